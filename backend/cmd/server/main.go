@@ -12,13 +12,19 @@ import (
 
 	"github.com/escuadron-404/red404/backend/config"
 	"github.com/escuadron-404/red404/backend/internal/handlers"
+	"github.com/escuadron-404/red404/backend/internal/jobs"
 	"github.com/escuadron-404/red404/backend/internal/migration"
+	"github.com/escuadron-404/red404/backend/internal/models"
 	"github.com/escuadron-404/red404/backend/internal/repositories"
 	"github.com/escuadron-404/red404/backend/internal/routes"
 	"github.com/escuadron-404/red404/backend/internal/services"
 	"github.com/escuadron-404/red404/backend/pkg/database"
 	"github.com/escuadron-404/red404/backend/pkg/middleware"
+	"github.com/escuadron-404/red404/backend/pkg/pubsub"
+	"github.com/escuadron-404/red404/backend/pkg/realtime"
+	"github.com/escuadron-404/red404/backend/pkg/storage"
 	"github.com/escuadron-404/red404/backend/pkg/utils"
+	"github.com/escuadron-404/red404/backend/pkg/video"
 
 	"github.com/go-playground/validator/v10"
 	"github.com/jackc/pgx/v5/pgxpool"
@@ -55,22 +61,160 @@ func main() {
 	validate := validator.New()
 	jwtUtil := utils.NewJWTUtil(cfg.JWTSecret, cfg.JWTExpirationHours)
 
+	// Initialize blob storage
+	mediaStorage, err := storage.New(&storage.Config{
+		Driver:         cfg.StorageDriver,
+		LocalPath:      cfg.StorageLocalPath,
+		PublicBaseURL:  cfg.PublicBaseURL,
+		SigningKey:     cfg.StorageSigningKey,
+		S3Endpoint:     cfg.S3Endpoint,
+		S3Region:       cfg.S3Region,
+		S3Bucket:       cfg.S3Bucket,
+		S3AccessKey:    cfg.S3AccessKey,
+		S3SecretKey:    cfg.S3SecretKey,
+		S3UsePathStyle: cfg.S3UsePathStyle,
+	})
+	if err != nil {
+		log.Printf("Failed to initialize storage: %v\n", err)
+		return
+	}
+
 	// Initialize repositories
 	userRepo := repositories.NewUserRepository(db.Pool)
+	followRepo := repositories.NewFollowRepository(db.Pool)
+	blockRepo := repositories.NewBlockRepository(db.Pool)
+	suggestionRepo := repositories.NewSuggestionRepository(db.Pool)
+	postRepo := repositories.NewPostRepository(db.Pool)
+	videoUploadRepo := repositories.NewVideoUploadRepository(db.Pool)
+	hashtagRepo := repositories.NewHashtagRepository(db.Pool)
+	mentionRepo := repositories.NewMentionRepository(db.Pool)
+	notificationRepo := repositories.NewNotificationRepository(db.Pool)
+	likeRepo := repositories.NewLikeRepository(db.Pool)
+	reactionRepo := repositories.NewReactionRepository(db.Pool)
+	commentRepo := repositories.NewCommentRepository(db.Pool)
+	bookmarkRepo := repositories.NewBookmarkRepository(db.Pool)
+	timelineRepo := repositories.NewTimelineRepository(db.Pool)
+	rankingRepo := repositories.NewRankingRepository(db.Pool)
+	trendingRepo := repositories.NewTrendingRepository(db.Pool)
+	messageRepo := repositories.NewMessageRepository(db.Pool)
+
+	// Initialize pub/sub, which carries events between server instances
+	bus, err := pubsub.New(&pubsub.Config{
+		Driver:  cfg.PubSubDriver,
+		Pool:    db.Pool,
+		Channel: cfg.PubSubChannel,
+	})
+	if err != nil {
+		log.Printf("Failed to initialize pub/sub: %v\n", err)
+		return
+	}
+	defer bus.Close()
+
+	// Initialize the realtime hub that pushes events to connected users. Events
+	// go through the relay so users connected to any instance get them.
+	hub := realtime.NewHub(realtime.Options{
+		SendBuffer:   cfg.RealtimeSendBuffer,
+		PingInterval: cfg.RealtimePingInterval,
+		ReplaySize:   cfg.RealtimeReplaySize,
+		ReplayTTL:    cfg.RealtimeReplayTTL,
+	})
+	relay := realtime.NewRelay(bus, hub)
+	defer relay.Close()
 
 	// Initialize services
-	userService := services.NewUserService(userRepo, validate)
 	authService := services.NewAuthService(userRepo, validate, jwtUtil)
+	feedOptions := services.FeedOptions{
+		Fanout: cfg.FeedFanout,
+		Limits: models.FanoutLimits{
+			MaxFollowers:  cfg.FeedFanoutMaxFollowers,
+			BackfillPosts: cfg.FeedFanoutBackfillPosts,
+		},
+		Ranking: services.RankingOptions{
+			Window:        cfg.FeedRankWindow,
+			MaxCandidates: cfg.FeedRankMaxCandidates,
+			HalfLife:      cfg.FeedRankHalfLife,
+			Weights: services.RankingWeights{
+				Recency:  cfg.FeedRankWeightRecency,
+				Likes:    cfg.FeedRankWeightLikes,
+				Comments: cfg.FeedRankWeightComments,
+				Saves:    cfg.FeedRankWeightSaves,
+				Affinity: cfg.FeedRankWeightAffinity,
+				Hashtags: cfg.FeedRankWeightHashtags,
+			},
+		},
+	}
+	userService := services.NewUserService(userRepo, followRepo, timelineRepo, relay, validate, feedOptions)
+	followService := services.NewFollowService(followRepo, userRepo, timelineRepo, relay, feedOptions)
+	blockService := services.NewBlockService(blockRepo, userRepo)
+	postMediaOptions := services.PostMediaOptions{
+		Storage:       mediaStorage,
+		URLTTL:        cfg.StorageURLTTL,
+		MaxItems:      cfg.PostMaxMedia,
+		MaxImageBytes: cfg.PostMaxImageBytes,
+	}
+	postService := services.NewPostService(postRepo, userRepo, followRepo, videoUploadRepo, hashtagRepo, mentionRepo, likeRepo, reactionRepo,
+		bookmarkRepo, timelineRepo, rankingRepo, trendingRepo, relay, postMediaOptions, feedOptions)
+	videoUploadService, err := services.NewVideoUploadService(videoUploadRepo, services.VideoUploadOptions{
+		Storage:     mediaStorage,
+		Tools:       video.Tools{FFprobePath: cfg.FFprobePath, FFmpegPath: cfg.FFmpegPath},
+		SpoolDir:    cfg.VideoSpoolDir,
+		MaxBytes:    cfg.VideoMaxBytes,
+		MaxDuration: cfg.VideoMaxDuration,
+		UploadTTL:   cfg.VideoUploadTTL,
+	})
+	if err != nil {
+		log.Printf("Failed to initialize video uploads: %v\n", err)
+		return
+	}
+	suggestionService := services.NewSuggestionService(suggestionRepo, followRepo, cfg.SuggestionsTTL)
+	notificationService := services.NewNotificationService(notificationRepo, followRepo)
+	fanoutService := services.NewFanoutService(timelineRepo, feedOptions)
+	trendingService := services.NewTrendingService(trendingRepo, services.TrendingOptions{
+		Window:            cfg.ExploreWindow,
+		MaxPostsPerAuthor: cfg.ExploreMaxPostsPerAuthor,
+	})
+	reactionService := services.NewReactionService(reactionRepo, postRepo, commentRepo, followRepo)
+	commentService := services.NewCommentService(commentRepo, postRepo, userRepo, followRepo, mentionRepo, reactionRepo, relay)
+	messageService := services.NewMessageService(messageRepo, userRepo, followRepo, relay)
 
 	// Initialize handlers
 	userHandler := handlers.NewUserHandler(userService, validate)
 	authHandler := handlers.NewAuthHandler(authService, validate)
+	mediaHandler := handlers.NewMediaHandler(mediaStorage)
+	followHandler := handlers.NewFollowHandler(followService)
+	blockHandler := handlers.NewBlockHandler(blockService)
+	suggestionHandler := handlers.NewSuggestionHandler(suggestionService)
+	videoUploadHandler := handlers.NewVideoUploadHandler(videoUploadService)
+	notificationHandler := handlers.NewNotificationHandler(notificationService)
+	reactionHandler := handlers.NewReactionHandler(reactionService, validate)
+	commentHandler := handlers.NewCommentHandler(commentService, validate)
+	searchHandler := handlers.NewSearchHandler(postService, commentService, validate)
+	messageHandler := handlers.NewMessageHandler(messageService, validate)
+	realtimeHandler := handlers.NewRealtimeHandler(hub, messageService)
+	// Allow every image at its maximum size plus 1 MB for the other form fields
+	postHandler := handlers.NewPostHandler(postService, validate, int64(cfg.PostMaxMedia)*cfg.PostMaxImageBytes+1<<20)
 
 	// Initialize middleware
 	authMiddleware := middleware.NewAuthMiddleware(jwtUtil)
 
 	// Setup routes using the routes package
-	mux := routes.SetupRoutes(userHandler, authHandler, authMiddleware)
+	mux := routes.SetupRoutes(&routes.Handlers{
+		User:   userHandler,
+		Auth:   authHandler,
+		Media:  mediaHandler,
+		Follow: followHandler,
+		Block:  blockHandler,
+		Post:   postHandler,
+
+		Suggestion:   suggestionHandler,
+		VideoUpload:  videoUploadHandler,
+		Comment:      commentHandler,
+		Reaction:     reactionHandler,
+		Notification: notificationHandler,
+		Search:       searchHandler,
+		Message:      messageHandler,
+		Realtime:     realtimeHandler,
+	}, authMiddleware)
 
 	// Wrap mux with CORS
 	//corsHandler := middleware.NewCORS().Handler(mux)
@@ -80,6 +224,21 @@ func main() {
 		Handler: mux,
 	}
 
+	// Start background jobs, they are stopped once the server has shut down
+	jobsCtx, stopJobs := context.WithCancel(context.Background())
+	backgroundJobs := []jobs.Job{
+		{Name: "suggestions", Interval: cfg.SuggestionsRefreshInterval, Run: suggestionService.RefreshStale},
+		{Name: "video-uploads-cleanup", Interval: time.Hour, Run: videoUploadService.CleanupExpired},
+		{Name: "trending", Interval: cfg.ExploreRefreshInterval, Run: trendingService.Refresh},
+		{Name: "realtime-replay-cleanup", Interval: time.Minute, Run: hub.PruneReplay},
+	}
+	if cfg.FeedFanout {
+		backgroundJobs = append(backgroundJobs, jobs.Job{Name: "feed-fanout", Interval: cfg.FeedFanoutInterval, Run: fanoutService.ProcessJobs})
+	}
+	waitJobs := jobs.Start(jobsCtx, backgroundJobs...)
+	defer waitJobs()
+	defer stopJobs()
+
 	// Wait for interrupt signal to gracefully shutdown the server
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM) // Listen for Ctrl+C and termination signals
@@ -102,6 +261,12 @@ func main() {
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
 	defer cancel() // This defer will now correctly run
 
+	// WebSocket connections are hijacked from the server, so Shutdown does not
+	// wait for them and they are closed here
+	if err := hub.Shutdown(ctx); err != nil {
+		log.Printf("Realtime connections forced to close: %v\n", err)
+	}
+
 	if err := server.Shutdown(ctx); err != nil {
 		log.Printf("Server forced to shutdown: %v\n", err)
 		// FIX: Replace os.Exit(1) with return.
@@ -4,7 +4,9 @@ package config
 import (
 	"log"
 	"os"
+	"path/filepath"
 	"strconv"
+	"time"
 
 	"github.com/joho/godotenv"
 )
@@ -19,6 +21,67 @@ type Config struct {
 	ServerPort         string
 	JWTSecret          string
 	JWTExpirationHours int
+
+	// Blob storage for uploaded media
+	StorageDriver     string
+	StorageLocalPath  string
+	StorageSigningKey string
+	StorageURLTTL     time.Duration
+	PublicBaseURL     string
+	S3Endpoint        string
+	S3Region          string
+	S3Bucket          string
+	S3AccessKey       string
+	S3SecretKey       string
+	S3UsePathStyle    bool
+
+	// Post media uploads
+	PostMaxMedia      int
+	PostMaxImageBytes int64
+
+	// Video uploads
+	VideoSpoolDir    string
+	VideoMaxBytes    int64
+	VideoMaxDuration time.Duration
+	VideoUploadTTL   time.Duration
+	FFprobePath      string
+	FFmpegPath       string
+
+	// Follow suggestions cache
+	SuggestionsRefreshInterval time.Duration
+	SuggestionsTTL             time.Duration
+
+	// Home feed fan-out on write
+	FeedFanout              bool
+	FeedFanoutMaxFollowers  int
+	FeedFanoutBackfillPosts int
+	FeedFanoutInterval      time.Duration
+
+	// Ranked feed
+	FeedRankWindow         time.Duration
+	FeedRankMaxCandidates  int
+	FeedRankHalfLife       time.Duration
+	FeedRankWeightRecency  float64
+	FeedRankWeightLikes    float64
+	FeedRankWeightComments float64
+	FeedRankWeightSaves    float64
+	FeedRankWeightAffinity float64
+	FeedRankWeightHashtags float64
+
+	// Explore page
+	ExploreWindow            time.Duration
+	ExploreRefreshInterval   time.Duration
+	ExploreMaxPostsPerAuthor int
+
+	// Realtime event streams
+	RealtimePingInterval time.Duration
+	RealtimeSendBuffer   int
+	RealtimeReplaySize   int
+	RealtimeReplayTTL    time.Duration
+
+	// Pub/sub between server instances
+	PubSubDriver  string
+	PubSubChannel string
 }
 
 func LoadConfig() *Config {
@@ -32,6 +95,11 @@ func LoadConfig() *Config {
 		expHours = 24
 	}
 
+	urlTTLMinutes, err := strconv.Atoi(getEnv("STORAGE_URL_TTL_MINUTES", "60"))
+	if err != nil || urlTTLMinutes <= 0 {
+		urlTTLMinutes = 60
+	}
+
 	return &Config{
 		DBHost:             getEnv("DB_HOST", "localhost"),
 		DBPort:             getEnv("DB_PORT", "5432"),
@@ -42,6 +110,57 @@ func LoadConfig() *Config {
 		ServerPort:         getEnv("SERVER_PORT", "8080"),
 		JWTSecret:          getEnv("JWT_SECRET", "super-secret-jwt-key"),
 		JWTExpirationHours: expHours,
+		StorageDriver:      getEnv("STORAGE_DRIVER", "local"),
+		StorageLocalPath:   getEnv("STORAGE_LOCAL_PATH", "./uploads"),
+		StorageSigningKey:  getEnv("STORAGE_SIGNING_KEY", ""),
+		StorageURLTTL:      time.Duration(urlTTLMinutes) * time.Minute,
+		PublicBaseURL:      getEnv("PUBLIC_BASE_URL", ""),
+		S3Endpoint:         getEnv("S3_ENDPOINT", ""),
+		S3Region:           getEnv("S3_REGION", "us-east-1"),
+		S3Bucket:           getEnv("S3_BUCKET", ""),
+		S3AccessKey:        getEnv("S3_ACCESS_KEY", ""),
+		S3SecretKey:        getEnv("S3_SECRET_KEY", ""),
+		S3UsePathStyle:     getEnvBool("S3_USE_PATH_STYLE", false),
+
+		PostMaxMedia:      getEnvInt("POST_MAX_MEDIA", 10),
+		PostMaxImageBytes: int64(getEnvInt("POST_MAX_IMAGE_MB", 10)) << 20,
+
+		VideoSpoolDir:    getEnv("VIDEO_SPOOL_DIR", filepath.Join(os.TempDir(), "red404-video-uploads")),
+		VideoMaxBytes:    int64(getEnvInt("VIDEO_MAX_MB", 200)) << 20,
+		VideoMaxDuration: time.Duration(getEnvInt("VIDEO_MAX_SECONDS", 180)) * time.Second,
+		VideoUploadTTL:   time.Duration(getEnvInt("VIDEO_UPLOAD_TTL_HOURS", 24)) * time.Hour,
+		FFprobePath:      getEnv("FFPROBE_PATH", "ffprobe"),
+		FFmpegPath:       getEnv("FFMPEG_PATH", "ffmpeg"),
+
+		SuggestionsRefreshInterval: time.Duration(getEnvInt("SUGGESTIONS_REFRESH_MINUTES", 15)) * time.Minute,
+		SuggestionsTTL:             time.Duration(getEnvInt("SUGGESTIONS_TTL_HOURS", 24)) * time.Hour,
+
+		FeedFanout:              getEnvBool("FEED_FANOUT", false),
+		FeedFanoutMaxFollowers:  getEnvInt("FEED_FANOUT_MAX_FOLLOWERS", 10000),
+		FeedFanoutBackfillPosts: getEnvInt("FEED_FANOUT_BACKFILL_POSTS", 50),
+		FeedFanoutInterval:      time.Duration(getEnvInt("FEED_FANOUT_INTERVAL_SECONDS", 5)) * time.Second,
+
+		FeedRankWindow:         time.Duration(getEnvInt("FEED_RANK_WINDOW_HOURS", 72)) * time.Hour,
+		FeedRankMaxCandidates:  getEnvInt("FEED_RANK_MAX_CANDIDATES", 500),
+		FeedRankHalfLife:       time.Duration(getEnvInt("FEED_RANK_HALF_LIFE_HOURS", 12)) * time.Hour,
+		FeedRankWeightRecency:  getEnvFloat("FEED_RANK_WEIGHT_RECENCY", 3),
+		FeedRankWeightLikes:    getEnvFloat("FEED_RANK_WEIGHT_LIKES", 1),
+		FeedRankWeightComments: getEnvFloat("FEED_RANK_WEIGHT_COMMENTS", 1.5),
+		FeedRankWeightSaves:    getEnvFloat("FEED_RANK_WEIGHT_SAVES", 2),
+		FeedRankWeightAffinity: getEnvFloat("FEED_RANK_WEIGHT_AFFINITY", 2),
+		FeedRankWeightHashtags: getEnvFloat("FEED_RANK_WEIGHT_HASHTAGS", 1),
+
+		ExploreWindow:            time.Duration(getEnvInt("EXPLORE_WINDOW_HOURS", 24)) * time.Hour,
+		ExploreRefreshInterval:   time.Duration(getEnvInt("EXPLORE_REFRESH_MINUTES", 10)) * time.Minute,
+		ExploreMaxPostsPerAuthor: getEnvInt("EXPLORE_MAX_POSTS_PER_AUTHOR", 2),
+
+		RealtimePingInterval: time.Duration(getEnvInt("REALTIME_PING_SECONDS", 25)) * time.Second,
+		RealtimeSendBuffer:   getEnvInt("REALTIME_SEND_BUFFER", 64),
+		RealtimeReplaySize:   getEnvInt("REALTIME_REPLAY_SIZE", 100),
+		RealtimeReplayTTL:    time.Duration(getEnvInt("REALTIME_REPLAY_MINUTES", 5)) * time.Minute,
+
+		PubSubDriver:  getEnv("PUBSUB_DRIVER", "memory"),
+		PubSubChannel: getEnv("PUBSUB_CHANNEL", "red404_events"),
 	}
 }
 
@@ -51,3 +170,31 @@ func getEnv(key, defaultValue string) string {
 	}
 	return defaultValue
 }
+
+func getEnvBool(key string, defaultValue bool) bool {
+	value, err := strconv.ParseBool(os.Getenv(key))
+	if err != nil {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvInt reads a positive integer, falling back to defaultValue when the
+// variable is missing or invalid
+func getEnvInt(key string, defaultValue int) int {
+	value, err := strconv.Atoi(os.Getenv(key))
+	if err != nil || value <= 0 {
+		return defaultValue
+	}
+	return value
+}
+
+// getEnvFloat reads a non-negative number, falling back to defaultValue when
+// the variable is missing or invalid
+func getEnvFloat(key string, defaultValue float64) float64 {
+	value, err := strconv.ParseFloat(os.Getenv(key), 64)
+	if err != nil || value < 0 {
+		return defaultValue
+	}
+	return value
+}
@@ -8,6 +8,8 @@ type LoginRequest struct {
 type RegisterRequest struct {
 	Email    string `json:"email" validate:"required,email"`
 	Password string `json:"password" validate:"required,min=8"`
+	// Username is picked from the email when left empty
+	Username string `json:"username" validate:"omitempty,min=3,max=30"`
 }
 
 type AuthResponse struct {
@@ -0,0 +1,32 @@
+package dto
+
+import "time"
+
+// SaveBookmarkRequest saves a post, in a collection when CollectionID is set
+type SaveBookmarkRequest struct {
+	CollectionID *int `json:"collection_id" validate:"omitempty,min=1"`
+}
+
+type BookmarkResponse struct {
+	SavedByMe    bool `json:"saved_by_me"`
+	CollectionID *int `json:"collection_id,omitempty"`
+}
+
+type CollectionRequest struct {
+	Name string `json:"name" validate:"required,max=50"`
+}
+
+type CollectionResponse struct {
+	ID        int       `json:"id"`
+	Name      string    `json:"name"`
+	PostCount int       `json:"post_count"`
+	CreatedAt time.Time `json:"created_at"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+// SavedPostResponse is a post of the saved list with when it was saved
+type SavedPostResponse struct {
+	PostResponse
+	CollectionID *int      `json:"collection_id,omitempty"`
+	SavedAt      time.Time `json:"saved_at"`
+}
@@ -0,0 +1,41 @@
+package dto
+
+import "time"
+
+type CreateCommentRequest struct {
+	Text string `json:"text" validate:"required,max=2200"`
+	// ParentID makes the comment a reply. Replies to replies are attached to
+	// the top level comment of the thread.
+	ParentID *int `json:"parent_id" validate:"omitempty,min=1"`
+}
+
+type UpdateCommentRequest struct {
+	Text string `json:"text" validate:"required,max=2200"`
+}
+
+// RemoveCommentRequest is sent by post authors and moderators removing
+// someone else's comment
+type RemoveCommentRequest struct {
+	Reason string `json:"reason" validate:"required,max=500"`
+}
+
+// CommentResponse describes a comment. Deleted comments that still have
+// replies come back with Deleted set and no author, text or mentions.
+// Deletion says whether the author deleted it ("self") or it was removed
+// ("moderation"); the reason is only shown to the author and the remover.
+type CommentResponse struct {
+	ID             int                     `json:"id"`
+	PostID         int                     `json:"post_id"`
+	ParentID       *int                    `json:"parent_id,omitempty"`
+	Author         *UserResponse           `json:"author,omitempty"`
+	Text           string                  `json:"text"`
+	Mentions       []MentionEntity         `json:"mentions"`
+	Deleted        bool                    `json:"deleted"`
+	Deletion       string                  `json:"deletion,omitempty"`
+	DeletionReason string                  `json:"deletion_reason,omitempty"`
+	ReplyCount     int                     `json:"reply_count"`
+	Reactions      []ReactionCountResponse `json:"reactions"`
+	MyReaction     string                  `json:"my_reaction,omitempty"`
+	CreatedAt      time.Time               `json:"created_at"`
+	UpdatedAt      time.Time               `json:"updated_at"`
+}
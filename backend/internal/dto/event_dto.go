@@ -0,0 +1,34 @@
+package dto
+
+// NotificationEvent announces a new notification. The full notification is
+// in GET /api/notifications.
+type NotificationEvent struct {
+	Type      string `json:"type"`
+	ActorID   int    `json:"actor_id"`
+	PostID    int    `json:"post_id"`
+	CommentID *int   `json:"comment_id,omitempty"`
+}
+
+// FollowEvent tells a user that User followed them or, for
+// "follow.accepted", approved their follow request
+type FollowEvent struct {
+	User UserResponse `json:"user"`
+}
+
+// LikeCountEvent carries the new like count of a post
+type LikeCountEvent struct {
+	PostID    int `json:"post_id"`
+	LikeCount int `json:"like_count"`
+}
+
+// TypingRequest is sent by a client while the user is writing a message
+type TypingRequest struct {
+	ConversationID int `json:"conversation_id"`
+}
+
+// TypingEvent tells the other members of a conversation that a user is
+// writing
+type TypingEvent struct {
+	ConversationID int `json:"conversation_id"`
+	UserID         int `json:"user_id"`
+}
@@ -0,0 +1,23 @@
+package dto
+
+import "time"
+
+type FollowRequestResponse struct {
+	ID        int          `json:"id"`
+	Sender    UserResponse `json:"sender"`
+	Status    string       `json:"status"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+// FollowResponse tells whether the follow went through or is waiting for the
+// approval of a private account
+type FollowResponse struct {
+	Status string `json:"status"`
+}
+
+type SuggestionResponse struct {
+	User            UserResponse `json:"user"`
+	MutualFollowers int          `json:"mutual_followers"`
+	SharedHashtags  int          `json:"shared_hashtags"`
+	Reason          string       `json:"reason"`
+}
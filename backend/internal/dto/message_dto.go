@@ -0,0 +1,68 @@
+package dto
+
+import "time"
+
+type StartConversationRequest struct {
+	UserID int `json:"user_id" validate:"required,min=1"`
+}
+
+type SendMessageRequest struct {
+	Text string `json:"text" validate:"required,max=2000"`
+}
+
+type CreateGroupRequest struct {
+	Title   string `json:"title" validate:"required,max=100"`
+	UserIDs []int  `json:"user_ids" validate:"required,min=1,dive,min=1"`
+}
+
+type AddMembersRequest struct {
+	UserIDs []int `json:"user_ids" validate:"required,min=1,dive,min=1"`
+}
+
+type UpdateGroupRequest struct {
+	Title string `json:"title" validate:"required,max=100"`
+}
+
+// UpdateMemberRoleRequest promotes or demotes a member. Making someone the
+// owner hands the group over and leaves the current owner as an admin.
+type UpdateMemberRoleRequest struct {
+	Role string `json:"role" validate:"required,oneof=owner admin member"`
+}
+
+// MessageResponse is a text message or, when kind is "system", the record of
+// a membership change: sender_id made event happen to target_user_id. The
+// text of "created" and "renamed" events is the group title.
+type MessageResponse struct {
+	ID             int       `json:"id"`
+	ConversationID int       `json:"conversation_id"`
+	SenderID       int       `json:"sender_id"`
+	Kind           string    `json:"kind"`
+	Event          string    `json:"event,omitempty"`
+	TargetUserID   *int      `json:"target_user_id,omitempty"`
+	Text           string    `json:"text"`
+	CreatedAt      time.Time `json:"created_at"`
+}
+
+type ConversationResponse struct {
+	ID    int    `json:"id"`
+	Kind  string `json:"kind"`
+	Title string `json:"title,omitempty"`
+	// Participant is the other member of a direct conversation
+	Participant *UserResponse `json:"participant,omitempty"`
+	MemberCount int           `json:"member_count"`
+	// MyRole is the role of the current user in a group
+	MyRole         string           `json:"my_role,omitempty"`
+	LastMessage    *MessageResponse `json:"last_message,omitempty"`
+	UnreadCount    int              `json:"unread_count"`
+	LastActivityAt time.Time        `json:"last_activity_at"`
+	CreatedAt      time.Time        `json:"created_at"`
+}
+
+// ConversationMemberResponse is a member of a conversation. LastReadMessageID
+// is the newest message they have read.
+type ConversationMemberResponse struct {
+	User              UserResponse `json:"user"`
+	Role              string       `json:"role"`
+	LastReadMessageID int          `json:"last_read_message_id"`
+	JoinedAt          time.Time    `json:"joined_at"`
+}
@@ -0,0 +1,17 @@
+package dto
+
+import "time"
+
+type NotificationResponse struct {
+	ID        int          `json:"id"`
+	Type      string       `json:"type"`
+	Actor     UserResponse `json:"actor"`
+	PostID    *int         `json:"post_id,omitempty"`
+	CommentID *int         `json:"comment_id,omitempty"`
+	Read      bool         `json:"read"`
+	CreatedAt time.Time    `json:"created_at"`
+}
+
+type UnreadCountResponse struct {
+	UnreadCount int `json:"unread_count"`
+}
@@ -0,0 +1,18 @@
+package dto
+
+// PaginatedResponse is the envelope used by offset paginated lists
+type PaginatedResponse[T any] struct {
+	Data       []T `json:"data"`
+	TotalCount int `json:"total_count"`
+	Limit      int `json:"limit"`
+	Offset     int `json:"offset"`
+}
+
+// CursorResponse is a page of a list paginated with opaque cursors. Pass
+// NextCursor back as the cursor parameter to get the next page; it is empty
+// on the last page.
+type CursorResponse[T any] struct {
+	Data       []T    `json:"data"`
+	NextCursor string `json:"next_cursor,omitempty"`
+	Limit      int    `json:"limit"`
+}
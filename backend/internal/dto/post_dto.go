@@ -0,0 +1,137 @@
+package dto
+
+import "time"
+
+type CreatePostRequest struct {
+	ImageURL    string `json:"image_url" validate:"max=255"`
+	Description string `json:"description" validate:"max=2200"`
+	// VideoUploadID attaches a finished resumable upload as the post video
+	VideoUploadID string `json:"video_upload_id" validate:"omitempty,hexadecimal,len=32"`
+	// NSFW marks sensitive content, which is kept out of explore
+	NSFW bool `json:"nsfw"`
+}
+
+type UpdatePostRequest struct {
+	ImageURL    *string `json:"image_url" validate:"omitempty,max=255"`
+	Description *string `json:"description" validate:"omitempty,max=2200"`
+	NSFW        *bool   `json:"nsfw"`
+}
+
+type PostResponse struct {
+	ID           int                     `json:"id"`
+	Author       UserResponse            `json:"author"`
+	ImageURL     string                  `json:"image_url"`
+	Description  string                  `json:"description"`
+	Media        []PostMediaResponse     `json:"media"`
+	Mentions     []MentionEntity         `json:"mentions"`
+	LikeCount    int                     `json:"like_count"`
+	LikedByMe    bool                    `json:"liked_by_me"`
+	CommentCount int                     `json:"comment_count"`
+	NSFW         bool                    `json:"nsfw"`
+	SavedByMe    bool                    `json:"saved_by_me"`
+	Reactions    []ReactionCountResponse `json:"reactions"`
+	MyReaction   string                  `json:"my_reaction,omitempty"`
+	CreatedAt    time.Time               `json:"created_at"`
+	UpdatedAt    time.Time               `json:"updated_at"`
+	// Ranking is only set in the ranked feed when an admin asks for debug
+	Ranking *RankingExplanation `json:"ranking,omitempty"`
+}
+
+// RankingExplanation breaks the score of a post in the ranked feed down into
+// the signals it was computed from
+type RankingExplanation struct {
+	Score   float64                  `json:"score"`
+	Signals map[string]RankingSignal `json:"signals"`
+}
+
+type RankingSignal struct {
+	Value        float64 `json:"value"`
+	Weight       float64 `json:"weight"`
+	Contribution float64 `json:"contribution"`
+}
+
+// MentionEntity marks the range of a text the frontend renders as a link to
+// a user. Offset and Length count UTF-16 code units, as JavaScript does.
+type MentionEntity struct {
+	Offset   int    `json:"offset"`
+	Length   int    `json:"length"`
+	UserID   int    `json:"user_id"`
+	Username string `json:"username"`
+}
+
+type LikeResponse struct {
+	LikeCount int  `json:"like_count"`
+	LikedByMe bool `json:"liked_by_me"`
+}
+
+type PostMediaResponse struct {
+	ID          int    `json:"id"`
+	Position    int    `json:"position"`
+	MediaType   string `json:"media_type"`
+	URL         string `json:"url"`
+	ContentType string `json:"content_type"`
+	Width       int    `json:"width"`
+	Height      int    `json:"height"`
+	AltText     string `json:"alt_text"`
+	PosterURL   string `json:"poster_url,omitempty"`
+	DurationMS  int    `json:"duration_ms,omitempty"`
+}
+
+type UpdateMediaAltTextRequest struct {
+	AltText string `json:"alt_text" validate:"max=1000"`
+}
+
+type VideoUploadResponse struct {
+	ID           string    `json:"id"`
+	Status       string    `json:"status"`
+	Error        string    `json:"error,omitempty"`
+	UploadLength int64     `json:"upload_length"`
+	UploadOffset int64     `json:"upload_offset"`
+	Width        int       `json:"width,omitempty"`
+	Height       int       `json:"height,omitempty"`
+	DurationMS   int       `json:"duration_ms,omitempty"`
+	ExpiresAt    time.Time `json:"expires_at"`
+}
+
+type HashtagResponse struct {
+	Name      string `json:"name"`
+	PostCount int    `json:"post_count"`
+}
+
+type TrendingHashtagResponse struct {
+	Name      string `json:"name"`
+	PostCount int    `json:"post_count"`
+	// RecentPostCount counts the posts using the tag in the trending window
+	RecentPostCount int `json:"recent_post_count"`
+}
+
+// ExploreResponse is a page of trending posts. Hashtags are only sent with
+// the first page.
+type ExploreResponse struct {
+	Posts    []PostResponse            `json:"posts"`
+	Hashtags []TrendingHashtagResponse `json:"hashtags"`
+	Limit    int                       `json:"limit"`
+	Offset   int                       `json:"offset"`
+}
+
+type ReactRequest struct {
+	Emoji string `json:"emoji" validate:"required"`
+}
+
+type ReactionCountResponse struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
+
+// ReactionSummaryResponse is the state of the reactions of an item after the
+// viewer reacted to it
+type ReactionSummaryResponse struct {
+	Reactions  []ReactionCountResponse `json:"reactions"`
+	MyReaction string                  `json:"my_reaction,omitempty"`
+}
+
+type ReactionResponse struct {
+	User      UserResponse `json:"user"`
+	Emoji     string       `json:"emoji"`
+	CreatedAt time.Time    `json:"created_at"`
+}
@@ -0,0 +1,27 @@
+package dto
+
+import "time"
+
+// SearchRequest holds the query parameters of the search endpoints
+type SearchRequest struct {
+	Query    string `validate:"max=200"`
+	Language string `validate:"omitempty,oneof=es en"`
+	Hashtag  string `validate:"max=100"`
+	Mention  string `validate:"max=31"`
+	From     *time.Time
+	To       *time.Time
+}
+
+// PostSearchResult is a matching post. Snippet is HTML escaped with the
+// matched words wrapped in <mark> tags.
+type PostSearchResult struct {
+	PostResponse
+	Snippet string `json:"snippet"`
+}
+
+// CommentSearchResult is a matching comment. Snippet is HTML escaped with the
+// matched words wrapped in <mark> tags.
+type CommentSearchResult struct {
+	CommentResponse
+	Snippet string `json:"snippet"`
+}
@@ -16,6 +16,30 @@ type UpdateUserRequest struct {
 type UserResponse struct {
 	ID        int       `json:"id"`
 	Email     string    `json:"email"`
+	Username  string    `json:"username"`
+	IsPrivate bool      `json:"is_private"`
+	DMPolicy  string    `json:"dm_policy"`
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
+
+	FollowersCount int `json:"followers_count"`
+	FollowingCount int `json:"following_count"`
+
+	// Relationship with the user making the request
+	IsFollowing     bool `json:"is_following"`
+	FollowsYou      bool `json:"follows_you"`
+	FollowRequested bool `json:"follow_requested"`
+	IsMuted         bool `json:"is_muted"`
+}
+
+type UpdatePrivacyRequest struct {
+	IsPrivate *bool `json:"is_private" validate:"required"`
+}
+
+type UpdateDMPolicyRequest struct {
+	DMPolicy string `json:"dm_policy" validate:"required,oneof=everyone followers nobody"`
+}
+
+type UpdateUsernameRequest struct {
+	Username string `json:"username" validate:"required,min=3,max=30"`
 }
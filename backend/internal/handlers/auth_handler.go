@@ -39,7 +39,7 @@ func (h *AuthHandler) Register(w http.ResponseWriter, r *http.Request) {
 
 	authResponse, err := h.authService.Register(r.Context(), req)
 	if err != nil {
-		common.ErrorResponse(w, http.StatusBadRequest, err.Error(), nil)
+		writeServiceError(w, err)
 		return
 	}
 
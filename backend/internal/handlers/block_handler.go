@@ -0,0 +1,74 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+)
+
+type BlockHandler struct {
+	blockService services.BlockService
+}
+
+func NewBlockHandler(blockService services.BlockService) *BlockHandler {
+	return &BlockHandler{blockService: blockService}
+}
+
+func (h *BlockHandler) Block(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.blockService.Block, "User blocked successfully")
+}
+
+func (h *BlockHandler) Unblock(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.blockService.Unblock, "User unblocked successfully")
+}
+
+func (h *BlockHandler) Mute(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.blockService.Mute, "User muted successfully")
+}
+
+func (h *BlockHandler) Unmute(w http.ResponseWriter, r *http.Request) {
+	h.act(w, r, h.blockService.Unmute, "User unmuted successfully")
+}
+
+func (h *BlockHandler) GetBlocked(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.blockService.GetBlocked, "Blocked users retrieved successfully")
+}
+
+func (h *BlockHandler) GetMuted(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, h.blockService.GetMuted, "Muted users retrieved successfully")
+}
+
+func (h *BlockHandler) act(w http.ResponseWriter, r *http.Request, action func(ctx context.Context, userID, targetID int) error, message string) {
+	targetID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	if err := action(r.Context(), currentUserID(r), targetID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, message)
+}
+
+func (h *BlockHandler) list(w http.ResponseWriter, r *http.Request, list func(ctx context.Context, userID, limit, offset int) ([]dto.UserResponse, int, error), message string) {
+	limit, offset := pageParams(r)
+
+	users, total, err := list(r.Context(), currentUserID(r), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.UserResponse]{
+		Data:       users,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, message)
+}
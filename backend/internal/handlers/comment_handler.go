@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+	"github.com/go-playground/validator/v10"
+)
+
+type CommentHandler struct {
+	commentService services.CommentService
+	validator      *validator.Validate
+}
+
+func NewCommentHandler(commentService services.CommentService, commentValidator *validator.Validate) *CommentHandler {
+	return &CommentHandler{
+		commentService: commentService,
+		validator:      commentValidator,
+	}
+}
+
+func (h *CommentHandler) CreateComment(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+
+	var req dto.CreateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	comment, err := h.commentService.CreateComment(r.Context(), currentUserID(r), postID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.CreatedResponse(w, comment, "Comment created successfully")
+}
+
+// GetPostComments lists top level comments. The sort query parameter is
+// "new" (default) or "top", and cursor continues a previous page.
+func (h *CommentHandler) GetPostComments(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+	limit, _ := pageParams(r)
+	query := r.URL.Query()
+
+	comments, next, err := h.commentService.GetPostComments(r.Context(), currentUserID(r), postID,
+		query.Get("sort"), query.Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.CursorResponse[dto.CommentResponse]{
+		Data:       comments,
+		NextCursor: next,
+		Limit:      limit,
+	}, "Comments retrieved successfully")
+}
+
+func (h *CommentHandler) GetReplies(w http.ResponseWriter, r *http.Request) {
+	commentID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid comment ID", nil)
+		return
+	}
+	limit, _ := pageParams(r)
+
+	replies, next, err := h.commentService.GetReplies(r.Context(), currentUserID(r), commentID, r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.CursorResponse[dto.CommentResponse]{
+		Data:       replies,
+		NextCursor: next,
+		Limit:      limit,
+	}, "Replies retrieved successfully")
+}
+
+func (h *CommentHandler) UpdateComment(w http.ResponseWriter, r *http.Request) {
+	commentID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid comment ID", nil)
+		return
+	}
+
+	var req dto.UpdateCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	comment, err := h.commentService.UpdateComment(r.Context(), currentUserID(r), commentID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, comment, "Comment updated successfully")
+}
+
+func (h *CommentHandler) DeleteComment(w http.ResponseWriter, r *http.Request) {
+	commentID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid comment ID", nil)
+		return
+	}
+
+	if err := h.commentService.DeleteComment(r.Context(), currentUserID(r), commentID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Comment deleted successfully")
+}
+
+// RemoveComment lets post authors and moderators take down a comment with a
+// reason
+func (h *CommentHandler) RemoveComment(w http.ResponseWriter, r *http.Request) {
+	commentID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid comment ID", nil)
+		return
+	}
+
+	var req dto.RemoveCommentRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if err := h.commentService.RemoveComment(r.Context(), currentUserID(r), commentID, req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Comment removed successfully")
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+)
+
+type FollowHandler struct {
+	followService services.FollowService
+}
+
+func NewFollowHandler(followService services.FollowService) *FollowHandler {
+	return &FollowHandler{followService: followService}
+}
+
+func (h *FollowHandler) Follow(w http.ResponseWriter, r *http.Request) {
+	targetID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	follow, err := h.followService.Follow(r.Context(), currentUserID(r), targetID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if follow.Status == services.FollowStatusRequested {
+		common.JSONResponse(w, http.StatusAccepted, common.Response{
+			Success: true,
+			Message: "Follow request sent",
+			Data:    follow,
+		})
+		return
+	}
+	common.SuccessResponse(w, follow, "User followed successfully")
+}
+
+func (h *FollowHandler) Unfollow(w http.ResponseWriter, r *http.Request) {
+	targetID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.followService.Unfollow(r.Context(), currentUserID(r), targetID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "User unfollowed successfully")
+}
+
+func (h *FollowHandler) GetFollowers(w http.ResponseWriter, r *http.Request) {
+	h.listConnections(w, r, h.followService.GetFollowers, "Followers retrieved successfully")
+}
+
+func (h *FollowHandler) GetFollowing(w http.ResponseWriter, r *http.Request) {
+	h.listConnections(w, r, h.followService.GetFollowing, "Following retrieved successfully")
+}
+
+type connectionLister func(ctx context.Context, viewerID, userID, limit, offset int) ([]dto.UserResponse, int, error)
+
+func (h *FollowHandler) listConnections(w http.ResponseWriter, r *http.Request, list connectionLister, message string) {
+	userID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+	limit, offset := pageParams(r)
+
+	users, total, err := list(r.Context(), currentUserID(r), userID, limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.UserResponse]{
+		Data:       users,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, message)
+}
+
+func (h *FollowHandler) RequestFollow(w http.ResponseWriter, r *http.Request) {
+	targetID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	request, err := h.followService.RequestFollow(r.Context(), currentUserID(r), targetID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.CreatedResponse(w, request, "Follow request sent")
+}
+
+func (h *FollowHandler) GetIncomingRequests(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+
+	requests, total, err := h.followService.GetIncomingRequests(r.Context(), currentUserID(r), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.FollowRequestResponse]{
+		Data:       requests,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, "Follow requests retrieved successfully")
+}
+
+func (h *FollowHandler) AcceptRequest(w http.ResponseWriter, r *http.Request) {
+	requestID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid follow request ID", nil)
+		return
+	}
+
+	if err := h.followService.AcceptRequest(r.Context(), currentUserID(r), requestID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Follow request accepted")
+}
+
+func (h *FollowHandler) RejectRequest(w http.ResponseWriter, r *http.Request) {
+	requestID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid follow request ID", nil)
+		return
+	}
+
+	if err := h.followService.RejectRequest(r.Context(), currentUserID(r), requestID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Follow request rejected")
+}
@@ -0,0 +1,114 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"strconv"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+	"github.com/escuadron-404/red404/backend/pkg/middleware"
+	"github.com/go-playground/validator/v10"
+)
+
+const (
+	defaultPageLimit = 10
+	maxPageLimit     = 100
+)
+
+// currentUserID returns the id of the authenticated user, or 0 when the
+// request did not go through the auth middleware
+func currentUserID(r *http.Request) int {
+	claims := middleware.GetUserFromContext(r.Context())
+	if claims == nil {
+		return 0
+	}
+	return claims.UserID
+}
+
+// pathID parses a numeric path parameter
+func pathID(r *http.Request, name string) (int, error) {
+	id, err := strconv.Atoi(r.PathValue(name))
+	if err != nil || id <= 0 {
+		return 0, fmt.Errorf("invalid %s", name)
+	}
+	return id, nil
+}
+
+// pageParams reads limit/offset query parameters with sane defaults
+func pageParams(r *http.Request) (limit, offset int) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageLimit
+	} else if limit > maxPageLimit {
+		limit = maxPageLimit
+	}
+
+	offset, err = strconv.Atoi(r.URL.Query().Get("offset"))
+	if err != nil || offset < 0 {
+		offset = 0
+	}
+	return limit, offset
+}
+
+// writeServiceError maps service error kinds to status codes. Unexpected
+// errors are logged and hidden from the client.
+func writeServiceError(w http.ResponseWriter, err error) {
+	var validationErrors validator.ValidationErrors
+	switch {
+	case errors.As(err, &validationErrors):
+		writeValidationErrors(w, validationErrors)
+	case errors.Is(err, services.ErrNotFound):
+		common.ErrorResponse(w, http.StatusNotFound, err.Error(), nil)
+	case errors.Is(err, services.ErrForbidden):
+		common.ErrorResponse(w, http.StatusForbidden, err.Error(), nil)
+	case errors.Is(err, services.ErrConflict):
+		common.ErrorResponse(w, http.StatusConflict, err.Error(), nil)
+	case errors.Is(err, services.ErrInvalid):
+		common.ErrorResponse(w, http.StatusBadRequest, err.Error(), nil)
+	case errors.Is(err, services.ErrTooLarge):
+		common.ErrorResponse(w, http.StatusRequestEntityTooLarge, err.Error(), nil)
+	case errors.Is(err, services.ErrGone):
+		common.ErrorResponse(w, http.StatusGone, err.Error(), nil)
+	default:
+		log.Printf("Unexpected service error: %v", err)
+		common.ErrorResponse(w, http.StatusInternalServerError, "Internal server error", nil)
+	}
+}
+
+func writeValidationErrors(w http.ResponseWriter, errs validator.ValidationErrors) {
+	validationErrors := make([]dto.ValidationError, 0, len(errs))
+
+	for _, err := range errs {
+		var message string
+		switch err.Tag() {
+		case "required":
+			message = fmt.Sprintf("%s is required", err.Field())
+		case "min":
+			message = fmt.Sprintf("%s must be at least %s long", err.Field(), err.Param())
+		case "max":
+			message = fmt.Sprintf("%s must be at most %s long", err.Field(), err.Param())
+		case "oneof":
+			message = fmt.Sprintf("%s must be one of: %s", err.Field(), err.Param())
+		default:
+			message = fmt.Sprintf("%s is invalid", err.Field())
+		}
+		validationErrors = append(validationErrors, dto.ValidationError{
+			Field:   err.Field(),
+			Message: message,
+		})
+	}
+
+	common.JSONResponse(w, http.StatusBadRequest, common.Response{
+		Success: false,
+		Message: "Validation failed",
+		Error: dto.ErrorResponse{
+			Success: false,
+			Message: "Validation failed",
+			Errors:  validationErrors,
+		},
+	})
+}
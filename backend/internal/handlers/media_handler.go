@@ -0,0 +1,66 @@
+package handlers
+
+import (
+	"errors"
+	"log"
+	"net/http"
+	"path"
+	"strconv"
+
+	"github.com/escuadron-404/red404/backend/pkg/common"
+	"github.com/escuadron-404/red404/backend/pkg/storage"
+)
+
+type MediaHandler struct {
+	storage storage.Storage
+}
+
+func NewMediaHandler(mediaStorage storage.Storage) *MediaHandler {
+	return &MediaHandler{storage: mediaStorage}
+}
+
+// ServeSigned serves objects of backends that sign their own URLs (the local
+// filesystem). Range requests are handled by http.ServeContent.
+func (h *MediaHandler) ServeSigned(w http.ResponseWriter, r *http.Request) {
+	verifier, ok := h.storage.(storage.Verifier)
+	if !ok {
+		common.ErrorResponse(w, http.StatusNotFound, "Media not found", nil)
+		return
+	}
+
+	key, err := storage.CleanKey(r.PathValue("key"))
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid media key", nil)
+		return
+	}
+
+	expires, err := strconv.ParseInt(r.URL.Query().Get("expires"), 10, 64)
+	if err != nil {
+		common.ErrorResponse(w, http.StatusForbidden, "Invalid or expired link", nil)
+		return
+	}
+	if err := verifier.Verify(key, expires, r.URL.Query().Get("signature")); err != nil {
+		common.ErrorResponse(w, http.StatusForbidden, "Invalid or expired link", nil)
+		return
+	}
+
+	obj, err := h.storage.Open(r.Context(), key)
+	if err != nil {
+		if errors.Is(err, storage.ErrNotFound) {
+			common.ErrorResponse(w, http.StatusNotFound, "Media not found", nil)
+			return
+		}
+		log.Printf("Error opening media %s: %v", key, err)
+		common.ErrorResponse(w, http.StatusInternalServerError, "Failed to read media", nil)
+		return
+	}
+	defer obj.Close()
+
+	info := obj.Info()
+	if info.ContentType != "" {
+		w.Header().Set("Content-Type", info.ContentType)
+	}
+	w.Header().Set("Cache-Control", "private, max-age=300")
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	http.ServeContent(w, r, path.Base(key), info.ModTime, obj)
+}
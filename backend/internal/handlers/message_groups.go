@@ -0,0 +1,166 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+)
+
+// CreateGroup starts a group conversation owned by the current user
+func (h *MessageHandler) CreateGroup(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	conversation, err := h.messageService.CreateGroup(r.Context(), currentUserID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.CreatedResponse(w, conversation, "Group created successfully")
+}
+
+func (h *MessageHandler) RenameGroup(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+
+	var req dto.UpdateGroupRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if err := h.messageService.RenameGroup(r.Context(), currentUserID(r), conversationID, req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Group renamed successfully")
+}
+
+func (h *MessageHandler) GetMembers(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+
+	members, err := h.messageService.GetMembers(r.Context(), currentUserID(r), conversationID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, members, "Members retrieved successfully")
+}
+
+// AddMembers adds the users in the body to a group and returns the updated
+// member list
+func (h *MessageHandler) AddMembers(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+
+	var req dto.AddMembersRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	members, err := h.messageService.AddMembers(r.Context(), currentUserID(r), conversationID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, members, "Members added successfully")
+}
+
+func (h *MessageHandler) RemoveMember(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+	memberID, err := pathID(r, "userId")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	if err := h.messageService.RemoveMember(r.Context(), currentUserID(r), conversationID, memberID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Member removed successfully")
+}
+
+// LeaveGroup takes the current user out of a group. If they own it, another
+// member becomes the owner.
+func (h *MessageHandler) LeaveGroup(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+
+	if err := h.messageService.LeaveGroup(r.Context(), currentUserID(r), conversationID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Left group successfully")
+}
+
+func (h *MessageHandler) UpdateMemberRole(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+	memberID, err := pathID(r, "userId")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+
+	var req dto.UpdateMemberRoleRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if err := h.messageService.UpdateMemberRole(r.Context(), currentUserID(r), conversationID, memberID, req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Member role updated successfully")
+}
@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+	"github.com/go-playground/validator/v10"
+)
+
+type MessageHandler struct {
+	messageService services.MessageService
+	validator      *validator.Validate
+}
+
+func NewMessageHandler(messageService services.MessageService, messageValidator *validator.Validate) *MessageHandler {
+	return &MessageHandler{
+		messageService: messageService,
+		validator:      messageValidator,
+	}
+}
+
+// StartConversation opens the conversation with the user in the body, or
+// returns the existing one
+func (h *MessageHandler) StartConversation(w http.ResponseWriter, r *http.Request) {
+	var req dto.StartConversationRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	conversation, err := h.messageService.StartConversation(r.Context(), currentUserID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, conversation, "Conversation retrieved successfully")
+}
+
+// GetConversations lists the inbox, most recent activity first. Pass
+// next_cursor back as cursor to get the next page.
+func (h *MessageHandler) GetConversations(w http.ResponseWriter, r *http.Request) {
+	limit, _ := pageParams(r)
+
+	conversations, next, err := h.messageService.GetConversations(r.Context(), currentUserID(r), r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.CursorResponse[dto.ConversationResponse]{
+		Data:       conversations,
+		NextCursor: next,
+		Limit:      limit,
+	}, "Conversations retrieved successfully")
+}
+
+// GetMessages lists the messages of a conversation, newest first. Pass
+// next_cursor back as cursor to get older messages.
+func (h *MessageHandler) GetMessages(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+	limit, _ := pageParams(r)
+
+	messages, next, err := h.messageService.GetMessages(r.Context(), currentUserID(r), conversationID,
+		r.URL.Query().Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.CursorResponse[dto.MessageResponse]{
+		Data:       messages,
+		NextCursor: next,
+		Limit:      limit,
+	}, "Messages retrieved successfully")
+}
+
+func (h *MessageHandler) SendMessage(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+
+	var req dto.SendMessageRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	message, err := h.messageService.SendMessage(r.Context(), currentUserID(r), conversationID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.CreatedResponse(w, message, "Message sent successfully")
+}
+
+func (h *MessageHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	conversationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid conversation ID", nil)
+		return
+	}
+
+	if err := h.messageService.MarkRead(r.Context(), currentUserID(r), conversationID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Conversation marked as read")
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"net/http"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+)
+
+type NotificationHandler struct {
+	notificationService services.NotificationService
+}
+
+func NewNotificationHandler(notificationService services.NotificationService) *NotificationHandler {
+	return &NotificationHandler{notificationService: notificationService}
+}
+
+func (h *NotificationHandler) GetNotifications(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+
+	notifications, total, err := h.notificationService.GetNotifications(r.Context(), currentUserID(r), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.NotificationResponse]{
+		Data:       notifications,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, "Notifications retrieved successfully")
+}
+
+func (h *NotificationHandler) GetUnreadCount(w http.ResponseWriter, r *http.Request) {
+	count, err := h.notificationService.GetUnreadCount(r.Context(), currentUserID(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, count, "Unread count retrieved successfully")
+}
+
+func (h *NotificationHandler) MarkRead(w http.ResponseWriter, r *http.Request) {
+	notificationID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid notification ID", nil)
+		return
+	}
+
+	if err := h.notificationService.MarkRead(r.Context(), currentUserID(r), notificationID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Notification marked as read")
+}
+
+func (h *NotificationHandler) MarkAllRead(w http.ResponseWriter, r *http.Request) {
+	if err := h.notificationService.MarkAllRead(r.Context(), currentUserID(r)); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Notifications marked as read")
+}
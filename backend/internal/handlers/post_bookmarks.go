@@ -0,0 +1,155 @@
+package handlers
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"strconv"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+)
+
+// SavePost bookmarks a post. The body is optional and can name a collection.
+func (h *PostHandler) SavePost(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+
+	var req dto.SaveBookmarkRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && !errors.Is(err, io.EOF) {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	bookmark, err := h.postService.SavePost(r.Context(), currentUserID(r), postID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, bookmark, "Post saved successfully")
+}
+
+func (h *PostHandler) UnsavePost(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+
+	bookmark, err := h.postService.UnsavePost(r.Context(), currentUserID(r), postID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, bookmark, "Post unsaved successfully")
+}
+
+// GetSavedPosts lists the saved posts of the current user. The
+// collection_id query parameter narrows it down to one collection.
+func (h *PostHandler) GetSavedPosts(w http.ResponseWriter, r *http.Request) {
+	limit, _ := pageParams(r)
+	query := r.URL.Query()
+
+	var collectionID *int
+	if raw := query.Get("collection_id"); raw != "" {
+		id, err := strconv.Atoi(raw)
+		if err != nil || id <= 0 {
+			common.ErrorResponse(w, http.StatusBadRequest, "Invalid collection ID", nil)
+			return
+		}
+		collectionID = &id
+	}
+
+	posts, next, err := h.postService.GetSavedPosts(r.Context(), currentUserID(r), collectionID, query.Get("cursor"), limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.CursorResponse[dto.SavedPostResponse]{
+		Data:       posts,
+		NextCursor: next,
+		Limit:      limit,
+	}, "Saved posts retrieved successfully")
+}
+
+func (h *PostHandler) CreateCollection(w http.ResponseWriter, r *http.Request) {
+	var req dto.CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	collection, err := h.postService.CreateCollection(r.Context(), currentUserID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.CreatedResponse(w, collection, "Collection created successfully")
+}
+
+func (h *PostHandler) GetCollections(w http.ResponseWriter, r *http.Request) {
+	collections, err := h.postService.GetCollections(r.Context(), currentUserID(r))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, collections, "Collections retrieved successfully")
+}
+
+func (h *PostHandler) RenameCollection(w http.ResponseWriter, r *http.Request) {
+	collectionID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid collection ID", nil)
+		return
+	}
+
+	var req dto.CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	collection, err := h.postService.RenameCollection(r.Context(), currentUserID(r), collectionID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, collection, "Collection renamed successfully")
+}
+
+func (h *PostHandler) DeleteCollection(w http.ResponseWriter, r *http.Request) {
+	collectionID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid collection ID", nil)
+		return
+	}
+
+	if err := h.postService.DeleteCollection(r.Context(), currentUserID(r), collectionID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Collection deleted successfully")
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net/http"
+	"strconv"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+	"github.com/go-playground/validator/v10"
+)
+
+// multipartMemory is how much of a multipart body is kept in memory before
+// the remaining files are spooled to disk
+const multipartMemory = 8 << 20
+
+type PostHandler struct {
+	postService    services.PostService
+	validator      *validator.Validate
+	maxUploadBytes int64
+}
+
+// NewPostHandler creates the post handler. maxUploadBytes caps the size of a
+// whole multipart request.
+func NewPostHandler(postService services.PostService, postValidator *validator.Validate, maxUploadBytes int64) *PostHandler {
+	return &PostHandler{
+		postService:    postService,
+		validator:      postValidator,
+		maxUploadBytes: maxUploadBytes,
+	}
+}
+
+// CreatePost accepts either a JSON body or a multipart form with a
+// description, any number of "media" files and one "alt_text" value per file
+// in the same order
+func (h *PostHandler) CreatePost(w http.ResponseWriter, r *http.Request) {
+	var req dto.CreatePostRequest
+	var uploads []services.MediaUpload
+
+	mediaType, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mediaType == "multipart/form-data" {
+		r.Body = http.MaxBytesReader(w, r.Body, h.maxUploadBytes)
+		if err := r.ParseMultipartForm(multipartMemory); err != nil {
+			common.ErrorResponse(w, http.StatusBadRequest, "Invalid multipart form or upload too large", nil)
+			return
+		}
+		defer r.MultipartForm.RemoveAll() //nolint:errcheck // best effort cleanup of spooled files
+
+		req.Description = r.FormValue("description")
+		req.VideoUploadID = r.FormValue("video_upload_id")
+		req.NSFW, _ = strconv.ParseBool(r.FormValue("nsfw"))
+		files, err := openUploads(r.MultipartForm)
+		if err != nil {
+			common.ErrorResponse(w, http.StatusBadRequest, "Failed to read uploaded files", nil)
+			return
+		}
+		defer closeUploads(files)
+		uploads = files
+	} else if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	post, err := h.postService.CreatePost(r.Context(), currentUserID(r), req, uploads)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.CreatedResponse(w, post, "Post created successfully")
+}
+
+func (h *PostHandler) GetPost(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+
+	post, err := h.postService.GetPost(r.Context(), currentUserID(r), postID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, post, "Post retrieved successfully")
+}
+
+func (h *PostHandler) GetUserPosts(w http.ResponseWriter, r *http.Request) {
+	userID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid user ID", nil)
+		return
+	}
+	limit, offset := pageParams(r)
+
+	posts, total, err := h.postService.GetUserPosts(r.Context(), currentUserID(r), userID, limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.PostResponse]{
+		Data:       posts,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, "Posts retrieved successfully")
+}
+
+func (h *PostHandler) GetVideoPosts(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+
+	posts, total, err := h.postService.GetVideoPosts(r.Context(), currentUserID(r), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.PostResponse]{
+		Data:       posts,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, "Videos retrieved successfully")
+}
+
+// GetFeed returns the home feed of the current user. The mode query
+// parameter picks "chronological" (default) or "ranked", and debug=true
+// explains the ranking to admins. Pass next_cursor back as cursor to get the
+// next page.
+func (h *PostHandler) GetFeed(w http.ResponseWriter, r *http.Request) {
+	limit, _ := pageParams(r)
+	query := r.URL.Query()
+	debug, _ := strconv.ParseBool(query.Get("debug"))
+
+	posts, next, err := h.postService.GetFeed(r.Context(), currentUserID(r), query.Get("mode"), query.Get("cursor"), limit, debug)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.CursorResponse[dto.PostResponse]{
+		Data:       posts,
+		NextCursor: next,
+		Limit:      limit,
+	}, "Feed retrieved successfully")
+}
+
+// GetExplore returns trending posts, paged with limit and offset, and the
+// trending hashtags
+func (h *PostHandler) GetExplore(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+
+	explore, err := h.postService.GetExplore(r.Context(), currentUserID(r), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, explore, "Explore retrieved successfully")
+}
+
+func (h *PostHandler) GetHashtag(w http.ResponseWriter, r *http.Request) {
+	tag, err := h.postService.GetHashtag(r.Context(), r.PathValue("name"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, tag, "Hashtag retrieved successfully")
+}
+
+func (h *PostHandler) GetHashtagPosts(w http.ResponseWriter, r *http.Request) {
+	limit, offset := pageParams(r)
+
+	posts, total, err := h.postService.GetHashtagPosts(r.Context(), currentUserID(r), r.PathValue("name"), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.PostResponse]{
+		Data:       posts,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, "Posts retrieved successfully")
+}
+
+func (h *PostHandler) UpdatePost(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+
+	var req dto.UpdatePostRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	post, err := h.postService.UpdatePost(r.Context(), currentUserID(r), postID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, post, "Post updated successfully")
+}
+
+func (h *PostHandler) UpdateMediaAltText(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+	mediaID, err := pathID(r, "mediaId")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid media ID", nil)
+		return
+	}
+
+	var req dto.UpdateMediaAltTextRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	if err := h.postService.UpdateMediaAltText(r.Context(), currentUserID(r), postID, mediaID, req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Alt text updated successfully")
+}
+
+func (h *PostHandler) DeletePost(w http.ResponseWriter, r *http.Request) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+
+	if err := h.postService.DeletePost(r.Context(), currentUserID(r), postID); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, nil, "Post deleted successfully")
+}
+
+func (h *PostHandler) LikePost(w http.ResponseWriter, r *http.Request) {
+	h.like(w, r, h.postService.LikePost, "Post liked successfully")
+}
+
+func (h *PostHandler) UnlikePost(w http.ResponseWriter, r *http.Request) {
+	h.like(w, r, h.postService.UnlikePost, "Post unliked successfully")
+}
+
+func (h *PostHandler) like(w http.ResponseWriter, r *http.Request,
+	action func(ctx context.Context, userID, postID int) (*dto.LikeResponse, error), message string) {
+	postID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid post ID", nil)
+		return
+	}
+
+	like, err := action(r.Context(), currentUserID(r), postID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, like, message)
+}
+
+// openUploads opens the "media" files of the form, pairing each one with the
+// "alt_text" value at the same index
+func openUploads(form *multipart.Form) ([]services.MediaUpload, error) {
+	headers := form.File["media"]
+	altTexts := form.Value["alt_text"]
+
+	uploads := make([]services.MediaUpload, 0, len(headers))
+	for i, header := range headers {
+		file, err := header.Open()
+		if err != nil {
+			closeUploads(uploads)
+			return nil, err
+		}
+		upload := services.MediaUpload{File: file, Size: header.Size}
+		if i < len(altTexts) {
+			upload.AltText = altTexts[i]
+		}
+		uploads = append(uploads, upload)
+	}
+	return uploads, nil
+}
+
+func closeUploads(uploads []services.MediaUpload) {
+	for _, upload := range uploads {
+		if closer, ok := upload.File.(io.Closer); ok {
+			closer.Close()
+		}
+	}
+}
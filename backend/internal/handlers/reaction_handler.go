@@ -0,0 +1,120 @@
+package handlers
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+	"github.com/go-playground/validator/v10"
+)
+
+type ReactionHandler struct {
+	reactionService services.ReactionService
+	validator       *validator.Validate
+}
+
+func NewReactionHandler(reactionService services.ReactionService, reactionValidator *validator.Validate) *ReactionHandler {
+	return &ReactionHandler{
+		reactionService: reactionService,
+		validator:       reactionValidator,
+	}
+}
+
+// GetPalette lists the emoji that can be used as reactions
+func (h *ReactionHandler) GetPalette(w http.ResponseWriter, _ *http.Request) {
+	common.SuccessResponse(w, services.ReactionPalette, "Reactions retrieved successfully")
+}
+
+func (h *ReactionHandler) ReactToPost(w http.ResponseWriter, r *http.Request) {
+	h.react(w, r, models.ReactionOnPost)
+}
+
+func (h *ReactionHandler) UnreactToPost(w http.ResponseWriter, r *http.Request) {
+	h.unreact(w, r, models.ReactionOnPost)
+}
+
+func (h *ReactionHandler) GetPostReactions(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, models.ReactionOnPost)
+}
+
+func (h *ReactionHandler) ReactToComment(w http.ResponseWriter, r *http.Request) {
+	h.react(w, r, models.ReactionOnComment)
+}
+
+func (h *ReactionHandler) UnreactToComment(w http.ResponseWriter, r *http.Request) {
+	h.unreact(w, r, models.ReactionOnComment)
+}
+
+func (h *ReactionHandler) GetCommentReactions(w http.ResponseWriter, r *http.Request) {
+	h.list(w, r, models.ReactionOnComment)
+}
+
+func (h *ReactionHandler) react(w http.ResponseWriter, r *http.Request, target models.ReactionTarget) {
+	targetID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid "+string(target)+" ID", nil)
+		return
+	}
+
+	var req dto.ReactRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	summary, err := h.reactionService.React(r.Context(), currentUserID(r), target, targetID, req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, summary, "Reaction saved successfully")
+}
+
+func (h *ReactionHandler) unreact(w http.ResponseWriter, r *http.Request, target models.ReactionTarget) {
+	targetID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid "+string(target)+" ID", nil)
+		return
+	}
+
+	summary, err := h.reactionService.Unreact(r.Context(), currentUserID(r), target, targetID)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, summary, "Reaction removed successfully")
+}
+
+// list returns who reacted to the item. The emoji query parameter narrows
+// it down to one reaction.
+func (h *ReactionHandler) list(w http.ResponseWriter, r *http.Request, target models.ReactionTarget) {
+	targetID, err := pathID(r, "id")
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid "+string(target)+" ID", nil)
+		return
+	}
+	limit, offset := pageParams(r)
+
+	reactions, total, err := h.reactionService.GetReactions(r.Context(), currentUserID(r), target, targetID,
+		r.URL.Query().Get("emoji"), limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.ReactionResponse]{
+		Data:       reactions,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, "Reactions retrieved successfully")
+}
@@ -0,0 +1,55 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"log"
+	"net/http"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/realtime"
+)
+
+type RealtimeHandler struct {
+	hub            *realtime.Hub
+	messageService services.MessageService
+}
+
+func NewRealtimeHandler(hub *realtime.Hub, messageService services.MessageService) *RealtimeHandler {
+	return &RealtimeHandler{
+		hub:            hub,
+		messageService: messageService,
+	}
+}
+
+// Connect opens the WebSocket that carries the events of the current user.
+// The client answers each {"type": "ping"} with {"type": "pong"} and sends
+// {"type": "typing", "data": {"conversation_id": ...}} while writing a message.
+func (h *RealtimeHandler) Connect(w http.ResponseWriter, r *http.Request) {
+	h.hub.ServeWebSocket(w, r, currentUserID(r), h.receive)
+}
+
+// Events streams the events of the current user as Server-Sent Events, for
+// networks where WebSockets do not get through. A reconnecting client gets
+// the events it missed after Last-Event-ID.
+func (h *RealtimeHandler) Events(w http.ResponseWriter, r *http.Request) {
+	h.hub.ServeEvents(w, r, currentUserID(r))
+}
+
+// receive handles a message from a client. There is no reply, so messages
+// the user is not allowed to send are dropped.
+func (h *RealtimeHandler) receive(ctx context.Context, userID int, msg realtime.Incoming) {
+	switch msg.Type {
+	case services.EventTyping:
+		var req dto.TypingRequest
+		if err := json.Unmarshal(msg.Data, &req); err != nil || req.ConversationID <= 0 {
+			return
+		}
+		err := h.messageService.Typing(ctx, userID, req.ConversationID)
+		if err != nil && !errors.Is(err, services.ErrNotFound) && !errors.Is(err, services.ErrForbidden) {
+			log.Printf("Failed to send typing event: %v", err)
+		}
+	}
+}
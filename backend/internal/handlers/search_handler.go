@@ -0,0 +1,124 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+	"github.com/go-playground/validator/v10"
+)
+
+// searchDateLayout is accepted for from and to next to RFC 3339 timestamps
+const searchDateLayout = "2006-01-02"
+
+type SearchHandler struct {
+	postService    services.PostService
+	commentService services.CommentService
+	validator      *validator.Validate
+}
+
+func NewSearchHandler(postService services.PostService, commentService services.CommentService,
+	searchValidator *validator.Validate) *SearchHandler {
+	return &SearchHandler{
+		postService:    postService,
+		commentService: commentService,
+		validator:      searchValidator,
+	}
+}
+
+// SearchPosts searches post descriptions. See searchRequest for the query
+// parameters.
+func (h *SearchHandler) SearchPosts(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.searchRequest(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := pageParams(r)
+
+	posts, total, err := h.postService.SearchPosts(r.Context(), currentUserID(r), req, limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.PostSearchResult]{
+		Data:       posts,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, "Posts retrieved successfully")
+}
+
+// SearchComments searches comments. See searchRequest for the query
+// parameters.
+func (h *SearchHandler) SearchComments(w http.ResponseWriter, r *http.Request) {
+	req, ok := h.searchRequest(w, r)
+	if !ok {
+		return
+	}
+	limit, offset := pageParams(r)
+
+	comments, total, err := h.commentService.SearchComments(r.Context(), currentUserID(r), req, limit, offset)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, dto.PaginatedResponse[dto.CommentSearchResult]{
+		Data:       comments,
+		TotalCount: total,
+		Limit:      limit,
+		Offset:     offset,
+	}, "Comments retrieved successfully")
+}
+
+// searchRequest reads q, lang ("es" or "en", both when missing), hashtag,
+// mention, from and to. Dates are RFC 3339 timestamps or plain days, a day
+// in to includes the whole day. It writes the error response and returns
+// false when a parameter is invalid.
+func (h *SearchHandler) searchRequest(w http.ResponseWriter, r *http.Request) (dto.SearchRequest, bool) {
+	query := r.URL.Query()
+	req := dto.SearchRequest{
+		Query:    query.Get("q"),
+		Language: query.Get("lang"),
+		Hashtag:  query.Get("hashtag"),
+		Mention:  query.Get("mention"),
+	}
+
+	var err error
+	if req.From, err = parseSearchTime(query.Get("from"), false); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid from date", nil)
+		return req, false
+	}
+	if req.To, err = parseSearchTime(query.Get("to"), true); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid to date", nil)
+		return req, false
+	}
+	if err := h.validator.Struct(req); err != nil {
+		writeServiceError(w, err)
+		return req, false
+	}
+	return req, true
+}
+
+// parseSearchTime parses an optional date bound. With endOfDay a plain day
+// is moved to the start of the next one so the range includes it.
+func parseSearchTime(value string, endOfDay bool) (*time.Time, error) {
+	if value == "" {
+		return nil, nil
+	}
+	if t, err := time.Parse(time.RFC3339, value); err == nil {
+		return &t, nil
+	}
+	t, err := time.Parse(searchDateLayout, value)
+	if err != nil {
+		return nil, fmt.Errorf("invalid date %q", value)
+	}
+	if endOfDay {
+		t = t.AddDate(0, 0, 1)
+	}
+	return &t, nil
+}
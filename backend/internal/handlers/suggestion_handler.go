@@ -0,0 +1,32 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+)
+
+type SuggestionHandler struct {
+	suggestionService services.SuggestionService
+}
+
+func NewSuggestionHandler(suggestionService services.SuggestionService) *SuggestionHandler {
+	return &SuggestionHandler{suggestionService: suggestionService}
+}
+
+func (h *SuggestionHandler) GetUserSuggestions(w http.ResponseWriter, r *http.Request) {
+	limit, err := strconv.Atoi(r.URL.Query().Get("limit"))
+	if err != nil || limit <= 0 {
+		limit = defaultPageLimit
+	}
+
+	suggestions, err := h.suggestionService.GetSuggestions(r.Context(), currentUserID(r), limit)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, suggestions, "Suggestions retrieved successfully")
+}
@@ -54,7 +54,7 @@ func (h *UserHandler) GetUserByID(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	user, err := h.userService.GetUserByID(r.Context(), id)
+	user, err := h.userService.GetUserByID(r.Context(), currentUserID(r), id)
 	if err != nil {
 		common.ErrorResponse(w, http.StatusNotFound, "User not found", nil)
 		return
@@ -82,7 +82,7 @@ func (h *UserHandler) GetAllUsers(w http.ResponseWriter, r *http.Request) {
 		offset = 0
 	}
 
-	users, totalCount, err := h.userService.GetAllUsers(ctx, limit, offset)
+	users, totalCount, err := h.userService.GetAllUsers(ctx, currentUserID(r), limit, offset)
 	if err != nil {
 		http.Error(w, "Failed to retrieve users", http.StatusInternalServerError)
 		return
@@ -134,6 +134,72 @@ func (h *UserHandler) UpdateUser(w http.ResponseWriter, r *http.Request) {
 	common.SuccessResponse(w, user, "User updated successfully")
 }
 
+func (h *UserHandler) UpdatePrivacy(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdatePrivacyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		h.handleValidationErrors(w, err)
+		return
+	}
+
+	user, err := h.userService.UpdatePrivacy(r.Context(), currentUserID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, user, "Privacy updated successfully")
+}
+
+func (h *UserHandler) UpdateDMPolicy(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateDMPolicyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		h.handleValidationErrors(w, err)
+		return
+	}
+
+	user, err := h.userService.UpdateDMPolicy(r.Context(), currentUserID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, user, "Message settings updated successfully")
+}
+
+func (h *UserHandler) UpdateUsername(w http.ResponseWriter, r *http.Request) {
+	var req dto.UpdateUsernameRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid JSON", nil)
+		return
+	}
+
+	// Validate request
+	if err := h.validator.Struct(req); err != nil {
+		h.handleValidationErrors(w, err)
+		return
+	}
+
+	user, err := h.userService.UpdateUsername(r.Context(), currentUserID(r), req)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	common.SuccessResponse(w, user, "Username updated successfully")
+}
+
 func (h *UserHandler) DeleteUser(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.Atoi(r.PathValue("id"))
 	if err != nil {
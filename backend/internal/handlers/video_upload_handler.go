@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/services"
+	"github.com/escuadron-404/red404/backend/pkg/common"
+)
+
+// Resumable uploads follow the tus 1.0.0 protocol (https://tus.io) with the
+// creation, expiration and termination extensions
+const (
+	tusVersion      = "1.0.0"
+	tusExtensions   = "creation,expiration,termination"
+	tusContentType  = "application/offset+octet-stream"
+	videoUploadsURL = "/api/uploads/videos/"
+)
+
+type VideoUploadHandler struct {
+	uploadService services.VideoUploadService
+}
+
+func NewVideoUploadHandler(uploadService services.VideoUploadService) *VideoUploadHandler {
+	return &VideoUploadHandler{uploadService: uploadService}
+}
+
+// Options lets tus clients discover what the server supports
+func (h *VideoUploadHandler) Options(w http.ResponseWriter, _ *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Tus-Version", tusVersion)
+	w.Header().Set("Tus-Extension", tusExtensions)
+	w.Header().Set("Tus-Max-Size", strconv.FormatInt(h.uploadService.MaxBytes(), 10))
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Create starts an upload. The size comes from Upload-Length and the original
+// filename can be sent in Upload-Metadata.
+func (h *VideoUploadHandler) Create(w http.ResponseWriter, r *http.Request) {
+	if !checkTusResumable(w, r) {
+		return
+	}
+
+	length, err := strconv.ParseInt(r.Header.Get("Upload-Length"), 10, 64)
+	if err != nil {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid Upload-Length header", nil)
+		return
+	}
+
+	filename := parseTusMetadata(r.Header.Get("Upload-Metadata"))["filename"]
+	upload, err := h.uploadService.CreateUpload(r.Context(), currentUserID(r), length, filename)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	w.Header().Set("Location", videoUploadsURL+upload.ID)
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+	w.WriteHeader(http.StatusCreated)
+}
+
+// Head reports how many bytes were received so the client can resume
+func (h *VideoUploadHandler) Head(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	w.Header().Set("Cache-Control", "no-store")
+
+	upload, err := h.uploadService.GetUpload(r.Context(), currentUserID(r), r.PathValue("id"))
+	if err != nil {
+		w.WriteHeader(http.StatusNotFound)
+		return
+	}
+
+	writeUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusOK)
+}
+
+// Patch appends the request body at Upload-Offset. The last chunk is
+// validated and processed before the response is sent.
+func (h *VideoUploadHandler) Patch(w http.ResponseWriter, r *http.Request) {
+	if !checkTusResumable(w, r) {
+		return
+	}
+	if r.Header.Get("Content-Type") != tusContentType {
+		common.ErrorResponse(w, http.StatusUnsupportedMediaType, "Content-Type must be "+tusContentType, nil)
+		return
+	}
+
+	offset, err := strconv.ParseInt(r.Header.Get("Upload-Offset"), 10, 64)
+	if err != nil || offset < 0 {
+		common.ErrorResponse(w, http.StatusBadRequest, "Invalid Upload-Offset header", nil)
+		return
+	}
+
+	upload, err := h.uploadService.AppendChunk(r.Context(), currentUserID(r), r.PathValue("id"), offset, r.Body)
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+
+	writeUploadHeaders(w, upload)
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// Delete terminates an upload that is no longer needed
+func (h *VideoUploadHandler) Delete(w http.ResponseWriter, r *http.Request) {
+	if !checkTusResumable(w, r) {
+		return
+	}
+
+	if err := h.uploadService.DeleteUpload(r.Context(), currentUserID(r), r.PathValue("id")); err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// GetStatus tells the client whether a finished upload was accepted and can
+// be attached to a post
+func (h *VideoUploadHandler) GetStatus(w http.ResponseWriter, r *http.Request) {
+	upload, err := h.uploadService.GetUploadStatus(r.Context(), currentUserID(r), r.PathValue("id"))
+	if err != nil {
+		writeServiceError(w, err)
+		return
+	}
+	common.SuccessResponse(w, upload, "Upload retrieved successfully")
+}
+
+// checkTusResumable rejects clients speaking another protocol version
+func checkTusResumable(w http.ResponseWriter, r *http.Request) bool {
+	w.Header().Set("Tus-Resumable", tusVersion)
+	if r.Header.Get("Tus-Resumable") != tusVersion {
+		w.Header().Set("Tus-Version", tusVersion)
+		common.ErrorResponse(w, http.StatusPreconditionFailed, "Unsupported tus version", nil)
+		return false
+	}
+	return true
+}
+
+func writeUploadHeaders(w http.ResponseWriter, upload *models.VideoUpload) {
+	w.Header().Set("Upload-Offset", strconv.FormatInt(upload.UploadOffset, 10))
+	w.Header().Set("Upload-Length", strconv.FormatInt(upload.UploadLength, 10))
+	w.Header().Set("Upload-Expires", upload.ExpiresAt.UTC().Format(http.TimeFormat))
+}
+
+// parseTusMetadata decodes "key base64value,key base64value" pairs, skipping
+// malformed ones
+func parseTusMetadata(header string) map[string]string {
+	metadata := make(map[string]string)
+	for _, pair := range strings.Split(header, ",") {
+		key, encoded, _ := strings.Cut(strings.TrimSpace(pair), " ")
+		if key == "" {
+			continue
+		}
+		value, err := base64.StdEncoding.DecodeString(encoded)
+		if err != nil {
+			continue
+		}
+		metadata[key] = string(value)
+	}
+	return metadata
+}
@@ -0,0 +1,53 @@
+// Package jobs runs periodic background work next to the HTTP server
+package jobs
+
+import (
+	"context"
+	"log"
+	"sync"
+	"time"
+)
+
+// Job is a unit of work executed every Interval
+type Job struct {
+	Name     string
+	Interval time.Duration
+	Run      func(ctx context.Context) error
+}
+
+// Start runs every job on its own goroutine until ctx is canceled. Each job
+// runs once right away and then on every tick; a run that fails is logged
+// and retried on the next tick. The returned function blocks until all jobs
+// have returned, so the caller can wait for them during shutdown.
+func Start(ctx context.Context, jobs ...Job) (wait func()) {
+	var wg sync.WaitGroup
+
+	for _, job := range jobs {
+		wg.Add(1)
+		go func(job Job) {
+			defer wg.Done()
+			run(ctx, job)
+		}(job)
+	}
+
+	return wg.Wait
+}
+
+func run(ctx context.Context, job Job) {
+	log.Printf("Job %s started, running every %s", job.Name, job.Interval)
+	ticker := time.NewTicker(job.Interval)
+	defer ticker.Stop()
+
+	for {
+		if err := job.Run(ctx); err != nil && ctx.Err() == nil {
+			log.Printf("Job %s failed: %v", job.Name, err)
+		}
+
+		select {
+		case <-ctx.Done():
+			log.Printf("Job %s stopped", job.Name)
+			return
+		case <-ticker.C:
+		}
+	}
+}
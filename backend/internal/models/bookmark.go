@@ -0,0 +1,28 @@
+package models
+
+import "time"
+
+// Collection is a named group of bookmarks owned by a user
+type Collection struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	Name      string    `json:"name" db:"name"`
+	PostCount int       `json:"post_count" db:"-"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+}
+
+// SavedPost is a bookmarked post with its author and when it was saved
+type SavedPost struct {
+	PostWithAuthor
+	BookmarkID   int       `json:"bookmark_id"`
+	CollectionID *int      `json:"collection_id,omitempty"`
+	SavedAt      time.Time `json:"saved_at"`
+}
+
+// BookmarkCursor is the position of a bookmark in the saved list, newest
+// first
+type BookmarkCursor struct {
+	SavedAt time.Time `json:"t"`
+	ID      int       `json:"id"`
+}
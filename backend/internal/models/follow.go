@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+type FollowRequestStatus string
+
+const (
+	FollowRequestPending  FollowRequestStatus = "pending"
+	FollowRequestAccepted FollowRequestStatus = "accepted"
+	FollowRequestRejected FollowRequestStatus = "rejected"
+)
+
+type FollowRequest struct {
+	ID         int                 `json:"id" db:"id"`
+	SenderID   int                 `json:"sender_id" db:"sender_id"`
+	ReceiverID int                 `json:"receiver_id" db:"receiver_id"`
+	Status     FollowRequestStatus `json:"status" db:"status"`
+	CreatedAt  time.Time           `json:"created_at" db:"created_at"`
+	UpdatedAt  time.Time           `json:"updated_at" db:"updated_at"`
+}
+
+// FollowRequestWithSender is a follow request joined with the user who sent it
+type FollowRequestWithSender struct {
+	FollowRequest
+	Sender User `json:"sender"`
+}
+
+// Relationship describes how a viewer and another user are connected
+type Relationship struct {
+	IsFollowing     bool `json:"is_following"`
+	FollowsYou      bool `json:"follows_you"`
+	FollowRequested bool `json:"follow_requested"`
+	IsMuted         bool `json:"is_muted"`
+}
+
+// Suggestion is a user recommended to follow along with why
+type Suggestion struct {
+	User        User    `json:"user"`
+	Score       float64 `json:"score"`
+	MutualCount int     `json:"mutual_count"`
+	SharedTags  int     `json:"shared_tags"`
+}
@@ -0,0 +1,128 @@
+package models
+
+import "time"
+
+// DMPolicy is who may start a conversation with a user or message them
+// before they have replied
+type DMPolicy string
+
+const (
+	DMPolicyEveryone  DMPolicy = "everyone"
+	DMPolicyFollowers DMPolicy = "followers"
+	DMPolicyNobody    DMPolicy = "nobody"
+)
+
+type ConversationKind string
+
+const (
+	ConversationDirect ConversationKind = "direct"
+	ConversationGroup  ConversationKind = "group"
+)
+
+// Conversation is a one-to-one conversation or a group chat. Direct
+// conversations keep their two members in UserLowID and UserHighID, lowest
+// id first. Groups have a title and an owner instead.
+type Conversation struct {
+	ID             int              `json:"id" db:"id"`
+	Kind           ConversationKind `json:"kind" db:"kind"`
+	UserLowID      *int             `json:"user_low_id,omitempty" db:"user_low_id"`
+	UserHighID     *int             `json:"user_high_id,omitempty" db:"user_high_id"`
+	Title          *string          `json:"title,omitempty" db:"title"`
+	OwnerID        *int             `json:"owner_id,omitempty" db:"owner_id"`
+	LastMessageID  *int             `json:"last_message_id,omitempty" db:"last_message_id"`
+	LastActivityAt time.Time        `json:"last_activity_at" db:"last_activity_at"`
+	CreatedAt      time.Time        `json:"created_at" db:"created_at"`
+}
+
+// OtherMember returns the member of a direct conversation who is not userID
+func (c *Conversation) OtherMember(userID int) int {
+	if c.UserLowID != nil && *c.UserLowID == userID {
+		return *c.UserHighID
+	}
+	return *c.UserLowID
+}
+
+// MemberRole is what a member can do in a group. Owners manage admins,
+// admins manage members and the group itself.
+type MemberRole string
+
+const (
+	MemberRoleOwner  MemberRole = "owner"
+	MemberRoleAdmin  MemberRole = "admin"
+	MemberRoleMember MemberRole = "member"
+)
+
+// ConversationMember is a member of a conversation with their read position
+type ConversationMember struct {
+	ConversationID    int        `json:"conversation_id" db:"conversation_id"`
+	UserID            int        `json:"user_id" db:"user_id"`
+	Role              MemberRole `json:"role" db:"role"`
+	LastReadMessageID int        `json:"last_read_message_id" db:"last_read_message_id"`
+	JoinedAt          time.Time  `json:"joined_at" db:"joined_at"`
+}
+
+// ConversationMemberWithUser is a member joined with their user
+type ConversationMemberWithUser struct {
+	ConversationMember
+	User User `json:"user"`
+}
+
+type MessageKind string
+
+const (
+	MessageText   MessageKind = "text"
+	MessageSystem MessageKind = "system"
+)
+
+// SystemEvent is the membership change a system message records
+type SystemEvent string
+
+const (
+	EventGroupCreated  SystemEvent = "created"
+	EventMemberAdded   SystemEvent = "member_added"
+	EventMemberRemoved SystemEvent = "member_removed"
+	EventMemberLeft    SystemEvent = "member_left"
+	EventGroupRenamed  SystemEvent = "renamed"
+	EventAdminAdded    SystemEvent = "admin_added"
+	EventAdminRemoved  SystemEvent = "admin_removed"
+	EventOwnerChanged  SystemEvent = "owner_changed"
+)
+
+// Message is a text written by SenderID or, for system messages, a change
+// SenderID made to TargetUserID. The text of a rename is the new title.
+type Message struct {
+	ID             int          `json:"id" db:"id"`
+	ConversationID int          `json:"conversation_id" db:"conversation_id"`
+	SenderID       int          `json:"sender_id" db:"sender_id"`
+	Kind           MessageKind  `json:"kind" db:"kind"`
+	Event          *SystemEvent `json:"event,omitempty" db:"event"`
+	TargetUserID   *int         `json:"target_user_id,omitempty" db:"target_user_id"`
+	Text           string       `json:"text" db:"text"`
+	CreatedAt      time.Time    `json:"created_at" db:"created_at"`
+}
+
+// ConversationSummary is a conversation as listed in the inbox of a member
+type ConversationSummary struct {
+	Conversation
+	// Other is the other member of a direct conversation, nil for groups
+	Other       *User      `json:"other,omitempty"`
+	MyRole      MemberRole `json:"my_role" db:"role"`
+	MemberCount int        `json:"member_count"`
+	LastMessage *Message   `json:"last_message,omitempty"`
+	// UnreadCount counts the messages from others after the last one the
+	// member read
+	UnreadCount int `json:"unread_count"`
+}
+
+// ConversationCursor is the position after which the next page of the inbox
+// starts
+type ConversationCursor struct {
+	LastActivityAt time.Time `json:"t"`
+	ID             int       `json:"id"`
+}
+
+// MessageCursor is the message before which the next, older page of a
+// conversation starts
+type MessageCursor struct {
+	ID int `json:"id"`
+}
@@ -0,0 +1,27 @@
+package models
+
+import "time"
+
+type NotificationType string
+
+const (
+	NotificationMention NotificationType = "mention"
+)
+
+// Notification tells a user that someone else interacted with them
+type Notification struct {
+	ID        int              `json:"id" db:"id"`
+	UserID    int              `json:"user_id" db:"user_id"`
+	ActorID   int              `json:"actor_id" db:"actor_id"`
+	Type      NotificationType `json:"type" db:"type"`
+	PostID    *int             `json:"post_id,omitempty" db:"post_id"`
+	CommentID *int             `json:"comment_id,omitempty" db:"comment_id"`
+	ReadAt    *time.Time       `json:"read_at,omitempty" db:"read_at"`
+	CreatedAt time.Time        `json:"created_at" db:"created_at"`
+}
+
+// NotificationWithActor is a notification joined with the user who caused it
+type NotificationWithActor struct {
+	Notification
+	Actor User `json:"actor"`
+}
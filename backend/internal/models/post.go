@@ -0,0 +1,167 @@
+package models
+
+import "time"
+
+type Post struct {
+	ID           int        `json:"id" db:"id"`
+	UserID       int        `json:"user_id" db:"user_id"`
+	ImageURL     string     `json:"image_url" db:"image_url"`
+	Description  string     `json:"description" db:"description"`
+	LikeCount    int        `json:"like_count" db:"like_count"`
+	CommentCount int        `json:"comment_count" db:"comment_count"`
+	NSFW         bool       `json:"nsfw" db:"nsfw"`
+	CreatedAt    time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time  `json:"updated_at" db:"updated_at"`
+	Deleted      bool       `json:"deleted" db:"deleted"`
+	DeletedAt    *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+}
+
+// PostWithAuthor is a post joined with the user who wrote it
+type PostWithAuthor struct {
+	Post
+	Author User `json:"author"`
+}
+
+type MediaType string
+
+const (
+	MediaTypeImage MediaType = "image"
+	MediaTypeVideo MediaType = "video"
+)
+
+// PostMedia is one item of a post carousel, stored in blob storage
+type PostMedia struct {
+	ID          int       `json:"id" db:"id"`
+	PostID      int       `json:"post_id" db:"post_id"`
+	Position    int       `json:"position" db:"position"`
+	MediaType   MediaType `json:"media_type" db:"media_type"`
+	StorageKey  string    `json:"storage_key" db:"storage_key"`
+	ContentType string    `json:"content_type" db:"content_type"`
+	Width       int       `json:"width" db:"width"`
+	Height      int       `json:"height" db:"height"`
+	AltText     string    `json:"alt_text" db:"alt_text"`
+	PosterKey   string    `json:"poster_key" db:"poster_key"`
+	DurationMS  int       `json:"duration_ms" db:"duration_ms"`
+	CreatedAt   time.Time `json:"created_at" db:"created_at"`
+}
+
+type VideoUploadStatus string
+
+const (
+	VideoUploadUploading VideoUploadStatus = "uploading"
+	VideoUploadReady     VideoUploadStatus = "ready"
+	VideoUploadFailed    VideoUploadStatus = "failed"
+	VideoUploadAttached  VideoUploadStatus = "attached"
+)
+
+// VideoUpload tracks a resumable upload. Once ready, the processed video and
+// its poster are in storage waiting to be attached to a post.
+type VideoUpload struct {
+	ID           string            `json:"id" db:"id"`
+	UserID       int               `json:"user_id" db:"user_id"`
+	UploadLength int64             `json:"upload_length" db:"upload_length"`
+	UploadOffset int64             `json:"upload_offset" db:"upload_offset"`
+	Filename     string            `json:"filename" db:"filename"`
+	Status       VideoUploadStatus `json:"status" db:"status"`
+	Error        string            `json:"error" db:"error"`
+	StorageKey   string            `json:"storage_key" db:"storage_key"`
+	PosterKey    string            `json:"poster_key" db:"poster_key"`
+	ContentType  string            `json:"content_type" db:"content_type"`
+	Width        int               `json:"width" db:"width"`
+	Height       int               `json:"height" db:"height"`
+	DurationMS   int               `json:"duration_ms" db:"duration_ms"`
+	CreatedAt    time.Time         `json:"created_at" db:"created_at"`
+	UpdatedAt    time.Time         `json:"updated_at" db:"updated_at"`
+	ExpiresAt    time.Time         `json:"expires_at" db:"expires_at"`
+}
+
+type Hashtag struct {
+	ID        int       `json:"id" db:"id"`
+	Name      string    `json:"name" db:"name"`
+	PostCount int       `json:"post_count" db:"post_count"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// TrendingHashtag is a hashtag picked by the trending job with the score it
+// was ranked by
+type TrendingHashtag struct {
+	Hashtag
+	Score           float64   `json:"score" db:"score"`
+	RecentPostCount int       `json:"recent_post_count" db:"recent_post_count"`
+	ComputedAt      time.Time `json:"computed_at" db:"computed_at"`
+}
+
+// Mention links a range of a post or comment text to the mentioned user.
+// Offset and Length are in UTF-16 code units.
+type Mention struct {
+	ID              int       `json:"id" db:"id"`
+	PostID          int       `json:"post_id" db:"post_id"`
+	CommentID       *int      `json:"comment_id,omitempty" db:"comment_id"`
+	AuthorID        int       `json:"author_id" db:"author_id"`
+	MentionedUserID int       `json:"mentioned_user_id" db:"mentioned_user_id"`
+	Username        string    `json:"username" db:"username"`
+	Offset          int       `json:"offset" db:"start_offset"`
+	Length          int       `json:"length" db:"length"`
+	CreatedAt       time.Time `json:"created_at" db:"created_at"`
+}
+
+// Comment is a comment on a post, or a reply to one when ParentID is set.
+// Deleted comments keep their row so the replies under them stay in place.
+type Comment struct {
+	ID            int        `json:"id" db:"id"`
+	UserID        int        `json:"user_id" db:"user_id"`
+	PostID        int        `json:"post_id" db:"post_id"`
+	ParentID      *int       `json:"parent_id,omitempty" db:"parent_id"`
+	Text          string     `json:"text" db:"text"`
+	ReplyCount    int        `json:"reply_count" db:"reply_count"`
+	ReactionCount int        `json:"reaction_count" db:"reaction_count"`
+	CreatedAt     time.Time  `json:"created_at" db:"created_at"`
+	UpdatedAt     time.Time  `json:"updated_at" db:"updated_at"`
+	Deleted       bool       `json:"deleted" db:"deleted"`
+	DeletedAt     *time.Time `json:"deleted_at,omitempty" db:"deleted_at"`
+	// DeletedBy is who deleted the comment: its author, the post author or a
+	// moderator. It is nil when that account no longer exists.
+	DeletedBy      *int    `json:"deleted_by,omitempty" db:"deleted_by"`
+	DeletionReason *string `json:"deletion_reason,omitempty" db:"deletion_reason"`
+}
+
+// CommentDeletion tells apart comments deleted by their author from comments
+// removed by someone else
+type CommentDeletion string
+
+const (
+	CommentDeletedBySelf     CommentDeletion = "self"
+	CommentRemovedModeration CommentDeletion = "moderation"
+)
+
+// PostCursor is the position of a post in a reverse chronological list
+type PostCursor struct {
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"id"`
+}
+
+// CommentWithAuthor is a comment joined with the user who wrote it
+type CommentWithAuthor struct {
+	Comment
+	Author User `json:"author"`
+	// Score is the popularity the comment was listed by, as of the snapshot
+	// time of the cursor
+	Score int `json:"-"`
+}
+
+type CommentSort string
+
+const (
+	CommentSortNew CommentSort = "new"
+	CommentSortTop CommentSort = "top"
+)
+
+// CommentCursor is the position after which the next page of comments
+// starts. Score and At, the time popularity is counted at, are only used when
+// sorting by popularity and At is set by the first page.
+type CommentCursor struct {
+	Score     int       `json:"s,omitempty"`
+	At        time.Time `json:"at"`
+	CreatedAt time.Time `json:"t"`
+	ID        int       `json:"id"`
+}
@@ -0,0 +1,32 @@
+package models
+
+import "time"
+
+// ReactionTarget is the kind of item a reaction is on
+type ReactionTarget string
+
+const (
+	ReactionOnPost    ReactionTarget = "post"
+	ReactionOnComment ReactionTarget = "comment"
+)
+
+type Reaction struct {
+	ID        int       `json:"id" db:"id"`
+	UserID    int       `json:"user_id" db:"user_id"`
+	PostID    *int      `json:"post_id,omitempty" db:"post_id"`
+	CommentID *int      `json:"comment_id,omitempty" db:"comment_id"`
+	Emoji     string    `json:"emoji" db:"emoji"`
+	CreatedAt time.Time `json:"created_at" db:"created_at"`
+}
+
+// ReactionWithUser is a reaction joined with the user who reacted
+type ReactionWithUser struct {
+	Reaction
+	User User `json:"user"`
+}
+
+// ReactionCount is how many users reacted to an item with Emoji
+type ReactionCount struct {
+	Emoji string `json:"emoji"`
+	Count int    `json:"count"`
+}
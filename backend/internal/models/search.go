@@ -0,0 +1,48 @@
+package models
+
+import "time"
+
+// SearchLanguage picks the text search configuration a query is stemmed
+// with. Empty searches with both Spanish and English.
+type SearchLanguage string
+
+const (
+	SearchLanguageAny     SearchLanguage = ""
+	SearchLanguageSpanish SearchLanguage = "es"
+	SearchLanguageEnglish SearchLanguage = "en"
+)
+
+// Snippets mark the matched words with these private use characters so they
+// can be told apart from the text itself once it is escaped
+const (
+	SnippetMatchStart = "\ue000"
+	SnippetMatchEnd   = "\ue001"
+)
+
+// SearchFilter is a full-text query over posts or comments. Text uses web
+// search syntax: quoted phrases, OR and -excluded words. Hashtag and Mention
+// are normalized, and From and To bound the creation time, To excluded.
+type SearchFilter struct {
+	Text     string
+	Language SearchLanguage
+	Hashtag  string
+	Mention  string
+	From     *time.Time
+	To       *time.Time
+}
+
+// PostSearchHit is a post matching a search with a snippet of its
+// description around the matches
+type PostSearchHit struct {
+	PostWithAuthor
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
+
+// CommentSearchHit is a comment matching a search with a snippet of its text
+// around the matches
+type CommentSearchHit struct {
+	CommentWithAuthor
+	Snippet string  `json:"snippet"`
+	Rank    float64 `json:"rank"`
+}
@@ -0,0 +1,50 @@
+package models
+
+import "time"
+
+type FanoutJobKind string
+
+const (
+	// FanoutPost pushes a new post into the timelines of its author's followers
+	FanoutPost FanoutJobKind = "post"
+	// FanoutFollow backfills the recent posts of a newly followed account
+	FanoutFollow FanoutJobKind = "follow"
+	// FanoutTimeline backfills the timeline of a user from every account they
+	// follow and marks it as materialized
+	FanoutTimeline FanoutJobKind = "timeline"
+)
+
+// FanoutJob is a pending unit of work of the timeline fan-out worker.
+// Timeline jobs have no author, FollowerID owns the timeline.
+type FanoutJob struct {
+	ID         int64         `json:"id" db:"id"`
+	Kind       FanoutJobKind `json:"kind" db:"kind"`
+	PostID     *int          `json:"post_id,omitempty" db:"post_id"`
+	FollowerID *int          `json:"follower_id,omitempty" db:"follower_id"`
+	AuthorID   *int          `json:"author_id,omitempty" db:"author_id"`
+	Attempts   int           `json:"attempts" db:"attempts"`
+	RunAfter   time.Time     `json:"run_after" db:"run_after"`
+	CreatedAt  time.Time     `json:"created_at" db:"created_at"`
+}
+
+// FanoutLimits bounds the work done for one job
+type FanoutLimits struct {
+	// MaxFollowers is the follower count above which posts are not pushed to
+	// followers but merged into their feed when it is read
+	MaxFollowers int
+	// BackfillPosts is how many recent posts a new follow brings in
+	BackfillPosts int
+}
+
+// RankingCandidate is a post considered for the ranked feed with the signals
+// it is scored on
+type RankingCandidate struct {
+	PostWithAuthor
+	SaveCount int `json:"save_count"`
+	// AuthorInteractions counts the recent likes, comments, reactions and
+	// saves of the viewer on posts of the same author
+	AuthorInteractions int `json:"author_interactions"`
+	// HashtagInteractions counts the recent interactions of the viewer with
+	// posts sharing a hashtag with this one
+	HashtagInteractions int `json:"hashtag_interactions"`
+}
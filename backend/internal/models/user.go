@@ -5,12 +5,26 @@ import (
 	"time"
 )
 
+// Roles a user can have. Moderators and admins can remove any comment.
+const (
+	RoleUser      = "user"
+	RoleModerator = "moderator"
+	RoleAdmin     = "admin"
+)
+
 type User struct {
 	ID        int       `json:"id" db:"id"`
 	Email     string    `json:"email" db:"email"`
+	Username  string    `json:"username" db:"username"`
 	Password  string    `json:"-" db:"password"`
+	IsPrivate bool      `json:"is_private" db:"is_private"`
+	Role      string    `json:"role" db:"role"`
+	DMPolicy  DMPolicy  `json:"dm_policy" db:"dm_policy"`
 	CreatedAt time.Time `json:"created_at" db:"created_at"`
 	UpdatedAt time.Time `json:"updated_at" db:"updated_at"`
+
+	FollowersCount int `json:"followers_count" db:"followers_count"`
+	FollowingCount int `json:"following_count" db:"following_count"`
 }
 
 type UserWithoutPassword struct {
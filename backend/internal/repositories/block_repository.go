@@ -0,0 +1,137 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BlockRepository interface {
+	Block(ctx context.Context, blockerID, blockedID int) error
+	Unblock(ctx context.Context, blockerID, blockedID int) (bool, error)
+	IsBlocked(ctx context.Context, userID, otherID int) (bool, error)
+	GetBlocked(ctx context.Context, blockerID, limit, offset int) ([]models.User, int, error)
+	Mute(ctx context.Context, muterID, mutedID int) error
+	Unmute(ctx context.Context, muterID, mutedID int) (bool, error)
+	GetMuted(ctx context.Context, muterID, limit, offset int) ([]models.User, int, error)
+}
+
+type blockRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBlockRepository(db *pgxpool.Pool) BlockRepository {
+	return &blockRepository{db: db}
+}
+
+// Block records the block and tears down every follow and pending follow
+// request between the two users, in both directions
+func (r *blockRepository) Block(ctx context.Context, blockerID, blockedID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `INSERT INTO user_blocks (blocker_id, blocked_id, created_at) VALUES ($1, $2, $3)
+              ON CONFLICT (blocker_id, blocked_id) DO NOTHING`
+	if _, err := tx.Exec(ctx, query, blockerID, blockedID, time.Now()); err != nil {
+		return fmt.Errorf("failed to insert block: %w", err)
+	}
+
+	if _, err := deleteFollow(ctx, tx, blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to remove follow: %w", err)
+	}
+	if _, err := deleteFollow(ctx, tx, blockedID, blockerID); err != nil {
+		return fmt.Errorf("failed to remove follow: %w", err)
+	}
+
+	requestsQuery := `DELETE FROM follow_request
+                      WHERE status = 'pending'
+                        AND ((sender_id = $1 AND receiver_id = $2) OR (sender_id = $2 AND receiver_id = $1))`
+	if _, err := tx.Exec(ctx, requestsQuery, blockerID, blockedID); err != nil {
+		return fmt.Errorf("failed to remove follow requests: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *blockRepository) Unblock(ctx context.Context, blockerID, blockedID int) (bool, error) {
+	query := `DELETE FROM user_blocks WHERE blocker_id = $1 AND blocked_id = $2`
+	tag, err := r.db.Exec(ctx, query, blockerID, blockedID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// IsBlocked reports whether either user has blocked the other
+func (r *blockRepository) IsBlocked(ctx context.Context, userID, otherID int) (bool, error) {
+	query := `SELECT NOT ` + notBlockedSQL("$1", "$2::int")
+	var blocked bool
+	err := r.db.QueryRow(ctx, query, userID, otherID).Scan(&blocked)
+	return blocked, err
+}
+
+func (r *blockRepository) GetBlocked(ctx context.Context, blockerID, limit, offset int) ([]models.User, int, error) {
+	countQuery := `SELECT COUNT(*) FROM user_blocks WHERE blocker_id = $1`
+	query := `SELECT ` + userColumnsOf("u") + `
+              FROM user_blocks b
+              JOIN users u ON u.id = b.blocked_id
+              WHERE b.blocker_id = $1
+              ORDER BY b.created_at DESC
+              LIMIT $2 OFFSET $3`
+	return r.listUsers(ctx, countQuery, query, blockerID, limit, offset)
+}
+
+func (r *blockRepository) Mute(ctx context.Context, muterID, mutedID int) error {
+	query := `INSERT INTO user_mutes (muter_id, muted_id, created_at) VALUES ($1, $2, $3)
+              ON CONFLICT (muter_id, muted_id) DO NOTHING`
+	_, err := r.db.Exec(ctx, query, muterID, mutedID, time.Now())
+	return err
+}
+
+func (r *blockRepository) Unmute(ctx context.Context, muterID, mutedID int) (bool, error) {
+	query := `DELETE FROM user_mutes WHERE muter_id = $1 AND muted_id = $2`
+	tag, err := r.db.Exec(ctx, query, muterID, mutedID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+func (r *blockRepository) GetMuted(ctx context.Context, muterID, limit, offset int) ([]models.User, int, error) {
+	countQuery := `SELECT COUNT(*) FROM user_mutes WHERE muter_id = $1`
+	query := `SELECT ` + userColumnsOf("u") + `
+              FROM user_mutes m
+              JOIN users u ON u.id = m.muted_id
+              WHERE m.muter_id = $1
+              ORDER BY m.created_at DESC
+              LIMIT $2 OFFSET $3`
+	return r.listUsers(ctx, countQuery, query, muterID, limit, offset)
+}
+
+func (r *blockRepository) listUsers(ctx context.Context, countQuery, query string, userID, limit, offset int) ([]models.User, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, countQuery, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count users: %w", err)
+	}
+	if total == 0 {
+		return []models.User{}, 0, nil
+	}
+
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query users: %w", err)
+	}
+	defer rows.Close()
+
+	users, err := collectUsers(rows, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
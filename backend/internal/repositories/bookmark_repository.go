@@ -0,0 +1,220 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type BookmarkRepository interface {
+	Save(ctx context.Context, userID, postID int, collectionID *int) error
+	Remove(ctx context.Context, userID, postID int) error
+	GetSavedPostIDs(ctx context.Context, userID int, postIDs []int) (map[int]bool, error)
+	GetSaved(ctx context.Context, userID int, collectionID *int, after *models.BookmarkCursor, limit int) ([]models.SavedPost, error)
+
+	CreateCollection(ctx context.Context, collection *models.Collection) error
+	GetCollection(ctx context.Context, userID, id int) (*models.Collection, error)
+	GetCollections(ctx context.Context, userID int) ([]models.Collection, error)
+	RenameCollection(ctx context.Context, userID, id int, name string) error
+	DeleteCollection(ctx context.Context, userID, id int) error
+}
+
+type bookmarkRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewBookmarkRepository(db *pgxpool.Pool) BookmarkRepository {
+	return &bookmarkRepository{db: db}
+}
+
+// savedVisibleSQL keeps the bookmarks of $1 whose post is live and still
+// visible to them. Bookmarks of hidden posts stay stored, so they come back
+// if the post becomes visible again.
+var savedVisibleSQL = `p.deleted = FALSE AND ` + visibleToSQL("$1", "u.id", "u.is_private")
+
+// Save bookmarks the post in collectionID, or outside of any collection when
+// it is nil. Saving an already saved post moves it to collectionID and keeps
+// its place in the saved list.
+func (r *bookmarkRepository) Save(ctx context.Context, userID, postID int, collectionID *int) error {
+	query := `INSERT INTO bookmarks (user_id, post_id, collection_id, created_at) VALUES ($1, $2, $3, $4)
+              ON CONFLICT (user_id, post_id) DO UPDATE SET collection_id = EXCLUDED.collection_id`
+	if _, err := r.db.Exec(ctx, query, userID, postID, collectionID, time.Now()); err != nil {
+		return fmt.Errorf("failed to save bookmark: %w", err)
+	}
+	return nil
+}
+
+// Remove deletes the bookmark. Removing a missing bookmark is a no-op.
+func (r *bookmarkRepository) Remove(ctx context.Context, userID, postID int) error {
+	query := `DELETE FROM bookmarks WHERE user_id = $1 AND post_id = $2`
+	if _, err := r.db.Exec(ctx, query, userID, postID); err != nil {
+		return fmt.Errorf("failed to remove bookmark: %w", err)
+	}
+	return nil
+}
+
+// GetSavedPostIDs reports which of postIDs userID bookmarked
+func (r *bookmarkRepository) GetSavedPostIDs(ctx context.Context, userID int, postIDs []int) (map[int]bool, error) {
+	saved := make(map[int]bool, len(postIDs))
+	if userID == 0 || len(postIDs) == 0 {
+		return saved, nil
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT post_id FROM bookmarks WHERE user_id = $1 AND post_id = ANY($2)`, userID, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query bookmarks: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int
+		if err := rows.Scan(&postID); err != nil {
+			return nil, fmt.Errorf("failed to scan bookmark row: %w", err)
+		}
+		saved[postID] = true
+	}
+	return saved, rows.Err()
+}
+
+// GetSaved lists the posts userID bookmarked after the cursor, most recently
+// saved first, optionally only those of one collection
+func (r *bookmarkRepository) GetSaved(ctx context.Context, userID int, collectionID *int, after *models.BookmarkCursor,
+	limit int) ([]models.SavedPost, error) {
+	if after == nil {
+		after = &models.BookmarkCursor{}
+	}
+
+	query := `SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `, b.id, b.collection_id, b.created_at
+              FROM bookmarks b
+              JOIN posts p ON p.id = b.post_id
+              JOIN users u ON u.id = p.user_id
+              WHERE b.user_id = $1 AND ($2::int IS NULL OR b.collection_id = $2)
+                AND ` + savedVisibleSQL + `
+                AND ($4::int = 0 OR (b.created_at, b.id) < ($3, $4))
+              ORDER BY b.created_at DESC, b.id DESC
+              LIMIT $5`
+	rows, err := r.db.Query(ctx, query, userID, collectionID, after.SavedAt, after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query saved posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts := make([]models.SavedPost, 0, limit)
+	for rows.Next() {
+		var post models.SavedPost
+		targets := append(postScanTargets(&post.Post), userScanTargets(&post.Author)...)
+		targets = append(targets, &post.BookmarkID, &post.CollectionID, &post.SavedAt)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan saved post row: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during saved post rows iteration: %w", err)
+	}
+	return posts, nil
+}
+
+// CreateCollection inserts the collection. Names are unique per user, ignoring
+// case, and a duplicate returns ErrConflict.
+func (r *bookmarkRepository) CreateCollection(ctx context.Context, collection *models.Collection) error {
+	now := time.Now()
+	collection.CreatedAt = now
+	collection.UpdatedAt = now
+	query := `INSERT INTO collections (user_id, name, created_at, updated_at) VALUES ($1, $2, $3, $4) RETURNING id`
+	err := r.db.QueryRow(ctx, query, collection.UserID, collection.Name, collection.CreatedAt, collection.UpdatedAt).
+		Scan(&collection.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to insert collection: %w", err)
+	}
+	return nil
+}
+
+// collectionSelect loads collections with the number of saved posts in them
+// that their owner, $1, can still see
+const collectionSelect = `SELECT c.id, c.user_id, c.name, c.created_at, c.updated_at,
+                                 (SELECT COUNT(*) FROM bookmarks b
+                                  JOIN posts p ON p.id = b.post_id
+                                  JOIN users u ON u.id = p.user_id
+                                  WHERE b.collection_id = c.id AND `
+
+func collectionQuery(where string) string {
+	return collectionSelect + savedVisibleSQL + `)
+              FROM collections c
+              WHERE c.user_id = $1 ` + where
+}
+
+func scanCollection(row pgx.Row, collection *models.Collection) error {
+	return row.Scan(&collection.ID, &collection.UserID, &collection.Name, &collection.CreatedAt, &collection.UpdatedAt,
+		&collection.PostCount)
+}
+
+// GetCollection returns a collection of userID
+func (r *bookmarkRepository) GetCollection(ctx context.Context, userID, id int) (*models.Collection, error) {
+	collection := &models.Collection{}
+	if err := scanCollection(r.db.QueryRow(ctx, collectionQuery(`AND c.id = $2`), userID, id), collection); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return collection, nil
+}
+
+// GetCollections lists the collections of userID by name
+func (r *bookmarkRepository) GetCollections(ctx context.Context, userID int) ([]models.Collection, error) {
+	rows, err := r.db.Query(ctx, collectionQuery(`ORDER BY lower(c.name), c.id`), userID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collections: %w", err)
+	}
+	defer rows.Close()
+
+	collections := []models.Collection{}
+	for rows.Next() {
+		var collection models.Collection
+		if err := scanCollection(rows, &collection); err != nil {
+			return nil, fmt.Errorf("failed to scan collection row: %w", err)
+		}
+		collections = append(collections, collection)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during collection rows iteration: %w", err)
+	}
+	return collections, nil
+}
+
+// RenameCollection changes the name of a collection of userID
+func (r *bookmarkRepository) RenameCollection(ctx context.Context, userID, id int, name string) error {
+	query := `UPDATE collections SET name = $1, updated_at = $2 WHERE id = $3 AND user_id = $4`
+	tag, err := r.db.Exec(ctx, query, name, time.Now(), id, userID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to rename collection: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// DeleteCollection deletes a collection of userID. Its posts stay saved.
+func (r *bookmarkRepository) DeleteCollection(ctx context.Context, userID, id int) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM collections WHERE id = $1 AND user_id = $2`, id, userID)
+	if err != nil {
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
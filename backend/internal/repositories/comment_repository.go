@@ -0,0 +1,359 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// commentColumnsOf is the column list shared by every query that loads a
+// comment and must match the order expected by commentScanTargets
+func commentColumnsOf(alias string) string {
+	return strings.NewReplacer("c.", alias+".").Replace(
+		`c.id, c.user_id, c.post_id, c.parent_id, c.text, c.reply_count, c.reaction_count,
+		 c.created_at, c.updated_at, c.deleted, c.deleted_at, c.deleted_by, c.deletion_reason`)
+}
+
+// commentScanTargets returns the scan destinations matching commentColumnsOf
+func commentScanTargets(comment *models.Comment) []any {
+	return []any{&comment.ID, &comment.UserID, &comment.PostID, &comment.ParentID, &comment.Text,
+		&comment.ReplyCount, &comment.ReactionCount, &comment.CreatedAt, &comment.UpdatedAt, &comment.Deleted, &comment.DeletedAt,
+		&comment.DeletedBy, &comment.DeletionReason}
+}
+
+type CommentRepository interface {
+	Create(ctx context.Context, comment *models.Comment, mentions []models.Mention) ([]int, error)
+	GetByID(ctx context.Context, id int) (*models.Comment, error)
+	GetVisibleByID(ctx context.Context, viewerID, id int) (*models.Comment, error)
+	GetByPost(ctx context.Context, viewerID, postID int, sort models.CommentSort, after *models.CommentCursor,
+		limit int) ([]models.CommentWithAuthor, error)
+	GetReplies(ctx context.Context, viewerID, parentID int, after *models.CommentCursor, limit int) ([]models.CommentWithAuthor, error)
+	Search(ctx context.Context, viewerID int, filter models.SearchFilter, limit, offset int) ([]models.CommentSearchHit, int, error)
+	Update(ctx context.Context, comment *models.Comment, mentions []models.Mention) ([]int, error)
+	SoftDelete(ctx context.Context, comment *models.Comment) error
+}
+
+type commentRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewCommentRepository(db *pgxpool.Pool) CommentRepository {
+	return &commentRepository{db: db}
+}
+
+// Create inserts the comment with its mentions and bumps the comment count
+// of the post and, for replies, the reply count of the parent. It returns the
+// users notified of a new mention.
+func (r *commentRepository) Create(ctx context.Context, comment *models.Comment, mentions []models.Mention) ([]int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	now := time.Now()
+	comment.CreatedAt = now
+	comment.UpdatedAt = now
+	query := `INSERT INTO comments (user_id, post_id, parent_id, text, created_at, updated_at, deleted)
+              VALUES ($1, $2, $3, $4, $5, $6, FALSE) RETURNING id`
+	if err := tx.QueryRow(ctx, query, comment.UserID, comment.PostID, comment.ParentID, comment.Text,
+		comment.CreatedAt, comment.UpdatedAt).Scan(&comment.ID); err != nil {
+		return nil, fmt.Errorf("failed to insert comment: %w", err)
+	}
+
+	if err := updateCommentCounts(ctx, tx, comment, 1); err != nil {
+		return nil, err
+	}
+	notified, err := syncMentions(ctx, tx, comment.PostID, &comment.ID, comment.UserID, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return notified, nil
+}
+
+// GetByID returns a live comment without any visibility checks, for
+// internal use such as ownership checks
+func (r *commentRepository) GetByID(ctx context.Context, id int) (*models.Comment, error) {
+	query := `SELECT ` + commentColumnsOf("c") + ` FROM comments c WHERE c.id = $1 AND c.deleted = FALSE`
+	comment := &models.Comment{}
+	if err := r.db.QueryRow(ctx, query, id).Scan(commentScanTargets(comment)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetVisibleByID returns a live comment if the viewer can see both the post
+// it belongs to and its author
+func (r *commentRepository) GetVisibleByID(ctx context.Context, viewerID, id int) (*models.Comment, error) {
+	query := `SELECT ` + commentColumnsOf("c") + `
+              FROM comments c
+              JOIN posts p ON p.id = c.post_id
+              JOIN users pu ON pu.id = p.user_id
+              WHERE c.id = $1 AND c.deleted = FALSE AND p.deleted = FALSE
+                AND ` + visibleToSQL("$2", "pu.id", "pu.is_private") + `
+                AND ` + notBlockedSQL("$2", "c.user_id")
+	comment := &models.Comment{}
+	if err := r.db.QueryRow(ctx, query, id, viewerID).Scan(commentScanTargets(comment)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return comment, nil
+}
+
+// GetByPost lists the top level comments of a post after the cursor, newest
+// or most popular first. Deleted comments are only kept while they have
+// replies, and comments of users with a block in either direction with the
+// viewer are left out. The caller checks that the post is visible.
+func (r *commentRepository) GetByPost(ctx context.Context, viewerID, postID int, sort models.CommentSort,
+	after *models.CommentCursor, limit int) ([]models.CommentWithAuthor, error) {
+	if after == nil {
+		after = &models.CommentCursor{}
+	}
+
+	ranking := ``
+	score := `0`
+	snapshot := `TRUE`
+	order := `c.created_at DESC, c.id DESC`
+	position := `(c.created_at, c.id) < ($3, $4)`
+	args := []any{postID, viewerID, after.CreatedAt, after.ID, limit}
+	if sort == models.CommentSortTop {
+		// Popularity counts reactions and live replies as of the snapshot time
+		// of the first page, and comments written since are left out, so
+		// activity while paging does not move comments across pages
+		ranking = `CROSS JOIN LATERAL (
+                  SELECT (SELECT COUNT(*) FROM reactions cr WHERE cr.comment_id = c.id AND cr.created_at <= $6)
+                       + (SELECT COUNT(*) FROM comments rc
+                          WHERE rc.parent_id = c.id AND rc.created_at <= $6
+                            AND (rc.deleted = FALSE OR rc.deleted_at > $6)) AS score
+              ) s`
+		score = `s.score`
+		snapshot = `c.created_at <= $6`
+		order = `s.score DESC, c.id DESC`
+		position = `(s.score, c.id) < ($3, $4)`
+		args = []any{postID, viewerID, after.Score, after.ID, limit, after.At}
+	}
+
+	query := `SELECT ` + commentColumnsOf("c") + `, ` + userColumnsOf("u") + `, ` + score + `
+              FROM comments c
+              JOIN users u ON u.id = c.user_id
+              ` + ranking + `
+              WHERE c.post_id = $1 AND c.parent_id IS NULL
+                AND (c.deleted = FALSE OR c.reply_count > 0)
+                AND ` + snapshot + `
+                AND ` + notBlockedSQL("$2", "u.id") + `
+                AND ($4::int = 0 OR ` + position + `)
+              ORDER BY ` + order + `
+              LIMIT $5`
+	rows, err := r.db.Query(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query comments: %w", err)
+	}
+	defer rows.Close()
+
+	return collectCommentsWithAuthor(rows, limit, true)
+}
+
+// GetReplies lists the live replies to a comment after the cursor, oldest
+// first so a thread reads like a conversation. The parent may be deleted, but
+// the list is empty when the viewer cannot see the post or the parent author.
+func (r *commentRepository) GetReplies(ctx context.Context, viewerID, parentID int, after *models.CommentCursor,
+	limit int) ([]models.CommentWithAuthor, error) {
+	query := `SELECT ` + commentColumnsOf("c") + `, ` + userColumnsOf("u") + `
+              FROM comments c
+              JOIN users u ON u.id = c.user_id
+              JOIN comments pc ON pc.id = c.parent_id
+              JOIN posts p ON p.id = pc.post_id
+              JOIN users pu ON pu.id = p.user_id
+              WHERE c.parent_id = $1 AND c.deleted = FALSE AND p.deleted = FALSE
+                AND ` + visibleToSQL("$2", "pu.id", "pu.is_private") + `
+                AND ` + notBlockedSQL("$2", "pc.user_id") + `
+                AND ` + notBlockedSQL("$2", "u.id") + `
+                AND ($4::int = 0 OR (c.created_at, c.id) > ($3, $4))
+              ORDER BY c.created_at, c.id
+              LIMIT $5`
+	if after == nil {
+		after = &models.CommentCursor{}
+	}
+	rows, err := r.db.Query(ctx, query, parentID, viewerID, after.CreatedAt, after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query replies: %w", err)
+	}
+	defer rows.Close()
+
+	return collectCommentsWithAuthor(rows, limit, false)
+}
+
+// Search pages through the live comments matching the filter, best matches
+// first. Only comments on posts the viewer can see are searched, blocked and
+// muted commenters are left out. The hashtag filter matches comments on
+// posts using the tag.
+func (r *commentRepository) Search(ctx context.Context, viewerID int, filter models.SearchFilter,
+	limit, offset int) ([]models.CommentSearchHit, int, error) {
+	from := `FROM comments c
+             JOIN users u ON u.id = c.user_id
+             JOIN posts p ON p.id = c.post_id
+             JOIN users pu ON pu.id = p.user_id
+             CROSS JOIN (SELECT ` + searchQuerySQL("$2", "$3", "$4") + ` AS query) q
+             WHERE c.deleted = FALSE AND p.deleted = FALSE
+               ` + searchMatchSQL(filter.Text, "c.search_vector") + `
+               AND ($5 = '' OR EXISTS (SELECT 1 FROM post_tags pt JOIN hashtags h ON h.id = pt.tag_id
+                                       WHERE pt.post_id = c.post_id AND h.name = $5))
+               AND ($6 = '' OR EXISTS (SELECT 1 FROM mentions m JOIN users mu ON mu.id = m.mentioned_user_id
+                                       WHERE m.comment_id = c.id AND mu.username = $6))
+               AND ($7::timestamp IS NULL OR c.created_at >= $7)
+               AND ($8::timestamp IS NULL OR c.created_at < $8)
+               AND ` + visibleToSQL("$1", "pu.id", "pu.is_private") + `
+               AND ` + notBlockedSQL("$1", "u.id") + `
+               AND ` + notMutedSQL("$1", "u.id")
+	args := searchArgs(viewerID, filter)
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) `+from, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count matching comments: %w", err)
+	}
+	if total == 0 {
+		return []models.CommentSearchHit{}, 0, nil
+	}
+
+	query := `SELECT ` + commentColumnsOf("c") + `, ` + userColumnsOf("u") + `,
+                     ts_headline($3::regconfig, c.text, q.query, $9),
+                     ts_rank_cd(c.search_vector, q.query)::float8 AS rank
+              ` + from + `
+              ORDER BY rank DESC, c.created_at DESC, c.id DESC
+              LIMIT $10 OFFSET $11`
+	rows, err := r.db.Query(ctx, query, append(args, headlineOptions, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search comments: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]models.CommentSearchHit, 0, limit)
+	for rows.Next() {
+		var hit models.CommentSearchHit
+		targets := append(commentScanTargets(&hit.Comment), userScanTargets(&hit.Author)...)
+		targets = append(targets, &hit.Snippet, &hit.Rank)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan comment search row: %w", err)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error during comment search rows iteration: %w", err)
+	}
+	return hits, total, nil
+}
+
+// Update saves the edited text and replaces the mentions of the comment. It
+// returns the users notified of a new mention.
+func (r *commentRepository) Update(ctx context.Context, comment *models.Comment, mentions []models.Mention) ([]int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `UPDATE comments SET text = $1, updated_at = $2 WHERE id = $3 AND deleted = FALSE`
+	tag, err := tx.Exec(ctx, query, comment.Text, comment.UpdatedAt, comment.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+
+	notified, err := syncMentions(ctx, tx, comment.PostID, &comment.ID, comment.UserID, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return notified, nil
+}
+
+// SoftDelete flags the comment as deleted by comment.DeletedBy, with the
+// reason if one was given, and stops counting it. The row stays so its
+// replies keep their place in the thread, and its mentions and their
+// notifications go away.
+func (r *commentRepository) SoftDelete(ctx context.Context, comment *models.Comment) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	now := time.Now()
+	query := `UPDATE comments SET deleted = TRUE, deleted_at = $1, updated_at = $1, deleted_by = $2, deletion_reason = $3
+              WHERE id = $4 AND deleted = FALSE`
+	tag, err := tx.Exec(ctx, query, now, comment.DeletedBy, comment.DeletionReason, comment.ID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	if err := updateCommentCounts(ctx, tx, comment, -1); err != nil {
+		return err
+	}
+	if _, err := syncMentions(ctx, tx, comment.PostID, &comment.ID, comment.UserID, nil); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// updateCommentCounts moves the comment count of the post and, for replies,
+// the reply count of the parent by delta. The parent is updated first, like
+// every other write touching both rows, so they cannot deadlock.
+func updateCommentCounts(ctx context.Context, tx pgx.Tx, comment *models.Comment, delta int) error {
+	if comment.ParentID != nil {
+		query := `UPDATE comments SET reply_count = reply_count + $1 WHERE id = $2`
+		if _, err := tx.Exec(ctx, query, delta, *comment.ParentID); err != nil {
+			return fmt.Errorf("failed to update reply count: %w", err)
+		}
+	}
+
+	query := `UPDATE posts SET comment_count = comment_count + $1 WHERE id = $2`
+	if _, err := tx.Exec(ctx, query, delta, comment.PostID); err != nil {
+		return fmt.Errorf("failed to update comment count: %w", err)
+	}
+	return nil
+}
+
+// collectCommentsWithAuthor scans rows selected with commentColumnsOf
+// followed by userColumnsOf and, when withScore is set, the popularity score
+func collectCommentsWithAuthor(rows pgx.Rows, capacity int, withScore bool) ([]models.CommentWithAuthor, error) {
+	comments := make([]models.CommentWithAuthor, 0, capacity)
+	for rows.Next() {
+		var comment models.CommentWithAuthor
+		targets := append(commentScanTargets(&comment.Comment), userScanTargets(&comment.Author)...)
+		if withScore {
+			targets = append(targets, &comment.Score)
+		}
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan comment row: %w", err)
+		}
+		comments = append(comments, comment)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during comment rows iteration: %w", err)
+	}
+	return comments, nil
+}
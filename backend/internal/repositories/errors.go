@@ -0,0 +1,19 @@
+package repositories
+
+import (
+	"errors"
+
+	"github.com/jackc/pgx/v5/pgconn"
+)
+
+// ErrNotFound is returned when the requested row does not exist
+var ErrNotFound = errors.New("record not found")
+
+// ErrConflict is returned when a write breaks a unique constraint
+var ErrConflict = errors.New("record already exists")
+
+// isUniqueViolation reports whether err comes from a unique constraint
+func isUniqueViolation(err error) bool {
+	var pgErr *pgconn.PgError
+	return errors.As(err, &pgErr) && pgErr.Code == "23505"
+}
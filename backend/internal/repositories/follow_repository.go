@@ -0,0 +1,368 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type FollowRepository interface {
+	IsFollowing(ctx context.Context, followerID, followedID int) (bool, error)
+	Follow(ctx context.Context, followerID, followedID int) (bool, error)
+	Unfollow(ctx context.Context, followerID, followedID int) (bool, error)
+	GetFollowers(ctx context.Context, viewerID, userID, limit, offset int) ([]models.User, int, error)
+	GetFollowing(ctx context.Context, viewerID, userID, limit, offset int) ([]models.User, int, error)
+	GetRelationships(ctx context.Context, viewerID int, userIDs []int) (map[int]models.Relationship, error)
+	CancelRequest(ctx context.Context, senderID, receiverID int) (bool, error)
+	CreateRequest(ctx context.Context, request *models.FollowRequest) error
+	GetRequestByID(ctx context.Context, id int) (*models.FollowRequest, error)
+	GetPendingRequest(ctx context.Context, senderID, receiverID int) (*models.FollowRequest, error)
+	GetIncomingRequests(ctx context.Context, receiverID, limit, offset int) ([]models.FollowRequestWithSender, int, error)
+	AcceptRequest(ctx context.Context, id int) error
+	RejectRequest(ctx context.Context, id int) error
+	AcceptAllPending(ctx context.Context, receiverID int) ([]int, error)
+}
+
+type followRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewFollowRepository(db *pgxpool.Pool) FollowRepository {
+	return &followRepository{db: db}
+}
+
+func (r *followRepository) IsFollowing(ctx context.Context, followerID, followedID int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM followers WHERE follower_id = $1 AND followed_id = $2)`
+	var exists bool
+	err := r.db.QueryRow(ctx, query, followerID, followedID).Scan(&exists)
+	return exists, err
+}
+
+func (r *followRepository) CreateRequest(ctx context.Context, request *models.FollowRequest) error {
+	now := time.Now()
+	request.Status = models.FollowRequestPending
+	request.CreatedAt = now
+	request.UpdatedAt = now
+	query := `INSERT INTO follow_request (sender_id, receiver_id, status, created_at, updated_at)
+              VALUES ($1, $2, $3, $4, $5) RETURNING id`
+	err := r.db.QueryRow(ctx, query, request.SenderID, request.ReceiverID, request.Status, request.CreatedAt, request.UpdatedAt).
+		Scan(&request.ID)
+	if err != nil {
+		if isUniqueViolation(err) {
+			return ErrConflict
+		}
+		return fmt.Errorf("failed to insert follow request: %w", err)
+	}
+	return nil
+}
+
+func (r *followRepository) GetRequestByID(ctx context.Context, id int) (*models.FollowRequest, error) {
+	query := `SELECT id, sender_id, receiver_id, status, created_at, updated_at FROM follow_request WHERE id = $1`
+	return r.scanRequest(r.db.QueryRow(ctx, query, id))
+}
+
+func (r *followRepository) GetPendingRequest(ctx context.Context, senderID, receiverID int) (*models.FollowRequest, error) {
+	query := `SELECT id, sender_id, receiver_id, status, created_at, updated_at FROM follow_request
+              WHERE sender_id = $1 AND receiver_id = $2 AND status = 'pending'`
+	return r.scanRequest(r.db.QueryRow(ctx, query, senderID, receiverID))
+}
+
+func (r *followRepository) scanRequest(row pgx.Row) (*models.FollowRequest, error) {
+	request := &models.FollowRequest{}
+	err := row.Scan(&request.ID, &request.SenderID, &request.ReceiverID, &request.Status, &request.CreatedAt, &request.UpdatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return request, nil
+}
+
+func (r *followRepository) GetIncomingRequests(ctx context.Context, receiverID, limit, offset int) ([]models.FollowRequestWithSender, int, error) {
+	var total int
+	countQuery := `SELECT COUNT(*) FROM follow_request WHERE receiver_id = $1 AND status = 'pending'`
+	if err := r.db.QueryRow(ctx, countQuery, receiverID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count follow requests: %w", err)
+	}
+	if total == 0 {
+		return []models.FollowRequestWithSender{}, 0, nil
+	}
+
+	query := `SELECT fr.id, fr.sender_id, fr.receiver_id, fr.status, fr.created_at, fr.updated_at, ` + userColumnsOf("u") + `
+              FROM follow_request fr
+              JOIN users u ON u.id = fr.sender_id
+              WHERE fr.receiver_id = $1 AND fr.status = 'pending'
+              ORDER BY fr.created_at DESC, fr.id DESC
+              LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, query, receiverID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query follow requests: %w", err)
+	}
+	defer rows.Close()
+
+	requests := make([]models.FollowRequestWithSender, 0, limit)
+	for rows.Next() {
+		var request models.FollowRequestWithSender
+		targets := append([]any{
+			&request.ID, &request.SenderID, &request.ReceiverID, &request.Status, &request.CreatedAt, &request.UpdatedAt,
+		}, userScanTargets(&request.Sender)...)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan follow request row: %w", err)
+		}
+		requests = append(requests, request)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error during follow request rows iteration: %w", err)
+	}
+
+	return requests, total, nil
+}
+
+// AcceptRequest marks the request as accepted and creates the follow in the
+// same transaction
+func (r *followRepository) AcceptRequest(ctx context.Context, id int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	var senderID, receiverID int
+	updateQuery := `UPDATE follow_request SET status = 'accepted', updated_at = $1
+                    WHERE id = $2 AND status = 'pending' RETURNING sender_id, receiver_id`
+	if err := tx.QueryRow(ctx, updateQuery, time.Now(), id).Scan(&senderID, &receiverID); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return ErrNotFound
+		}
+		return err
+	}
+
+	if _, err := insertFollow(ctx, tx, senderID, receiverID); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+func (r *followRepository) RejectRequest(ctx context.Context, id int) error {
+	query := `UPDATE follow_request SET status = 'rejected', updated_at = $1 WHERE id = $2 AND status = 'pending'`
+	tag, err := r.db.Exec(ctx, query, time.Now(), id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// AcceptAllPending is used when an account goes public: whoever was waiting
+// for approval becomes a follower. It returns the senders of the accepted
+// requests.
+func (r *followRepository) AcceptAllPending(ctx context.Context, receiverID int) ([]int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `UPDATE follow_request SET status = 'accepted', updated_at = $1
+              WHERE receiver_id = $2 AND status = 'pending' RETURNING sender_id`
+	rows, err := tx.Query(ctx, query, time.Now(), receiverID)
+	if err != nil {
+		return nil, err
+	}
+	senders, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, senderID := range senders {
+		if _, err := insertFollow(ctx, tx, senderID, receiverID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return senders, nil
+}
+
+// Follow creates the follow and bumps both counters. It reports false when
+// the follow already existed, which makes the operation idempotent.
+func (r *followRepository) Follow(ctx context.Context, followerID, followedID int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	created, err := insertFollow(ctx, tx, followerID, followedID)
+	if err != nil {
+		return false, err
+	}
+
+	return created, tx.Commit(ctx)
+}
+
+func (r *followRepository) Unfollow(ctx context.Context, followerID, followedID int) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	removed, err := deleteFollow(ctx, tx, followerID, followedID)
+	if err != nil {
+		return false, err
+	}
+
+	return removed, tx.Commit(ctx)
+}
+
+func (r *followRepository) CancelRequest(ctx context.Context, senderID, receiverID int) (bool, error) {
+	query := `DELETE FROM follow_request WHERE sender_id = $1 AND receiver_id = $2 AND status = 'pending'`
+	tag, err := r.db.Exec(ctx, query, senderID, receiverID)
+	if err != nil {
+		return false, err
+	}
+	return tag.RowsAffected() > 0, nil
+}
+
+// GetFollowers lists who follows userID. Users on either side of a block
+// with the viewer are left out of the page and of the total.
+func (r *followRepository) GetFollowers(ctx context.Context, viewerID, userID, limit, offset int) ([]models.User, int, error) {
+	from := `FROM followers f
+              JOIN users u ON u.id = f.follower_id
+              WHERE f.followed_id = $1 AND ` + notBlockedSQL("$2", "u.id")
+	return r.listUsers(ctx, from, viewerID, userID, limit, offset)
+}
+
+func (r *followRepository) GetFollowing(ctx context.Context, viewerID, userID, limit, offset int) ([]models.User, int, error) {
+	from := `FROM followers f
+              JOIN users u ON u.id = f.followed_id
+              WHERE f.follower_id = $1 AND ` + notBlockedSQL("$2", "u.id")
+	return r.listUsers(ctx, from, viewerID, userID, limit, offset)
+}
+
+// listUsers counts and pages the users matched by from, which filters on
+// userID in $1 and viewerID in $2. The total is counted with the same filter
+// as the page, so blocked users do not show up in it either.
+func (r *followRepository) listUsers(ctx context.Context, from string, viewerID, userID, limit, offset int) ([]models.User, int, error) {
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) `+from, userID, viewerID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count follows: %w", err)
+	}
+	if total == 0 {
+		return []models.User{}, 0, nil
+	}
+
+	query := `SELECT ` + userColumnsOf("u") + ` ` + from + `
+              ORDER BY f.created_at DESC, f.id DESC
+              LIMIT $3 OFFSET $4`
+	rows, err := r.db.Query(ctx, query, userID, viewerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query follows: %w", err)
+	}
+	defer rows.Close()
+
+	users, err := collectUsers(rows, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return users, total, nil
+}
+
+// GetRelationships loads the follow state between the viewer and each of the
+// given users in a single round trip
+func (r *followRepository) GetRelationships(ctx context.Context, viewerID int, userIDs []int) (map[int]models.Relationship, error) {
+	relationships := make(map[int]models.Relationship, len(userIDs))
+	if viewerID == 0 || len(userIDs) == 0 {
+		return relationships, nil
+	}
+
+	query := `SELECT u.id,
+                     EXISTS (SELECT 1 FROM followers WHERE follower_id = $1 AND followed_id = u.id),
+                     EXISTS (SELECT 1 FROM followers WHERE follower_id = u.id AND followed_id = $1),
+                     EXISTS (SELECT 1 FROM follow_request WHERE sender_id = $1 AND receiver_id = u.id AND status = 'pending'),
+                     EXISTS (SELECT 1 FROM user_mutes WHERE muter_id = $1 AND muted_id = u.id)
+              FROM unnest($2::int[]) AS u(id)`
+	rows, err := r.db.Query(ctx, query, viewerID, userIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationships: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var relationship models.Relationship
+		if err := rows.Scan(&id, &relationship.IsFollowing, &relationship.FollowsYou, &relationship.FollowRequested, &relationship.IsMuted); err != nil {
+			return nil, fmt.Errorf("failed to scan relationship row: %w", err)
+		}
+		relationships[id] = relationship
+	}
+
+	return relationships, rows.Err()
+}
+
+// insertFollow creates the follow row and keeps the counters of both users in
+// sync. The unique constraint makes concurrent duplicates a no-op.
+func insertFollow(ctx context.Context, tx pgx.Tx, followerID, followedID int) (bool, error) {
+	query := `INSERT INTO followers (follower_id, followed_id, created_at) VALUES ($1, $2, $3)
+              ON CONFLICT (follower_id, followed_id) DO NOTHING`
+	tag, err := tx.Exec(ctx, query, followerID, followedID, time.Now())
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	return true, updateFollowCounts(ctx, tx, followerID, followedID, 1)
+}
+
+// deleteFollow also takes the posts of the account out of the materialized
+// timeline of the follower
+func deleteFollow(ctx context.Context, tx pgx.Tx, followerID, followedID int) (bool, error) {
+	query := `DELETE FROM followers WHERE follower_id = $1 AND followed_id = $2`
+	tag, err := tx.Exec(ctx, query, followerID, followedID)
+	if err != nil {
+		return false, err
+	}
+	if tag.RowsAffected() == 0 {
+		return false, nil
+	}
+
+	timelineQuery := `DELETE FROM timeline_entries WHERE user_id = $1 AND author_id = $2`
+	if _, err := tx.Exec(ctx, timelineQuery, followerID, followedID); err != nil {
+		return false, fmt.Errorf("failed to clear timeline entries: %w", err)
+	}
+
+	return true, updateFollowCounts(ctx, tx, followerID, followedID, -1)
+}
+
+// updateFollowCounts touches the two user rows in id order so concurrent
+// follows between the same pair of users cannot deadlock
+func updateFollowCounts(ctx context.Context, tx pgx.Tx, followerID, followedID, delta int) error {
+	query := `UPDATE users SET
+                  following_count = following_count + CASE WHEN id = $2 THEN $1 ELSE 0 END,
+                  followers_count = followers_count + CASE WHEN id = $3 THEN $1 ELSE 0 END
+              WHERE id = $4`
+	first, second := followerID, followedID
+	if first > second {
+		first, second = second, first
+	}
+	for _, id := range []int{first, second} {
+		if _, err := tx.Exec(ctx, query, delta, followerID, followedID, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,37 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type HashtagRepository interface {
+	GetByName(ctx context.Context, name string) (*models.Hashtag, error)
+}
+
+type hashtagRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewHashtagRepository(db *pgxpool.Pool) HashtagRepository {
+	return &hashtagRepository{db: db}
+}
+
+// GetByName looks up a normalized tag. Hashtags removed by moderators are
+// reported as missing.
+func (r *hashtagRepository) GetByName(ctx context.Context, name string) (*models.Hashtag, error) {
+	query := `SELECT id, name, post_count, created_at FROM hashtags WHERE name = $1 AND deleted = FALSE`
+	hashtag := &models.Hashtag{}
+	err := r.db.QueryRow(ctx, query, name).Scan(&hashtag.ID, &hashtag.Name, &hashtag.PostCount, &hashtag.CreatedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return hashtag, nil
+}
@@ -0,0 +1,91 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type LikeRepository interface {
+	Like(ctx context.Context, userID, postID int) (int, error)
+	Unlike(ctx context.Context, userID, postID int) (int, error)
+	GetLikedPostIDs(ctx context.Context, userID int, postIDs []int) (map[int]bool, error)
+}
+
+type likeRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewLikeRepository(db *pgxpool.Pool) LikeRepository {
+	return &likeRepository{db: db}
+}
+
+// Like records the like and bumps the counter of the post in the same
+// transaction, returning the new count. The unique constraint makes liking
+// twice a no-op, and the counter update locks the post row, so concurrent
+// likes are all counted exactly once.
+func (r *likeRepository) Like(ctx context.Context, userID, postID int) (int, error) {
+	query := `INSERT INTO likes (user_id, post_id, created_at) VALUES ($1, $2, $3)
+              ON CONFLICT (user_id, post_id) DO NOTHING`
+	return r.change(ctx, postID, 1, query, userID, postID, time.Now())
+}
+
+// Unlike removes the like and returns the new count of the post. Unliking a
+// post that was not liked is a no-op.
+func (r *likeRepository) Unlike(ctx context.Context, userID, postID int) (int, error) {
+	query := `DELETE FROM likes WHERE user_id = $1 AND post_id = $2`
+	return r.change(ctx, postID, -1, query, userID, postID)
+}
+
+// change runs query and moves the like counter of postID by delta when the
+// query touched a row
+func (r *likeRepository) change(ctx context.Context, postID, delta int, query string, args ...any) (int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	tag, err := tx.Exec(ctx, query, args...)
+	if err != nil {
+		return 0, err
+	}
+
+	countQuery := `SELECT like_count FROM posts WHERE id = $1`
+	countArgs := []any{postID}
+	if tag.RowsAffected() > 0 {
+		countQuery = `UPDATE posts SET like_count = like_count + $1 WHERE id = $2 RETURNING like_count`
+		countArgs = []any{delta, postID}
+	}
+
+	var count int
+	if err := tx.QueryRow(ctx, countQuery, countArgs...).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to update like count: %w", err)
+	}
+	return count, tx.Commit(ctx)
+}
+
+// GetLikedPostIDs reports which of postIDs userID has liked
+func (r *likeRepository) GetLikedPostIDs(ctx context.Context, userID int, postIDs []int) (map[int]bool, error) {
+	liked := make(map[int]bool, len(postIDs))
+	if userID == 0 || len(postIDs) == 0 {
+		return liked, nil
+	}
+
+	rows, err := r.db.Query(ctx, `SELECT post_id FROM likes WHERE user_id = $1 AND post_id = ANY($2)`, userID, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query likes: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var postID int
+		if err := rows.Scan(&postID); err != nil {
+			return nil, fmt.Errorf("failed to scan like row: %w", err)
+		}
+		liked[postID] = true
+	}
+	return liked, rows.Err()
+}
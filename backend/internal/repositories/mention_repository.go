@@ -0,0 +1,130 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type MentionRepository interface {
+	GetByPosts(ctx context.Context, viewerID int, postIDs []int) (map[int][]models.Mention, error)
+	GetByComments(ctx context.Context, viewerID int, commentIDs []int) (map[int][]models.Mention, error)
+}
+
+type mentionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMentionRepository(db *pgxpool.Pool) MentionRepository {
+	return &mentionRepository{db: db}
+}
+
+// GetByPosts returns the mentions in the description of every post in
+// postIDs, keyed by post id and ordered by offset. Users blocking the viewer
+// or blocked by them are left out.
+func (r *mentionRepository) GetByPosts(ctx context.Context, viewerID int, postIDs []int) (map[int][]models.Mention, error) {
+	return r.get(ctx, viewerID, postIDs, `m.post_id = ANY($1) AND m.comment_id IS NULL`, func(m *models.Mention) int {
+		return m.PostID
+	})
+}
+
+// GetByComments is GetByPosts for the text of comments, keyed by comment id
+func (r *mentionRepository) GetByComments(ctx context.Context, viewerID int, commentIDs []int) (map[int][]models.Mention, error) {
+	return r.get(ctx, viewerID, commentIDs, `m.comment_id = ANY($1)`, func(m *models.Mention) int {
+		return *m.CommentID
+	})
+}
+
+// get loads the mentions matching where, which filters on the ids in $1, and
+// groups them with key
+func (r *mentionRepository) get(ctx context.Context, viewerID int, ids []int, where string,
+	key func(*models.Mention) int) (map[int][]models.Mention, error) {
+	mentions := make(map[int][]models.Mention, len(ids))
+	if len(ids) == 0 {
+		return mentions, nil
+	}
+
+	query := `SELECT m.id, m.post_id, m.comment_id, m.author_id, m.mentioned_user_id, u.username,
+                     m.start_offset, m.length, m.created_at
+              FROM mentions m
+              JOIN users u ON u.id = m.mentioned_user_id
+              WHERE ` + where + `
+                AND ` + notBlockedSQL("$2", "m.mentioned_user_id") + `
+              ORDER BY m.start_offset`
+	rows, err := r.db.Query(ctx, query, ids, viewerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query mentions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var mention models.Mention
+		if err := rows.Scan(&mention.ID, &mention.PostID, &mention.CommentID, &mention.AuthorID, &mention.MentionedUserID,
+			&mention.Username, &mention.Offset, &mention.Length, &mention.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan mention row: %w", err)
+		}
+		mentions[key(&mention)] = append(mentions[key(&mention)], mention)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during mention rows iteration: %w", err)
+	}
+	return mentions, nil
+}
+
+// syncMentions replaces the mentions of a post description, or of one of its
+// comments when commentID is set. Usernames are resolved here so the stored
+// links survive renames, and names that do not exist, belong to someone with
+// a block in either direction with the author, or to someone who cannot see
+// the post, such as non-followers of a private account, are dropped. Users
+// mentioned for the first time get a notification, and users no longer
+// mentioned lose theirs. It returns the ids of the users who were notified.
+func syncMentions(ctx context.Context, tx pgx.Tx, postID int, commentID *int, authorID int, mentions []models.Mention) ([]int, error) {
+	usernames := make([]string, 0, len(mentions))
+	offsets := make([]int, 0, len(mentions))
+	lengths := make([]int, 0, len(mentions))
+	for _, mention := range mentions {
+		usernames = append(usernames, mention.Username)
+		offsets = append(offsets, mention.Offset)
+		lengths = append(lengths, mention.Length)
+	}
+
+	query := `WITH previous AS (
+                  DELETE FROM mentions
+                  WHERE post_id = $1 AND comment_id IS NOT DISTINCT FROM $2
+                  RETURNING mentioned_user_id
+              ), inserted AS (
+                  INSERT INTO mentions (post_id, comment_id, author_id, mentioned_user_id, start_offset, length, created_at)
+                  SELECT $1, $2, $3, u.id, m.start_offset, m.length, now()
+                  FROM unnest($4::text[], $5::int[], $6::int[]) AS m(username, start_offset, length)
+                  JOIN users u ON u.username = m.username
+                  JOIN posts p ON p.id = $1
+                  JOIN users pu ON pu.id = p.user_id
+                  WHERE ` + notBlockedSQL("$3", "u.id") + `
+                    AND ` + visibleToSQL("u.id", "pu.id", "pu.is_private") + `
+                  RETURNING mentioned_user_id
+              ), notified AS (
+                  INSERT INTO notifications (user_id, actor_id, type, post_id, comment_id, created_at)
+                  SELECT DISTINCT mentioned_user_id, $3::int, 'mention', $1::int, $2::int, now()
+                  FROM inserted
+                  WHERE mentioned_user_id <> $3
+                    AND mentioned_user_id NOT IN (SELECT mentioned_user_id FROM previous)
+                  RETURNING user_id
+              ), removed AS (
+                  DELETE FROM notifications
+                  WHERE type = 'mention' AND post_id = $1 AND comment_id IS NOT DISTINCT FROM $2
+                    AND user_id NOT IN (SELECT mentioned_user_id FROM inserted)
+              )
+              SELECT user_id FROM notified`
+	rows, err := tx.Query(ctx, query, postID, commentID, authorID, usernames, offsets, lengths)
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync mentions: %w", err)
+	}
+	notified, err := pgx.CollectRows(rows, pgx.RowTo[int])
+	if err != nil {
+		return nil, fmt.Errorf("failed to sync mentions: %w", err)
+	}
+	return notified, nil
+}
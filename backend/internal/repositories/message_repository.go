@@ -0,0 +1,503 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const conversationColumns = `c.id, c.kind, c.user_low_id, c.user_high_id, c.title, c.owner_id, c.last_message_id,
+                             c.last_activity_at, c.created_at`
+
+// conversationScanTargets returns the scan destinations matching
+// conversationColumns
+func conversationScanTargets(conversation *models.Conversation) []any {
+	return []any{&conversation.ID, &conversation.Kind, &conversation.UserLowID, &conversation.UserHighID, &conversation.Title,
+		&conversation.OwnerID, &conversation.LastMessageID, &conversation.LastActivityAt, &conversation.CreatedAt}
+}
+
+const messageColumns = `m.id, m.conversation_id, m.sender_id, m.kind, m.event, m.target_user_id, m.text, m.created_at`
+
+// messageScanTargets returns the scan destinations matching messageColumns
+func messageScanTargets(message *models.Message) []any {
+	return []any{&message.ID, &message.ConversationID, &message.SenderID, &message.Kind, &message.Event,
+		&message.TargetUserID, &message.Text, &message.CreatedAt}
+}
+
+type MessageRepository interface {
+	GetOrCreateDirect(ctx context.Context, userID, otherID int) (*models.Conversation, error)
+	GetForMember(ctx context.Context, conversationID, userID int) (*models.Conversation, error)
+	GetConversations(ctx context.Context, userID int, after *models.ConversationCursor, limit int) ([]models.ConversationSummary, error)
+	GetMessages(ctx context.Context, conversationID int, before *models.MessageCursor, limit int) ([]models.Message, error)
+	HasSent(ctx context.Context, conversationID, userID int) (bool, error)
+	Create(ctx context.Context, message *models.Message) error
+	MarkRead(ctx context.Context, conversationID, userID int) error
+
+	CreateGroup(ctx context.Context, conversation *models.Conversation, memberIDs []int) error
+	GetMember(ctx context.Context, conversationID, userID int) (*models.ConversationMember, error)
+	GetMembers(ctx context.Context, conversationID int) ([]models.ConversationMemberWithUser, error)
+	GetMemberIDs(ctx context.Context, conversationID int) ([]int, error)
+	AddMembers(ctx context.Context, conversationID, actorID int, userIDs []int) error
+	RemoveMember(ctx context.Context, conversationID, actorID, userID int, event models.SystemEvent, successorID *int) error
+	SetRole(ctx context.Context, conversationID, actorID, userID int, role models.MemberRole, event models.SystemEvent) error
+	TransferOwnership(ctx context.Context, conversationID, ownerID, newOwnerID int) error
+	Rename(ctx context.Context, conversationID, actorID int, title string) error
+}
+
+type messageRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewMessageRepository(db *pgxpool.Pool) MessageRepository {
+	return &messageRepository{db: db}
+}
+
+// GetOrCreateDirect returns the conversation between the two users, creating
+// it with both of them as members the first time
+func (r *messageRepository) GetOrCreateDirect(ctx context.Context, userID, otherID int) (*models.Conversation, error) {
+	low, high := min(userID, otherID), max(userID, otherID)
+
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	conversation := &models.Conversation{}
+	query := `INSERT INTO conversations AS c (kind, user_low_id, user_high_id) VALUES ('direct', $1, $2)
+              ON CONFLICT (user_low_id, user_high_id) DO NOTHING
+              RETURNING ` + conversationColumns
+	err = tx.QueryRow(ctx, query, low, high).Scan(conversationScanTargets(conversation)...)
+	if errors.Is(err, pgx.ErrNoRows) {
+		existing := `SELECT ` + conversationColumns + ` FROM conversations c WHERE c.user_low_id = $1 AND c.user_high_id = $2`
+		if err := tx.QueryRow(ctx, existing, low, high).Scan(conversationScanTargets(conversation)...); err != nil {
+			return nil, fmt.Errorf("failed to get conversation: %w", err)
+		}
+		return conversation, tx.Commit(ctx)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create conversation: %w", err)
+	}
+
+	members := `INSERT INTO conversation_members (conversation_id, user_id) VALUES ($1, $2), ($1, $3)`
+	if _, err := tx.Exec(ctx, members, conversation.ID, low, high); err != nil {
+		return nil, fmt.Errorf("failed to add conversation members: %w", err)
+	}
+	return conversation, tx.Commit(ctx)
+}
+
+// GetForMember loads a conversation userID is a member of. Conversations of
+// other users are reported as missing.
+func (r *messageRepository) GetForMember(ctx context.Context, conversationID, userID int) (*models.Conversation, error) {
+	query := `SELECT ` + conversationColumns + `
+              FROM conversations c
+              JOIN conversation_members cm ON cm.conversation_id = c.id
+              WHERE c.id = $1 AND cm.user_id = $2`
+	conversation := &models.Conversation{}
+	if err := r.db.QueryRow(ctx, query, conversationID, userID).Scan(conversationScanTargets(conversation)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return conversation, nil
+}
+
+// GetConversations lists the inbox of the user after the cursor, most recent
+// activity first. Direct conversations without messages yet and those with a
+// user blocked in either direction are left out.
+func (r *messageRepository) GetConversations(ctx context.Context, userID int, after *models.ConversationCursor,
+	limit int) ([]models.ConversationSummary, error) {
+	// Groups join the member themselves so every row has a user, it is
+	// dropped after the scan
+	query := `SELECT ` + conversationColumns + `, cm.role, ` + userColumnsOf("ou") + `,
+                     (SELECT COUNT(*) FROM conversation_members mc WHERE mc.conversation_id = c.id),
+                     ` + messageColumns + `,
+                     (SELECT COUNT(*) FROM messages um
+                      WHERE um.conversation_id = c.id AND um.id > cm.last_read_message_id AND um.sender_id <> $1)
+              FROM conversation_members cm
+              JOIN conversations c ON c.id = cm.conversation_id
+              JOIN users ou ON ou.id = CASE WHEN c.kind = 'group' THEN $1
+                                            WHEN c.user_low_id = $1 THEN c.user_high_id
+                                            ELSE c.user_low_id END
+              JOIN messages m ON m.id = c.last_message_id
+              WHERE cm.user_id = $1
+                AND (c.kind = 'group' OR ` + notBlockedSQL("$1", "ou.id") + `)
+                AND ($3::int = 0 OR (c.last_activity_at, c.id) < ($2, $3))
+              ORDER BY c.last_activity_at DESC, c.id DESC
+              LIMIT $4`
+	if after == nil {
+		after = &models.ConversationCursor{}
+	}
+	rows, err := r.db.Query(ctx, query, userID, after.LastActivityAt, after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversations: %w", err)
+	}
+	defer rows.Close()
+
+	conversations := make([]models.ConversationSummary, 0, limit)
+	for rows.Next() {
+		var summary models.ConversationSummary
+		other := &models.User{}
+		last := &models.Message{}
+		targets := append(conversationScanTargets(&summary.Conversation), &summary.MyRole)
+		targets = append(targets, userScanTargets(other)...)
+		targets = append(targets, &summary.MemberCount)
+		targets = append(targets, messageScanTargets(last)...)
+		targets = append(targets, &summary.UnreadCount)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation row: %w", err)
+		}
+		if summary.Kind == models.ConversationDirect {
+			summary.Other = other
+		}
+		summary.LastMessage = last
+		conversations = append(conversations, summary)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during conversation rows iteration: %w", err)
+	}
+	return conversations, nil
+}
+
+// GetMessages lists the messages of a conversation before the cursor, newest
+// first
+func (r *messageRepository) GetMessages(ctx context.Context, conversationID int, before *models.MessageCursor,
+	limit int) ([]models.Message, error) {
+	query := `SELECT ` + messageColumns + `
+              FROM messages m
+              WHERE m.conversation_id = $1 AND ($2::int = 0 OR m.id < $2)
+              ORDER BY m.id DESC
+              LIMIT $3`
+	if before == nil {
+		before = &models.MessageCursor{}
+	}
+	rows, err := r.db.Query(ctx, query, conversationID, before.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query messages: %w", err)
+	}
+	defer rows.Close()
+
+	messages := make([]models.Message, 0, limit)
+	for rows.Next() {
+		var message models.Message
+		if err := rows.Scan(messageScanTargets(&message)...); err != nil {
+			return nil, fmt.Errorf("failed to scan message row: %w", err)
+		}
+		messages = append(messages, message)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during message rows iteration: %w", err)
+	}
+	return messages, nil
+}
+
+// HasSent reports whether the user wrote any message in the conversation
+func (r *messageRepository) HasSent(ctx context.Context, conversationID, userID int) (bool, error) {
+	query := `SELECT EXISTS (SELECT 1 FROM messages WHERE conversation_id = $1 AND sender_id = $2 AND kind = 'text')`
+	var sent bool
+	err := r.db.QueryRow(ctx, query, conversationID, userID).Scan(&sent)
+	return sent, err
+}
+
+// Create stores a text message
+func (r *messageRepository) Create(ctx context.Context, message *models.Message) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	message.Kind = models.MessageText
+	if err := insertMessage(ctx, tx, message); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// MarkRead marks every message of the conversation as read by the user
+func (r *messageRepository) MarkRead(ctx context.Context, conversationID, userID int) error {
+	query := `UPDATE conversation_members cm
+              SET last_read_message_id = GREATEST(cm.last_read_message_id, COALESCE(c.last_message_id, 0))
+              FROM conversations c
+              WHERE c.id = cm.conversation_id AND cm.conversation_id = $1 AND cm.user_id = $2`
+	_, err := r.db.Exec(ctx, query, conversationID, userID)
+	return err
+}
+
+// CreateGroup creates a group owned by conversation.OwnerID with the other
+// members in memberIDs, and records its creation
+func (r *messageRepository) CreateGroup(ctx context.Context, conversation *models.Conversation, memberIDs []int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	conversation.Kind = models.ConversationGroup
+	query := `INSERT INTO conversations (kind, title, owner_id) VALUES ('group', $1, $2)
+              RETURNING id, last_activity_at, created_at`
+	if err := tx.QueryRow(ctx, query, conversation.Title, conversation.OwnerID).
+		Scan(&conversation.ID, &conversation.LastActivityAt, &conversation.CreatedAt); err != nil {
+		return fmt.Errorf("failed to create group: %w", err)
+	}
+
+	memberQuery := `INSERT INTO conversation_members (conversation_id, user_id, role) VALUES ($1, $2, $3)
+                    ON CONFLICT DO NOTHING`
+	if _, err := tx.Exec(ctx, memberQuery, conversation.ID, *conversation.OwnerID, models.MemberRoleOwner); err != nil {
+		return fmt.Errorf("failed to add group owner: %w", err)
+	}
+	for _, memberID := range memberIDs {
+		if _, err := tx.Exec(ctx, memberQuery, conversation.ID, memberID, models.MemberRoleMember); err != nil {
+			return fmt.Errorf("failed to add group member: %w", err)
+		}
+	}
+
+	created := systemMessage(conversation.ID, *conversation.OwnerID, models.EventGroupCreated, nil)
+	created.Text = *conversation.Title
+	if err := insertMessage(ctx, tx, created); err != nil {
+		return err
+	}
+	conversation.LastMessageID = &created.ID
+	conversation.LastActivityAt = created.CreatedAt
+
+	return tx.Commit(ctx)
+}
+
+// GetMember returns the membership of userID in the conversation
+func (r *messageRepository) GetMember(ctx context.Context, conversationID, userID int) (*models.ConversationMember, error) {
+	query := `SELECT conversation_id, user_id, role, last_read_message_id, joined_at
+              FROM conversation_members WHERE conversation_id = $1 AND user_id = $2`
+	member := &models.ConversationMember{}
+	err := r.db.QueryRow(ctx, query, conversationID, userID).
+		Scan(&member.ConversationID, &member.UserID, &member.Role, &member.LastReadMessageID, &member.JoinedAt)
+	if err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return member, nil
+}
+
+// GetMembers lists the members of a conversation in the order they joined
+func (r *messageRepository) GetMembers(ctx context.Context, conversationID int) ([]models.ConversationMemberWithUser, error) {
+	query := `SELECT cm.conversation_id, cm.user_id, cm.role, cm.last_read_message_id, cm.joined_at, ` + userColumnsOf("u") + `
+              FROM conversation_members cm
+              JOIN users u ON u.id = cm.user_id
+              WHERE cm.conversation_id = $1
+              ORDER BY cm.joined_at, cm.user_id`
+	rows, err := r.db.Query(ctx, query, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation members: %w", err)
+	}
+	defer rows.Close()
+
+	var members []models.ConversationMemberWithUser
+	for rows.Next() {
+		var member models.ConversationMemberWithUser
+		targets := append([]any{&member.ConversationID, &member.UserID, &member.Role, &member.LastReadMessageID, &member.JoinedAt},
+			userScanTargets(&member.User)...)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan conversation member row: %w", err)
+		}
+		members = append(members, member)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during conversation member rows iteration: %w", err)
+	}
+	return members, nil
+}
+
+// GetMemberIDs returns the ids of the members of a conversation
+func (r *messageRepository) GetMemberIDs(ctx context.Context, conversationID int) ([]int, error) {
+	rows, err := r.db.Query(ctx, `SELECT user_id FROM conversation_members WHERE conversation_id = $1`, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query conversation members: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
+
+// AddMembers adds users to a group, recording each one added. Users already
+// in the group are skipped. New members start with everything sent so far
+// marked as read.
+func (r *messageRepository) AddMembers(ctx context.Context, conversationID, actorID int, userIDs []int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `INSERT INTO conversation_members (conversation_id, user_id, role, last_read_message_id)
+              SELECT id, $2, 'member', COALESCE(last_message_id, 0) FROM conversations WHERE id = $1
+              ON CONFLICT DO NOTHING`
+	for _, userID := range userIDs {
+		tag, err := tx.Exec(ctx, query, conversationID, userID)
+		if err != nil {
+			return fmt.Errorf("failed to add group member: %w", err)
+		}
+		if tag.RowsAffected() == 0 {
+			continue
+		}
+		if err := insertMessage(ctx, tx, systemMessage(conversationID, actorID, models.EventMemberAdded, &userID)); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// RemoveMember takes userID out of a group and records event. When the owner
+// leaves, successorID becomes the new owner. A group left without members is
+// deleted.
+func (r *messageRepository) RemoveMember(ctx context.Context, conversationID, actorID, userID int, event models.SystemEvent,
+	successorID *int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	tag, err := tx.Exec(ctx, `DELETE FROM conversation_members WHERE conversation_id = $1 AND user_id = $2`, conversationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	var remaining int
+	if err := tx.QueryRow(ctx, `SELECT COUNT(*) FROM conversation_members WHERE conversation_id = $1`, conversationID).
+		Scan(&remaining); err != nil {
+		return fmt.Errorf("failed to count group members: %w", err)
+	}
+	if remaining == 0 {
+		if _, err := tx.Exec(ctx, `DELETE FROM conversations WHERE id = $1`, conversationID); err != nil {
+			return fmt.Errorf("failed to delete empty group: %w", err)
+		}
+		return tx.Commit(ctx)
+	}
+
+	if err := insertMessage(ctx, tx, systemMessage(conversationID, actorID, event, &userID)); err != nil {
+		return err
+	}
+	if successorID != nil {
+		if err := transferOwnership(ctx, tx, conversationID, userID, *successorID); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// SetRole changes the role of a member and records event
+func (r *messageRepository) SetRole(ctx context.Context, conversationID, actorID, userID int, role models.MemberRole,
+	event models.SystemEvent) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `UPDATE conversation_members SET role = $1 WHERE conversation_id = $2 AND user_id = $3`
+	tag, err := tx.Exec(ctx, query, role, conversationID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	if err := insertMessage(ctx, tx, systemMessage(conversationID, actorID, event, &userID)); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// TransferOwnership makes newOwnerID the owner of a group, leaving the
+// previous owner as an admin
+func (r *messageRepository) TransferOwnership(ctx context.Context, conversationID, ownerID, newOwnerID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if err := transferOwnership(ctx, tx, conversationID, ownerID, newOwnerID); err != nil {
+		return err
+	}
+	return tx.Commit(ctx)
+}
+
+// Rename sets the title of a group and records the new one
+func (r *messageRepository) Rename(ctx context.Context, conversationID, actorID int, title string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err := tx.Exec(ctx, `UPDATE conversations SET title = $1 WHERE id = $2 AND kind = 'group'`, title, conversationID); err != nil {
+		return fmt.Errorf("failed to rename group: %w", err)
+	}
+	renamed := systemMessage(conversationID, actorID, models.EventGroupRenamed, nil)
+	renamed.Text = title
+	if err := insertMessage(ctx, tx, renamed); err != nil {
+		return err
+	}
+
+	return tx.Commit(ctx)
+}
+
+// transferOwnership hands a group over from ownerID, who stays an admin if
+// they are still a member, to newOwnerID
+func transferOwnership(ctx context.Context, tx pgx.Tx, conversationID, ownerID, newOwnerID int) error {
+	if _, err := tx.Exec(ctx, `UPDATE conversations SET owner_id = $1 WHERE id = $2`, newOwnerID, conversationID); err != nil {
+		return fmt.Errorf("failed to update group owner: %w", err)
+	}
+	query := `UPDATE conversation_members
+              SET role = CASE WHEN user_id = $2 THEN 'owner' ELSE 'admin' END
+              WHERE conversation_id = $1 AND user_id IN ($2, $3)`
+	if _, err := tx.Exec(ctx, query, conversationID, newOwnerID, ownerID); err != nil {
+		return fmt.Errorf("failed to update member roles: %w", err)
+	}
+	return insertMessage(ctx, tx, systemMessage(conversationID, ownerID, models.EventOwnerChanged, &newOwnerID))
+}
+
+// systemMessage builds the record of a change actorID made to targetID
+func systemMessage(conversationID, actorID int, event models.SystemEvent, targetID *int) *models.Message {
+	return &models.Message{
+		ConversationID: conversationID,
+		SenderID:       actorID,
+		Kind:           models.MessageSystem,
+		Event:          &event,
+		TargetUserID:   targetID,
+	}
+}
+
+// insertMessage stores a message, moves the conversation to the top of the
+// inboxes and marks it read for the sender
+func insertMessage(ctx context.Context, tx pgx.Tx, message *models.Message) error {
+	message.CreatedAt = time.Now()
+	query := `INSERT INTO messages (conversation_id, sender_id, kind, event, target_user_id, text, created_at)
+              VALUES ($1, $2, $3, $4, $5, $6, $7) RETURNING id`
+	if err := tx.QueryRow(ctx, query, message.ConversationID, message.SenderID, message.Kind, message.Event,
+		message.TargetUserID, message.Text, message.CreatedAt).Scan(&message.ID); err != nil {
+		return fmt.Errorf("failed to insert message: %w", err)
+	}
+
+	conversationQuery := `UPDATE conversations SET last_message_id = $1, last_activity_at = $2 WHERE id = $3`
+	if _, err := tx.Exec(ctx, conversationQuery, message.ID, message.CreatedAt, message.ConversationID); err != nil {
+		return fmt.Errorf("failed to update conversation: %w", err)
+	}
+	readQuery := `UPDATE conversation_members SET last_read_message_id = $1 WHERE conversation_id = $2 AND user_id = $3`
+	if _, err := tx.Exec(ctx, readQuery, message.ID, message.ConversationID, message.SenderID); err != nil {
+		return fmt.Errorf("failed to update read position: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,107 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type NotificationRepository interface {
+	GetByUser(ctx context.Context, userID, limit, offset int) ([]models.NotificationWithActor, int, error)
+	CountUnread(ctx context.Context, userID int) (int, error)
+	MarkRead(ctx context.Context, userID, id int) error
+	MarkAllRead(ctx context.Context, userID int) error
+}
+
+type notificationRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewNotificationRepository(db *pgxpool.Pool) NotificationRepository {
+	return &notificationRepository{db: db}
+}
+
+// notificationVisibleSQL hides notifications from users with a block in
+// either direction, about posts or comments that were deleted since and about
+// posts the recipient can no longer see, such as after unfollowing a private
+// account. userParam is the placeholder holding the recipient id.
+func notificationVisibleSQL(userParam string) string {
+	return notBlockedSQL(userParam, "n.actor_id") + `
+           AND (n.post_id IS NULL OR EXISTS (SELECT 1 FROM posts np JOIN users npu ON npu.id = np.user_id
+                                             WHERE np.id = n.post_id AND np.deleted = FALSE
+                                               AND ` + visibleToSQL(userParam, "npu.id", "npu.is_private") + `))
+           AND (n.comment_id IS NULL OR EXISTS (SELECT 1 FROM comments nc WHERE nc.id = n.comment_id AND nc.deleted = FALSE))`
+}
+
+// GetByUser lists the notifications of userID newest first, with the user
+// who caused each of them
+func (r *notificationRepository) GetByUser(ctx context.Context, userID, limit, offset int) ([]models.NotificationWithActor, int, error) {
+	where := `WHERE n.user_id = $1 AND ` + notificationVisibleSQL("$1")
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) FROM notifications n `+where, userID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count notifications: %w", err)
+	}
+	if total == 0 {
+		return []models.NotificationWithActor{}, 0, nil
+	}
+
+	query := `SELECT n.id, n.user_id, n.actor_id, n.type, n.post_id, n.comment_id, n.read_at, n.created_at, ` + userColumnsOf("u") + `
+              FROM notifications n
+              JOIN users u ON u.id = n.actor_id ` + where + `
+              ORDER BY n.created_at DESC, n.id DESC
+              LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, query, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query notifications: %w", err)
+	}
+	defer rows.Close()
+
+	notifications := make([]models.NotificationWithActor, 0, limit)
+	for rows.Next() {
+		var n models.NotificationWithActor
+		targets := append([]any{&n.ID, &n.UserID, &n.ActorID, &n.Type, &n.PostID, &n.CommentID, &n.ReadAt, &n.CreatedAt},
+			userScanTargets(&n.Actor)...)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan notification row: %w", err)
+		}
+		notifications = append(notifications, n)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error during notification rows iteration: %w", err)
+	}
+	return notifications, total, nil
+}
+
+func (r *notificationRepository) CountUnread(ctx context.Context, userID int) (int, error) {
+	query := `SELECT COUNT(*) FROM notifications n
+              WHERE n.user_id = $1 AND n.read_at IS NULL AND ` + notificationVisibleSQL("$1")
+	var count int
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&count); err != nil {
+		return 0, fmt.Errorf("failed to count unread notifications: %w", err)
+	}
+	return count, nil
+}
+
+// MarkRead marks one notification of userID as read. Reading it twice is
+// not an error.
+func (r *notificationRepository) MarkRead(ctx context.Context, userID, id int) error {
+	query := `UPDATE notifications SET read_at = COALESCE(read_at, $1) WHERE id = $2 AND user_id = $3`
+	tag, err := r.db.Exec(ctx, query, time.Now(), id, userID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *notificationRepository) MarkAllRead(ctx context.Context, userID int) error {
+	query := `UPDATE notifications SET read_at = $1 WHERE user_id = $2 AND read_at IS NULL`
+	_, err := r.db.Exec(ctx, query, time.Now(), userID)
+	return err
+}
@@ -0,0 +1,496 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// postColumnsOf is the column list shared by every query that loads a post
+// and must match the order expected by postScanTargets
+func postColumnsOf(alias string) string {
+	return strings.NewReplacer("p.", alias+".").Replace(
+		`p.id, p.user_id, COALESCE(p.image_url, ''), COALESCE(p.description, ''), p.like_count, p.comment_count,
+		 p.nsfw, p.created_at, p.updated_at, p.deleted, p.deleted_at`)
+}
+
+// postScanTargets returns the scan destinations matching postColumnsOf
+func postScanTargets(post *models.Post) []any {
+	return []any{&post.ID, &post.UserID, &post.ImageURL, &post.Description, &post.LikeCount, &post.CommentCount,
+		&post.NSFW, &post.CreatedAt, &post.UpdatedAt, &post.Deleted, &post.DeletedAt}
+}
+
+type PostRepository interface {
+	Create(ctx context.Context, post *models.Post, media []models.PostMedia, tags []string, mentions []models.Mention) ([]int, error)
+	GetByID(ctx context.Context, id int) (*models.Post, error)
+	GetVisibleByID(ctx context.Context, viewerID, id int) (*models.PostWithAuthor, error)
+	GetByUser(ctx context.Context, viewerID, userID, limit, offset int) ([]models.PostWithAuthor, int, error)
+	GetVideos(ctx context.Context, viewerID, limit, offset int) ([]models.PostWithAuthor, int, error)
+	GetFeed(ctx context.Context, viewerID int, after *models.PostCursor, limit int) ([]models.PostWithAuthor, error)
+	GetByHashtag(ctx context.Context, viewerID, tagID, limit, offset int) ([]models.PostWithAuthor, int, error)
+	Search(ctx context.Context, viewerID int, filter models.SearchFilter, limit, offset int) ([]models.PostSearchHit, int, error)
+	Update(ctx context.Context, post *models.Post, tags []string, mentions []models.Mention) ([]int, error)
+	SoftDelete(ctx context.Context, id int) error
+	GetMedia(ctx context.Context, postIDs []int) (map[int][]models.PostMedia, error)
+	UpdateMediaAltText(ctx context.Context, postID, mediaID int, altText string) error
+}
+
+type postRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewPostRepository(db *pgxpool.Pool) PostRepository {
+	return &postRepository{db: db}
+}
+
+// Create inserts the post, its media items, hashtags and mentions in one
+// transaction. Media positions follow the order of the slice. It returns the
+// users notified of a new mention.
+func (r *postRepository) Create(ctx context.Context, post *models.Post, media []models.PostMedia, tags []string, mentions []models.Mention) ([]int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	now := time.Now()
+	post.CreatedAt = now
+	post.UpdatedAt = now
+	query := `INSERT INTO posts (user_id, image_url, description, nsfw, created_at, updated_at, deleted)
+              VALUES ($1, NULLIF($2, ''), $3, $4, $5, $6, FALSE) RETURNING id`
+	if err := tx.QueryRow(ctx, query, post.UserID, post.ImageURL, post.Description, post.NSFW, post.CreatedAt, post.UpdatedAt).
+		Scan(&post.ID); err != nil {
+		return nil, fmt.Errorf("failed to insert post: %w", err)
+	}
+
+	mediaQuery := `INSERT INTO post_media (post_id, position, media_type, storage_key, content_type, width, height, alt_text,
+                                          poster_key, duration_ms, created_at)
+                   VALUES ($1, $2, $3, $4, $5, $6, $7, $8, NULLIF($9, ''), NULLIF($10, 0), $11) RETURNING id`
+	for i := range media {
+		item := &media[i]
+		item.PostID = post.ID
+		item.Position = i
+		item.CreatedAt = now
+		if err := tx.QueryRow(ctx, mediaQuery, item.PostID, item.Position, item.MediaType, item.StorageKey,
+			item.ContentType, item.Width, item.Height, item.AltText, item.PosterKey, item.DurationMS, item.CreatedAt).Scan(&item.ID); err != nil {
+			return nil, fmt.Errorf("failed to insert post media: %w", err)
+		}
+	}
+
+	if err := syncPostTags(ctx, tx, post.ID, tags); err != nil {
+		return nil, err
+	}
+	notified, err := syncMentions(ctx, tx, post.ID, nil, post.UserID, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return notified, nil
+}
+
+// GetByID returns a live post without any visibility checks, for internal
+// use such as ownership checks
+func (r *postRepository) GetByID(ctx context.Context, id int) (*models.Post, error) {
+	query := `SELECT ` + postColumnsOf("p") + ` FROM posts p WHERE p.id = $1 AND p.deleted = FALSE`
+	post := &models.Post{}
+	if err := r.db.QueryRow(ctx, query, id).Scan(postScanTargets(post)...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return post, nil
+}
+
+// GetVisibleByID returns the post with its author if the viewer is allowed to
+// see it: not deleted, no block between them and, for private accounts, the
+// viewer follows the author
+func (r *postRepository) GetVisibleByID(ctx context.Context, viewerID, id int) (*models.PostWithAuthor, error) {
+	query := `SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `
+              FROM posts p
+              JOIN users u ON u.id = p.user_id
+              WHERE p.id = $1 AND p.deleted = FALSE AND ` + visibleToSQL("$2", "u.id", "u.is_private")
+	post := &models.PostWithAuthor{}
+	targets := append(postScanTargets(&post.Post), userScanTargets(&post.Author)...)
+	if err := r.db.QueryRow(ctx, query, id, viewerID).Scan(targets...); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return post, nil
+}
+
+// GetByUser lists the live posts of userID newest first. The list is empty
+// when the viewer is not allowed to see the author.
+func (r *postRepository) GetByUser(ctx context.Context, viewerID, userID, limit, offset int) ([]models.PostWithAuthor, int, error) {
+	visibility := visibleToSQL("$2", "u.id", "u.is_private")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM posts p
+                   JOIN users u ON u.id = p.user_id
+                   WHERE p.user_id = $1 AND p.deleted = FALSE AND ` + visibility
+	if err := r.db.QueryRow(ctx, countQuery, userID, viewerID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count posts: %w", err)
+	}
+	if total == 0 {
+		return []models.PostWithAuthor{}, 0, nil
+	}
+
+	query := `SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `
+              FROM posts p
+              JOIN users u ON u.id = p.user_id
+              WHERE p.user_id = $1 AND p.deleted = FALSE AND ` + visibility + `
+              ORDER BY p.created_at DESC, p.id DESC
+              LIMIT $3 OFFSET $4`
+	rows, err := r.db.Query(ctx, query, userID, viewerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts, err := collectPostsWithAuthor(rows, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return posts, total, nil
+}
+
+// GetByHashtag lists the live posts tagged with tagID that the viewer can
+// see, newest first. Muted authors are left out.
+func (r *postRepository) GetByHashtag(ctx context.Context, viewerID, tagID, limit, offset int) ([]models.PostWithAuthor, int, error) {
+	from := `FROM post_tags pt
+             JOIN posts p ON p.id = pt.post_id
+             JOIN users u ON u.id = p.user_id
+             WHERE pt.tag_id = $1 AND p.deleted = FALSE
+               AND ` + visibleToSQL("$2", "u.id", "u.is_private") + `
+               AND ` + notMutedSQL("$2", "u.id")
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) `+from, tagID, viewerID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count hashtag posts: %w", err)
+	}
+	if total == 0 {
+		return []models.PostWithAuthor{}, 0, nil
+	}
+
+	query := `SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + ` ` + from + `
+              ORDER BY p.created_at DESC, p.id DESC
+              LIMIT $3 OFFSET $4`
+	rows, err := r.db.Query(ctx, query, tagID, viewerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query hashtag posts: %w", err)
+	}
+	defer rows.Close()
+
+	posts, err := collectPostsWithAuthor(rows, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return posts, total, nil
+}
+
+// Search pages through the live posts matching the filter that the viewer
+// can see, best matches first and newest first among equal ones. Muted
+// authors are left out.
+func (r *postRepository) Search(ctx context.Context, viewerID int, filter models.SearchFilter,
+	limit, offset int) ([]models.PostSearchHit, int, error) {
+	from := `FROM posts p
+             JOIN users u ON u.id = p.user_id
+             CROSS JOIN (SELECT ` + searchQuerySQL("$2", "$3", "$4") + ` AS query) q
+             WHERE p.deleted = FALSE
+               ` + searchMatchSQL(filter.Text, "p.search_vector") + `
+               AND ($5 = '' OR EXISTS (SELECT 1 FROM post_tags pt JOIN hashtags h ON h.id = pt.tag_id
+                                       WHERE pt.post_id = p.id AND h.name = $5))
+               AND ($6 = '' OR EXISTS (SELECT 1 FROM mentions m JOIN users mu ON mu.id = m.mentioned_user_id
+                                       WHERE m.post_id = p.id AND m.comment_id IS NULL AND mu.username = $6))
+               AND ($7::timestamp IS NULL OR p.created_at >= $7)
+               AND ($8::timestamp IS NULL OR p.created_at < $8)
+               AND ` + visibleToSQL("$1", "u.id", "u.is_private") + `
+               AND ` + notMutedSQL("$1", "u.id")
+	args := searchArgs(viewerID, filter)
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) `+from, args...).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count matching posts: %w", err)
+	}
+	if total == 0 {
+		return []models.PostSearchHit{}, 0, nil
+	}
+
+	query := `SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `,
+                     ts_headline($3::regconfig, COALESCE(p.description, ''), q.query, $9),
+                     ts_rank_cd(p.search_vector, q.query)::float8 AS rank
+              ` + from + `
+              ORDER BY rank DESC, p.created_at DESC, p.id DESC
+              LIMIT $10 OFFSET $11`
+	rows, err := r.db.Query(ctx, query, append(args, headlineOptions, limit, offset)...)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to search posts: %w", err)
+	}
+	defer rows.Close()
+
+	hits := make([]models.PostSearchHit, 0, limit)
+	for rows.Next() {
+		var hit models.PostSearchHit
+		targets := append(postScanTargets(&hit.Post), userScanTargets(&hit.Author)...)
+		targets = append(targets, &hit.Snippet, &hit.Rank)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan post search row: %w", err)
+		}
+		hits = append(hits, hit)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error during post search rows iteration: %w", err)
+	}
+	return hits, total, nil
+}
+
+// GetVideos lists the live video posts visible to the viewer newest first.
+// Muted authors are left out since this is a feed.
+func (r *postRepository) GetVideos(ctx context.Context, viewerID, limit, offset int) ([]models.PostWithAuthor, int, error) {
+	where := `WHERE p.deleted = FALSE
+                AND EXISTS (SELECT 1 FROM post_media pm WHERE pm.post_id = p.id AND pm.media_type = 'video')
+                AND ` + visibleToSQL("$1", "u.id", "u.is_private") + `
+                AND ` + notMutedSQL("$1", "u.id")
+
+	var total int
+	countQuery := `SELECT COUNT(*) FROM posts p JOIN users u ON u.id = p.user_id ` + where
+	if err := r.db.QueryRow(ctx, countQuery, viewerID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count videos: %w", err)
+	}
+	if total == 0 {
+		return []models.PostWithAuthor{}, 0, nil
+	}
+
+	query := `SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `
+              FROM posts p
+              JOIN users u ON u.id = p.user_id ` + where + `
+              ORDER BY p.created_at DESC, p.id DESC
+              LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, query, viewerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query videos: %w", err)
+	}
+	defer rows.Close()
+
+	posts, err := collectPostsWithAuthor(rows, limit)
+	if err != nil {
+		return nil, 0, err
+	}
+	return posts, total, nil
+}
+
+// GetFeed lists the live posts of the accounts the viewer follows and their
+// own, newest first, after the cursor. Rather than merging every post of
+// every followed account, the lateral join takes at most limit posts per
+// account from idx_posts_user_created, so the cost grows with the number of
+// accounts and the page size but not with how much they posted. Muted and
+// blocked accounts are left out.
+func (r *postRepository) GetFeed(ctx context.Context, viewerID int, after *models.PostCursor, limit int) ([]models.PostWithAuthor, error) {
+	if after == nil {
+		after = &models.PostCursor{}
+	}
+
+	query := `WITH authors AS (
+                  SELECT followed_id AS user_id FROM followers WHERE follower_id = $1
+                  UNION
+                  SELECT $1
+              )
+              SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `
+              FROM authors a
+              CROSS JOIN LATERAL (
+                  SELECT * FROM posts ap
+                  WHERE ap.user_id = a.user_id AND ap.deleted = FALSE
+                    AND ($3::int = 0 OR (ap.created_at, ap.id) < ($2, $3))
+                  ORDER BY ap.created_at DESC, ap.id DESC
+                  LIMIT $4
+              ) p
+              JOIN users u ON u.id = p.user_id
+              WHERE ` + notBlockedSQL("$1", "u.id") + `
+                AND ` + notMutedSQL("$1", "u.id") + `
+              ORDER BY p.created_at DESC, p.id DESC
+              LIMIT $4`
+	rows, err := r.db.Query(ctx, query, viewerID, after.CreatedAt, after.ID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query feed: %w", err)
+	}
+	defer rows.Close()
+
+	return collectPostsWithAuthor(rows, limit)
+}
+
+// Update saves the edited fields and replaces the hashtags and mentions of
+// the post. It returns the users notified of a new mention.
+func (r *postRepository) Update(ctx context.Context, post *models.Post, tags []string, mentions []models.Mention) ([]int, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `UPDATE posts SET image_url = NULLIF($1, ''), description = $2, nsfw = $3, updated_at = $4
+              WHERE id = $5 AND deleted = FALSE`
+	tag, err := tx.Exec(ctx, query, post.ImageURL, post.Description, post.NSFW, post.UpdatedAt, post.ID)
+	if err != nil {
+		return nil, err
+	}
+	if tag.RowsAffected() == 0 {
+		return nil, ErrNotFound
+	}
+
+	if err := syncPostTags(ctx, tx, post.ID, tags); err != nil {
+		return nil, err
+	}
+	notified, err := syncMentions(ctx, tx, post.ID, nil, post.UserID, mentions)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := tx.Commit(ctx); err != nil {
+		return nil, err
+	}
+	return notified, nil
+}
+
+// SoftDelete flags the post as deleted, keeping the row so comments, likes
+// and moderation history still point somewhere. Its hashtags stop counting
+// it.
+func (r *postRepository) SoftDelete(ctx context.Context, id int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	now := time.Now()
+	query := `UPDATE posts SET deleted = TRUE, deleted_at = $1, updated_at = $1 WHERE id = $2 AND deleted = FALSE`
+	tag, err := tx.Exec(ctx, query, now, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+
+	countQuery := `UPDATE hashtags SET post_count = post_count - 1
+                   WHERE id IN (SELECT tag_id FROM post_tags WHERE post_id = $1)`
+	if _, err := tx.Exec(ctx, countQuery, id); err != nil {
+		return fmt.Errorf("failed to update hashtag counts: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// syncPostTags makes tags the exact set of hashtags of the post, creating
+// missing hashtags and keeping their post counts in step
+func syncPostTags(ctx context.Context, tx pgx.Tx, postID int, tags []string) error {
+	if tags == nil {
+		tags = []string{}
+	}
+
+	upsertQuery := `INSERT INTO hashtags (name, created_at, deleted)
+                    SELECT name, now(), FALSE FROM unnest($1::text[]) AS name
+                    ORDER BY name
+                    ON CONFLICT (name) DO NOTHING`
+	if _, err := tx.Exec(ctx, upsertQuery, tags); err != nil {
+		return fmt.Errorf("failed to create hashtags: %w", err)
+	}
+
+	removeQuery := `WITH removed AS (
+                        DELETE FROM post_tags pt
+                        USING hashtags h
+                        WHERE pt.post_id = $1 AND h.id = pt.tag_id AND h.name <> ALL($2::text[])
+                        RETURNING pt.tag_id
+                    )
+                    UPDATE hashtags SET post_count = post_count - 1
+                    WHERE id IN (SELECT tag_id FROM removed)`
+	if _, err := tx.Exec(ctx, removeQuery, postID, tags); err != nil {
+		return fmt.Errorf("failed to remove post hashtags: %w", err)
+	}
+
+	addQuery := `WITH added AS (
+                     INSERT INTO post_tags (post_id, tag_id, created_at)
+                     SELECT $1, h.id, now() FROM hashtags h WHERE h.name = ANY($2::text[])
+                     ON CONFLICT (post_id, tag_id) DO NOTHING
+                     RETURNING tag_id
+                 )
+                 UPDATE hashtags SET post_count = post_count + 1
+                 WHERE id IN (SELECT tag_id FROM added)`
+	if _, err := tx.Exec(ctx, addQuery, postID, tags); err != nil {
+		return fmt.Errorf("failed to add post hashtags: %w", err)
+	}
+	return nil
+}
+
+// GetMedia returns the media of every post in postIDs, keyed by post id and
+// ordered by position
+func (r *postRepository) GetMedia(ctx context.Context, postIDs []int) (map[int][]models.PostMedia, error) {
+	media := make(map[int][]models.PostMedia, len(postIDs))
+	if len(postIDs) == 0 {
+		return media, nil
+	}
+
+	query := `SELECT id, post_id, position, media_type, storage_key, content_type, width, height, alt_text,
+                     COALESCE(poster_key, ''), COALESCE(duration_ms, 0), created_at
+              FROM post_media
+              WHERE post_id = ANY($1)
+              ORDER BY post_id, position`
+	rows, err := r.db.Query(ctx, query, postIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query post media: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var item models.PostMedia
+		if err := rows.Scan(&item.ID, &item.PostID, &item.Position, &item.MediaType, &item.StorageKey,
+			&item.ContentType, &item.Width, &item.Height, &item.AltText, &item.PosterKey, &item.DurationMS, &item.CreatedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan post media row: %w", err)
+		}
+		media[item.PostID] = append(media[item.PostID], item)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during post media rows iteration: %w", err)
+	}
+	return media, nil
+}
+
+func (r *postRepository) UpdateMediaAltText(ctx context.Context, postID, mediaID int, altText string) error {
+	query := `UPDATE post_media SET alt_text = $1 WHERE id = $2 AND post_id = $3`
+	tag, err := r.db.Exec(ctx, query, altText, mediaID, postID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// collectPostsWithAuthor scans rows selected with postColumnsOf followed by
+// userColumnsOf
+func collectPostsWithAuthor(rows pgx.Rows, capacity int) ([]models.PostWithAuthor, error) {
+	posts := make([]models.PostWithAuthor, 0, capacity)
+	for rows.Next() {
+		var post models.PostWithAuthor
+		targets := append(postScanTargets(&post.Post), userScanTargets(&post.Author)...)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan post row: %w", err)
+		}
+		posts = append(posts, post)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during post rows iteration: %w", err)
+	}
+	return posts, nil
+}
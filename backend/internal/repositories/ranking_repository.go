@@ -0,0 +1,106 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// interestTags is how many of the hashtags the viewer engages with most are
+// used to find posts from accounts they do not follow
+const interestTags = 10
+
+type RankingRepository interface {
+	GetCandidates(ctx context.Context, viewerID int, since, asOf time.Time, limit int) ([]models.RankingCandidate, error)
+}
+
+type rankingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewRankingRepository(db *pgxpool.Pool) RankingRepository {
+	return &rankingRepository{db: db}
+}
+
+// GetCandidates gathers the posts made between since and asOf by the viewer,
+// by the accounts they follow and, up to limit, by anyone under the hashtags
+// the viewer interacted with most, together with the signals the ranked feed
+// scores them on. Interactions are counted from since as well. Only posts the
+// viewer can see are returned, and muted accounts are left out.
+func (r *rankingRepository) GetCandidates(ctx context.Context, viewerID int, since, asOf time.Time,
+	limit int) ([]models.RankingCandidate, error) {
+	query := `WITH interactions AS (
+                  SELECT post_id FROM likes WHERE user_id = $1 AND created_at >= $2
+                  UNION ALL
+                  SELECT post_id FROM comments WHERE user_id = $1 AND created_at >= $2 AND deleted = FALSE
+                  UNION ALL
+                  SELECT post_id FROM reactions WHERE user_id = $1 AND created_at >= $2 AND post_id IS NOT NULL
+                  UNION ALL
+                  SELECT post_id FROM bookmarks WHERE user_id = $1 AND created_at >= $2
+              ), author_affinity AS (
+                  SELECT ip.user_id, COUNT(*) AS n
+                  FROM interactions i JOIN posts ip ON ip.id = i.post_id
+                  WHERE ip.user_id <> $1
+                  GROUP BY ip.user_id
+              ), tag_interest AS (
+                  SELECT pt.tag_id, COUNT(*) AS n
+                  FROM interactions i JOIN post_tags pt ON pt.post_id = i.post_id
+                  GROUP BY pt.tag_id
+              ), candidates AS (
+                  (SELECT cp.id FROM posts cp
+                   WHERE cp.user_id = $1 AND cp.deleted = FALSE AND cp.created_at BETWEEN $2 AND $3)
+                  UNION
+                  (SELECT cp.id FROM followers f
+                   CROSS JOIN LATERAL (
+                       SELECT id FROM posts fp
+                       WHERE fp.user_id = f.followed_id AND fp.deleted = FALSE AND fp.created_at BETWEEN $2 AND $3
+                       ORDER BY fp.created_at DESC, fp.id DESC
+                       LIMIT $4
+                   ) cp
+                   WHERE f.follower_id = $1)
+                  UNION
+                  (SELECT DISTINCT pt.post_id FROM (
+                       SELECT tag_id FROM tag_interest ORDER BY n DESC, tag_id LIMIT ` + fmt.Sprint(interestTags) + `
+                   ) ti
+                   JOIN post_tags pt ON pt.tag_id = ti.tag_id
+                   JOIN posts tp ON tp.id = pt.post_id
+                   WHERE tp.deleted = FALSE AND tp.created_at BETWEEN $2 AND $3
+                   LIMIT $4)
+              )
+              SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `,
+                     (SELECT COUNT(*) FROM bookmarks b WHERE b.post_id = p.id),
+                     COALESCE(aa.n, 0),
+                     COALESCE((SELECT SUM(ti.n) FROM post_tags pt JOIN tag_interest ti ON ti.tag_id = pt.tag_id
+                               WHERE pt.post_id = p.id), 0)::int
+              FROM candidates c
+              JOIN posts p ON p.id = c.id
+              JOIN users u ON u.id = p.user_id
+              LEFT JOIN author_affinity aa ON aa.user_id = p.user_id
+              WHERE ` + visibleToSQL("$1", "u.id", "u.is_private") + `
+                AND ` + notMutedSQL("$1", "u.id") + `
+              ORDER BY p.created_at DESC, p.id DESC
+              LIMIT $4`
+	rows, err := r.db.Query(ctx, query, viewerID, since, asOf, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ranking candidates: %w", err)
+	}
+	defer rows.Close()
+
+	candidates := make([]models.RankingCandidate, 0, limit)
+	for rows.Next() {
+		var candidate models.RankingCandidate
+		targets := append(postScanTargets(&candidate.Post), userScanTargets(&candidate.Author)...)
+		targets = append(targets, &candidate.SaveCount, &candidate.AuthorInteractions, &candidate.HashtagInteractions)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan ranking candidate row: %w", err)
+		}
+		candidates = append(candidates, candidate)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during ranking candidate rows iteration: %w", err)
+	}
+	return candidates, nil
+}
@@ -0,0 +1,204 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+type ReactionRepository interface {
+	Set(ctx context.Context, userID int, target models.ReactionTarget, targetID int, emoji string) error
+	Remove(ctx context.Context, userID int, target models.ReactionTarget, targetID int) error
+	GetCounts(ctx context.Context, target models.ReactionTarget, targetIDs []int) (map[int][]models.ReactionCount, error)
+	GetUserReactions(ctx context.Context, userID int, target models.ReactionTarget, targetIDs []int) (map[int]string, error)
+	GetByTarget(ctx context.Context, viewerID int, target models.ReactionTarget, targetID int, emoji string,
+		limit, offset int) ([]models.ReactionWithUser, int, error)
+}
+
+type reactionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewReactionRepository(db *pgxpool.Pool) ReactionRepository {
+	return &reactionRepository{db: db}
+}
+
+// reactionColumn is the reactions column referencing items of target
+func reactionColumn(target models.ReactionTarget) string {
+	if target == models.ReactionOnComment {
+		return "comment_id"
+	}
+	return "post_id"
+}
+
+// Set reacts to the item with emoji, replacing the previous reaction of the
+// user. Reacting again with the same emoji keeps the original time.
+func (r *reactionRepository) Set(ctx context.Context, userID int, target models.ReactionTarget, targetID int, emoji string) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	// xmax is 0 for a freshly inserted row and set when the conflict updated
+	// an existing one. Nothing is returned when the emoji did not change.
+	column := reactionColumn(target)
+	query := `INSERT INTO reactions (user_id, ` + column + `, emoji, created_at) VALUES ($1, $2, $3, $4)
+              ON CONFLICT (user_id, ` + column + `) WHERE ` + column + ` IS NOT NULL
+              DO UPDATE SET emoji = EXCLUDED.emoji, created_at = EXCLUDED.created_at
+              WHERE reactions.emoji <> EXCLUDED.emoji
+              RETURNING xmax = 0`
+	var inserted bool
+	err = tx.QueryRow(ctx, query, userID, targetID, emoji, time.Now()).Scan(&inserted)
+	if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+		return err
+	}
+	if inserted {
+		if err := updateReactionCount(ctx, tx, target, targetID, 1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// Remove deletes the reaction of the user. Removing a missing reaction is a
+// no-op.
+func (r *reactionRepository) Remove(ctx context.Context, userID int, target models.ReactionTarget, targetID int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `DELETE FROM reactions WHERE user_id = $1 AND ` + reactionColumn(target) + ` = $2`
+	tag, err := tx.Exec(ctx, query, userID, targetID)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() > 0 {
+		if err := updateReactionCount(ctx, tx, target, targetID, -1); err != nil {
+			return err
+		}
+	}
+
+	return tx.Commit(ctx)
+}
+
+// updateReactionCount keeps comments.reaction_count, used to sort comments
+// by popularity, in step. Post reactions are only counted when read.
+func updateReactionCount(ctx context.Context, tx pgx.Tx, target models.ReactionTarget, targetID, delta int) error {
+	if target != models.ReactionOnComment {
+		return nil
+	}
+	query := `UPDATE comments SET reaction_count = reaction_count + $1 WHERE id = $2`
+	if _, err := tx.Exec(ctx, query, delta, targetID); err != nil {
+		return fmt.Errorf("failed to update reaction count: %w", err)
+	}
+	return nil
+}
+
+// GetCounts returns the number of reactions per emoji of every item in
+// targetIDs, most used emoji first
+func (r *reactionRepository) GetCounts(ctx context.Context, target models.ReactionTarget, targetIDs []int) (map[int][]models.ReactionCount, error) {
+	counts := make(map[int][]models.ReactionCount, len(targetIDs))
+	if len(targetIDs) == 0 {
+		return counts, nil
+	}
+
+	column := reactionColumn(target)
+	query := `SELECT ` + column + `, emoji, COUNT(*) FROM reactions
+              WHERE ` + column + ` = ANY($1)
+              GROUP BY ` + column + `, emoji
+              ORDER BY ` + column + `, COUNT(*) DESC, emoji`
+	rows, err := r.db.Query(ctx, query, targetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query reaction counts: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var count models.ReactionCount
+		if err := rows.Scan(&id, &count.Emoji, &count.Count); err != nil {
+			return nil, fmt.Errorf("failed to scan reaction count row: %w", err)
+		}
+		counts[id] = append(counts[id], count)
+	}
+	return counts, rows.Err()
+}
+
+// GetUserReactions returns the emoji userID reacted with on each item of
+// targetIDs it reacted to
+func (r *reactionRepository) GetUserReactions(ctx context.Context, userID int, target models.ReactionTarget, targetIDs []int) (map[int]string, error) {
+	reactions := make(map[int]string, len(targetIDs))
+	if userID == 0 || len(targetIDs) == 0 {
+		return reactions, nil
+	}
+
+	column := reactionColumn(target)
+	query := `SELECT ` + column + `, emoji FROM reactions WHERE user_id = $1 AND ` + column + ` = ANY($2)`
+	rows, err := r.db.Query(ctx, query, userID, targetIDs)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query user reactions: %w", err)
+	}
+	defer rows.Close()
+
+	for rows.Next() {
+		var id int
+		var emoji string
+		if err := rows.Scan(&id, &emoji); err != nil {
+			return nil, fmt.Errorf("failed to scan user reaction row: %w", err)
+		}
+		reactions[id] = emoji
+	}
+	return reactions, rows.Err()
+}
+
+// GetByTarget lists who reacted to the item newest first, only with emoji
+// when it is not empty. Users with a block in either direction with the
+// viewer are left out.
+func (r *reactionRepository) GetByTarget(ctx context.Context, viewerID int, target models.ReactionTarget, targetID int, emoji string,
+	limit, offset int) ([]models.ReactionWithUser, int, error) {
+	from := `FROM reactions re
+             JOIN users u ON u.id = re.user_id
+             WHERE re.` + reactionColumn(target) + ` = $1 AND ($2 = '' OR re.emoji = $2)
+               AND ` + notBlockedSQL("$3", "u.id")
+
+	var total int
+	if err := r.db.QueryRow(ctx, `SELECT COUNT(*) `+from, targetID, emoji, viewerID).Scan(&total); err != nil {
+		return nil, 0, fmt.Errorf("failed to count reactions: %w", err)
+	}
+	if total == 0 {
+		return []models.ReactionWithUser{}, 0, nil
+	}
+
+	query := `SELECT re.id, re.user_id, re.post_id, re.comment_id, re.emoji, re.created_at, ` + userColumnsOf("u") + ` ` + from + `
+              ORDER BY re.created_at DESC, re.id DESC
+              LIMIT $4 OFFSET $5`
+	rows, err := r.db.Query(ctx, query, targetID, emoji, viewerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("failed to query reactions: %w", err)
+	}
+	defer rows.Close()
+
+	reactions := make([]models.ReactionWithUser, 0, limit)
+	for rows.Next() {
+		var reaction models.ReactionWithUser
+		targets := append([]any{&reaction.ID, &reaction.UserID, &reaction.PostID, &reaction.CommentID, &reaction.Emoji,
+			&reaction.CreatedAt}, userScanTargets(&reaction.User)...)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, 0, fmt.Errorf("failed to scan reaction row: %w", err)
+		}
+		reactions = append(reactions, reaction)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, 0, fmt.Errorf("error during reaction rows iteration: %w", err)
+	}
+	return reactions, total, nil
+}
@@ -0,0 +1,50 @@
+package repositories
+
+import (
+	"github.com/escuadron-404/red404/backend/internal/models"
+)
+
+// headlineOptions configures the snippets of search results. Matches are
+// wrapped in the snippet markers instead of HTML since the text is not
+// escaped by Postgres.
+const headlineOptions = `StartSel=` + models.SnippetMatchStart + `, StopSel=` + models.SnippetMatchEnd +
+	`, MinWords=15, MaxWords=35, MaxFragments=2, FragmentDelimiter=" … "`
+
+// searchQuerySQL parses the search text in textParam with both
+// configurations in configParams
+func searchQuerySQL(textParam, primaryConfigParam, secondaryConfigParam string) string {
+	return `websearch_to_tsquery(` + primaryConfigParam + `::regconfig, ` + textParam + `)
+            || websearch_to_tsquery(` + secondaryConfigParam + `::regconfig, ` + textParam + `)`
+}
+
+// searchMatchSQL is the condition matching vector against the parsed query
+// q.query, or nothing when there is no text to search so only the other
+// filters apply. Text made only of stop words parses to an empty query,
+// which matches no rows.
+func searchMatchSQL(text, vector string) string {
+	if text == "" {
+		return ""
+	}
+	return `AND numnode(q.query) > 0 AND ` + vector + ` @@ q.query`
+}
+
+// searchConfigs returns the text search configurations a query in language
+// is parsed with. The first one also builds the snippets.
+func searchConfigs(language models.SearchLanguage) (primary, secondary string) {
+	switch language {
+	case models.SearchLanguageEnglish:
+		return "english", "english"
+	case models.SearchLanguageSpanish:
+		return "spanish", "spanish"
+	default:
+		return "spanish", "english"
+	}
+}
+
+// searchArgs returns the first eight arguments shared by the search queries:
+// the viewer, the text, both configurations, the hashtag, the mention and the
+// date range
+func searchArgs(viewerID int, filter models.SearchFilter) []any {
+	primary, secondary := searchConfigs(filter.Language)
+	return []any{viewerID, filter.Text, primary, secondary, filter.Hashtag, filter.Mention, filter.From, filter.To}
+}
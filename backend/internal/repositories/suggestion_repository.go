@@ -0,0 +1,185 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// Scoring weights for follow suggestions. A mutual follow is the strongest
+// signal, a shared hashtag is worth a bit less and popularity only breaks ties
+// (it grows logarithmically with the follower count).
+const (
+	suggestionMutualWeight     = 3.0
+	suggestionTagWeight        = 1.5
+	suggestionPopularityWeight = 0.5
+
+	// How many of the most followed accounts are considered as candidates for
+	// users without any graph or hashtag signal yet
+	suggestionPopularPool = 200
+)
+
+type SuggestionRepository interface {
+	Compute(ctx context.Context, userID, limit int) error
+	GetForUser(ctx context.Context, userID, limit int) ([]models.Suggestion, error)
+	GetComputedAt(ctx context.Context, userID int) (time.Time, error)
+	GetStaleUserIDs(ctx context.Context, olderThan time.Time, limit int) ([]int, error)
+}
+
+type suggestionRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewSuggestionRepository(db *pgxpool.Pool) SuggestionRepository {
+	return &suggestionRepository{db: db}
+}
+
+// Compute replaces the cached suggestions of userID with the best candidates
+// from friends-of-friends, shared hashtag interests and popular accounts
+func (r *suggestionRepository) Compute(ctx context.Context, userID, limit int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err := tx.Exec(ctx, `DELETE FROM user_suggestions WHERE user_id = $1`, userID); err != nil {
+		return fmt.Errorf("failed to clear suggestions: %w", err)
+	}
+
+	query := `
+	WITH friends_of_friends AS (
+		SELECT f2.followed_id AS candidate_id, COUNT(*) AS mutual_count
+		FROM followers f1
+		JOIN followers f2 ON f2.follower_id = f1.followed_id
+		WHERE f1.follower_id = $1
+		GROUP BY f2.followed_id
+	),
+	interests AS (
+		SELECT pt.tag_id FROM post_tags pt
+		JOIN posts p ON p.id = pt.post_id
+		WHERE p.user_id = $1 AND p.deleted IS NOT TRUE
+		UNION
+		SELECT pt.tag_id FROM post_tags pt
+		JOIN likes l ON l.post_id = pt.post_id
+		WHERE l.user_id = $1
+	),
+	shared_interests AS (
+		SELECT p.user_id AS candidate_id, COUNT(DISTINCT pt.tag_id) AS shared_tags
+		FROM post_tags pt
+		JOIN posts p ON p.id = pt.post_id
+		WHERE pt.tag_id IN (SELECT tag_id FROM interests) AND p.deleted IS NOT TRUE
+		GROUP BY p.user_id
+	),
+	popular AS (
+		SELECT id AS candidate_id FROM users
+		WHERE deleted IS NOT TRUE
+		ORDER BY followers_count DESC
+		LIMIT $6
+	),
+	candidates AS (
+		SELECT candidate_id FROM friends_of_friends
+		UNION SELECT candidate_id FROM shared_interests
+		UNION SELECT candidate_id FROM popular
+	)
+	INSERT INTO user_suggestions (user_id, suggested_id, score, mutual_count, shared_tags, computed_at)
+	SELECT $1, u.id,
+	       $3::float8 * COALESCE(fof.mutual_count, 0)
+	     + $4::float8 * COALESCE(si.shared_tags, 0)
+	     + $5::float8 * ln(1 + u.followers_count),
+	       COALESCE(fof.mutual_count, 0),
+	       COALESCE(si.shared_tags, 0),
+	       $7
+	FROM candidates c
+	JOIN users u ON u.id = c.candidate_id
+	LEFT JOIN friends_of_friends fof ON fof.candidate_id = u.id
+	LEFT JOIN shared_interests si ON si.candidate_id = u.id
+	WHERE u.id <> $1
+	  AND u.deleted IS NOT TRUE
+	  AND NOT EXISTS (SELECT 1 FROM followers f WHERE f.follower_id = $1 AND f.followed_id = u.id)
+	  AND ` + notBlockedSQL("$1", "u.id") + `
+	ORDER BY 3 DESC, u.id
+	LIMIT $2`
+
+	now := time.Now()
+	if _, err := tx.Exec(ctx, query, userID, limit,
+		suggestionMutualWeight, suggestionTagWeight, suggestionPopularityWeight, suggestionPopularPool, now); err != nil {
+		return fmt.Errorf("failed to compute suggestions: %w", err)
+	}
+
+	stateQuery := `INSERT INTO user_suggestions_state (user_id, computed_at) VALUES ($1, $2)
+                   ON CONFLICT (user_id) DO UPDATE SET computed_at = EXCLUDED.computed_at`
+	if _, err := tx.Exec(ctx, stateQuery, userID, now); err != nil {
+		return fmt.Errorf("failed to record suggestion state: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetForUser reads the cached suggestions. Follows, blocks and mutes are
+// checked again because they may have changed since the cache was computed.
+func (r *suggestionRepository) GetForUser(ctx context.Context, userID, limit int) ([]models.Suggestion, error) {
+	query := `SELECT s.score, s.mutual_count, s.shared_tags, ` + userColumnsOf("u") + `
+              FROM user_suggestions s
+              JOIN users u ON u.id = s.suggested_id
+              WHERE s.user_id = $1
+                AND NOT EXISTS (SELECT 1 FROM followers f WHERE f.follower_id = $1 AND f.followed_id = u.id)
+                AND NOT EXISTS (SELECT 1 FROM follow_request fr
+                                WHERE fr.sender_id = $1 AND fr.receiver_id = u.id AND fr.status = 'pending')
+                AND ` + notBlockedSQL("$1", "u.id") + `
+                AND ` + notMutedSQL("$1", "u.id") + `
+              ORDER BY s.score DESC, u.id
+              LIMIT $2`
+	rows, err := r.db.Query(ctx, query, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query suggestions: %w", err)
+	}
+	defer rows.Close()
+
+	suggestions := make([]models.Suggestion, 0, limit)
+	for rows.Next() {
+		var suggestion models.Suggestion
+		targets := append([]any{&suggestion.Score, &suggestion.MutualCount, &suggestion.SharedTags},
+			userScanTargets(&suggestion.User)...)
+		if err := rows.Scan(targets...); err != nil {
+			return nil, fmt.Errorf("failed to scan suggestion row: %w", err)
+		}
+		suggestions = append(suggestions, suggestion)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during suggestion rows iteration: %w", err)
+	}
+
+	return suggestions, nil
+}
+
+// GetComputedAt returns when the suggestions of userID were last computed,
+// or ErrNotFound if they never were
+func (r *suggestionRepository) GetComputedAt(ctx context.Context, userID int) (time.Time, error) {
+	var computedAt time.Time
+	err := r.db.QueryRow(ctx, `SELECT computed_at FROM user_suggestions_state WHERE user_id = $1`, userID).Scan(&computedAt)
+	if errors.Is(err, pgx.ErrNoRows) {
+		return time.Time{}, ErrNotFound
+	}
+	return computedAt, err
+}
+
+// GetStaleUserIDs returns users whose suggestions are older than olderThan or
+// were never computed, oldest first
+func (r *suggestionRepository) GetStaleUserIDs(ctx context.Context, olderThan time.Time, limit int) ([]int, error) {
+	query := `SELECT u.id FROM users u
+              LEFT JOIN user_suggestions_state s ON s.user_id = u.id
+              WHERE u.deleted IS NOT TRUE AND (s.computed_at IS NULL OR s.computed_at < $1)
+              ORDER BY s.computed_at NULLS FIRST, u.id
+              LIMIT $2`
+	rows, err := r.db.Query(ctx, query, olderThan, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query stale suggestions: %w", err)
+	}
+	return pgx.CollectRows(rows, pgx.RowTo[int])
+}
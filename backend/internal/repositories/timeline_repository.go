@@ -0,0 +1,241 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// maxFanoutAttempts is how many times a failing fan-out job is tried before
+// it is dropped
+const maxFanoutAttempts = 5
+
+type TimelineRepository interface {
+	EnqueuePost(ctx context.Context, postID, authorID int) error
+	EnqueueFollow(ctx context.Context, followerID, followedID int) error
+	EnqueueTimeline(ctx context.Context, userID int) error
+	ProcessNext(ctx context.Context, limits models.FanoutLimits) (bool, error)
+	IsMaterialized(ctx context.Context, userID int) (bool, error)
+	GetTimeline(ctx context.Context, viewerID int, after *models.PostCursor, limit, maxFollowers int) ([]models.PostWithAuthor, error)
+}
+
+type timelineRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTimelineRepository(db *pgxpool.Pool) TimelineRepository {
+	return &timelineRepository{db: db}
+}
+
+func (r *timelineRepository) EnqueuePost(ctx context.Context, postID, authorID int) error {
+	query := `INSERT INTO fanout_jobs (kind, post_id, author_id) VALUES ($1, $2, $3)`
+	if _, err := r.db.Exec(ctx, query, models.FanoutPost, postID, authorID); err != nil {
+		return fmt.Errorf("failed to enqueue post fan-out: %w", err)
+	}
+	return nil
+}
+
+func (r *timelineRepository) EnqueueFollow(ctx context.Context, followerID, followedID int) error {
+	query := `INSERT INTO fanout_jobs (kind, follower_id, author_id) VALUES ($1, $2, $3)`
+	if _, err := r.db.Exec(ctx, query, models.FanoutFollow, followerID, followedID); err != nil {
+		return fmt.Errorf("failed to enqueue follow backfill: %w", err)
+	}
+	return nil
+}
+
+// EnqueueTimeline queues the backfill of the whole timeline of userID unless
+// one is already waiting
+func (r *timelineRepository) EnqueueTimeline(ctx context.Context, userID int) error {
+	query := `INSERT INTO fanout_jobs (kind, follower_id) VALUES ($1, $2)
+              ON CONFLICT (follower_id) WHERE kind = 'timeline' DO NOTHING`
+	if _, err := r.db.Exec(ctx, query, models.FanoutTimeline, userID); err != nil {
+		return fmt.Errorf("failed to enqueue timeline backfill: %w", err)
+	}
+	return nil
+}
+
+// ProcessNext claims the oldest due job, skipping jobs other workers hold,
+// and runs it in the same transaction it deletes it in. It reports false
+// when there was nothing to do. A failing job is pushed back with a growing
+// delay and dropped after maxFanoutAttempts.
+func (r *timelineRepository) ProcessNext(ctx context.Context, limits models.FanoutLimits) (bool, error) {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	query := `SELECT id, kind, post_id, follower_id, author_id, attempts, run_after, created_at
+              FROM fanout_jobs
+              WHERE run_after <= now()
+              ORDER BY run_after, id
+              LIMIT 1
+              FOR UPDATE SKIP LOCKED`
+	var job models.FanoutJob
+	if err := tx.QueryRow(ctx, query).Scan(&job.ID, &job.Kind, &job.PostID, &job.FollowerID, &job.AuthorID,
+		&job.Attempts, &job.RunAfter, &job.CreatedAt); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return false, nil
+		}
+		return false, fmt.Errorf("failed to claim fan-out job: %w", err)
+	}
+
+	if err := runFanoutJob(ctx, tx, &job, limits); err != nil {
+		_ = tx.Rollback(ctx)
+		return true, r.retry(ctx, &job, err)
+	}
+	if _, err := tx.Exec(ctx, `DELETE FROM fanout_jobs WHERE id = $1`, job.ID); err != nil {
+		return true, fmt.Errorf("failed to delete fan-out job: %w", err)
+	}
+	return true, tx.Commit(ctx)
+}
+
+// runFanoutJob writes the timeline entries of a job. Authors above
+// limits.MaxFollowers only get the entry in their own timeline, their
+// followers read their posts directly. A timeline job marks the timeline as
+// materialized in the same transaction as its entries.
+func runFanoutJob(ctx context.Context, tx pgx.Tx, job *models.FanoutJob, limits models.FanoutLimits) error {
+	var query string
+	var args []any
+	switch job.Kind {
+	case models.FanoutPost:
+		query = `INSERT INTO timeline_entries (user_id, post_id, author_id, created_at)
+                 SELECT r.user_id, p.id, p.user_id, p.created_at
+                 FROM posts p
+                 JOIN users a ON a.id = p.user_id
+                 CROSS JOIN LATERAL (
+                     SELECT p.user_id
+                     UNION ALL
+                     SELECT f.follower_id FROM followers f
+                     WHERE f.followed_id = p.user_id AND a.followers_count <= $2
+                 ) r(user_id)
+                 WHERE p.id = $1 AND p.deleted = FALSE
+                 ON CONFLICT (user_id, post_id) DO NOTHING`
+		args = []any{*job.PostID, limits.MaxFollowers}
+	case models.FanoutFollow:
+		// The follow may have been undone while the job waited
+		query = `INSERT INTO timeline_entries (user_id, post_id, author_id, created_at)
+                 SELECT f.follower_id, p.id, p.user_id, p.created_at
+                 FROM followers f
+                 JOIN users a ON a.id = f.followed_id
+                 CROSS JOIN LATERAL (
+                     SELECT fp.id, fp.user_id, fp.created_at FROM posts fp
+                     WHERE fp.user_id = f.followed_id AND fp.deleted = FALSE
+                     ORDER BY fp.created_at DESC, fp.id DESC
+                     LIMIT $4
+                 ) p
+                 WHERE f.follower_id = $1 AND f.followed_id = $2 AND a.followers_count <= $3
+                 ON CONFLICT (user_id, post_id) DO NOTHING`
+		args = []any{*job.FollowerID, *job.AuthorID, limits.MaxFollowers, limits.BackfillPosts}
+	case models.FanoutTimeline:
+		query = `INSERT INTO timeline_entries (user_id, post_id, author_id, created_at)
+                 SELECT $1, p.id, p.user_id, p.created_at
+                 FROM (
+                     SELECT $1::int
+                     UNION
+                     SELECT f.followed_id FROM followers f
+                     JOIN users a ON a.id = f.followed_id
+                     WHERE f.follower_id = $1 AND a.followers_count <= $2
+                 ) s(author_id)
+                 CROSS JOIN LATERAL (
+                     SELECT fp.id, fp.user_id, fp.created_at FROM posts fp
+                     WHERE fp.user_id = s.author_id AND fp.deleted = FALSE
+                     ORDER BY fp.created_at DESC, fp.id DESC
+                     LIMIT $3
+                 ) p
+                 ON CONFLICT (user_id, post_id) DO NOTHING`
+		args = []any{*job.FollowerID, limits.MaxFollowers, limits.BackfillPosts}
+	default:
+		return fmt.Errorf("unknown fan-out job kind %q", job.Kind)
+	}
+
+	if _, err := tx.Exec(ctx, query, args...); err != nil {
+		return fmt.Errorf("failed to write timeline entries: %w", err)
+	}
+	if job.Kind == models.FanoutTimeline {
+		query = `INSERT INTO materialized_timelines (user_id) VALUES ($1) ON CONFLICT (user_id) DO NOTHING`
+		if _, err := tx.Exec(ctx, query, *job.FollowerID); err != nil {
+			return fmt.Errorf("failed to mark timeline as materialized: %w", err)
+		}
+	}
+	return nil
+}
+
+func (r *timelineRepository) retry(ctx context.Context, job *models.FanoutJob, cause error) error {
+	if job.Attempts+1 >= maxFanoutAttempts {
+		if _, err := r.db.Exec(ctx, `DELETE FROM fanout_jobs WHERE id = $1`, job.ID); err != nil {
+			return fmt.Errorf("failed to drop fan-out job %d: %w", job.ID, err)
+		}
+		return fmt.Errorf("dropped fan-out job %d after %d attempts: %w", job.ID, maxFanoutAttempts, cause)
+	}
+
+	query := `UPDATE fanout_jobs SET attempts = attempts + 1, run_after = now() + make_interval(mins => attempts + 1)
+              WHERE id = $1`
+	if _, err := r.db.Exec(ctx, query, job.ID); err != nil {
+		return fmt.Errorf("failed to reschedule fan-out job %d: %w", job.ID, err)
+	}
+	return fmt.Errorf("fan-out job %d failed: %w", job.ID, cause)
+}
+
+// IsMaterialized reports whether the timeline of userID was backfilled from
+// every account they follow
+func (r *timelineRepository) IsMaterialized(ctx context.Context, userID int) (bool, error) {
+	var exists bool
+	query := `SELECT EXISTS (SELECT 1 FROM materialized_timelines WHERE user_id = $1)`
+	if err := r.db.QueryRow(ctx, query, userID).Scan(&exists); err != nil {
+		return false, fmt.Errorf("failed to check timeline: %w", err)
+	}
+	return exists, nil
+}
+
+// GetTimeline reads the materialized timeline of the viewer after the cursor
+// and merges in the posts of followed accounts above maxFollowers, which are
+// never fanned out. Each side contributes at most limit posts, so the merge
+// stays as cheap as a single page.
+func (r *timelineRepository) GetTimeline(ctx context.Context, viewerID int, after *models.PostCursor,
+	limit, maxFollowers int) ([]models.PostWithAuthor, error) {
+	if after == nil {
+		after = &models.PostCursor{}
+	}
+
+	query := `WITH entries AS (
+                  SELECT te.post_id FROM timeline_entries te
+                  JOIN posts ep ON ep.id = te.post_id
+                  WHERE te.user_id = $1 AND ep.deleted = FALSE
+                    AND ` + notMutedSQL("$1", "te.author_id") + `
+                    AND ($3::int = 0 OR (te.created_at, te.post_id) < ($2, $3))
+                  ORDER BY te.created_at DESC, te.post_id DESC
+                  LIMIT $4
+              ), merged AS (
+                  SELECT cp.id AS post_id
+                  FROM followers f
+                  JOIN users a ON a.id = f.followed_id
+                  CROSS JOIN LATERAL (
+                      SELECT id FROM posts ap
+                      WHERE ap.user_id = f.followed_id AND ap.deleted = FALSE
+                        AND ($3::int = 0 OR (ap.created_at, ap.id) < ($2, $3))
+                      ORDER BY ap.created_at DESC, ap.id DESC
+                      LIMIT $4
+                  ) cp
+                  WHERE f.follower_id = $1 AND a.followers_count > $5
+                    AND ` + notMutedSQL("$1", "a.id") + `
+              )
+              SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `
+              FROM posts p
+              JOIN users u ON u.id = p.user_id
+              WHERE p.id IN (SELECT post_id FROM entries UNION SELECT post_id FROM merged)
+                AND ` + notBlockedSQL("$1", "u.id") + `
+              ORDER BY p.created_at DESC, p.id DESC
+              LIMIT $4`
+	rows, err := r.db.Query(ctx, query, viewerID, after.CreatedAt, after.ID, limit, maxFollowers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query timeline: %w", err)
+	}
+	defer rows.Close()
+
+	return collectPostsWithAuthor(rows, limit)
+}
@@ -0,0 +1,162 @@
+package repositories
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+// trendingEligibleSQL limits trending to posts anyone may see: live, not
+// marked NSFW and written by public accounts
+const trendingEligibleSQL = `p.deleted = FALSE AND p.nsfw = FALSE AND u.is_private = FALSE`
+
+// recentEngagementSQL lists the likes, comments, reactions and saves made
+// since $1, weighted by how much effort each one takes
+const recentEngagementSQL = `SELECT post_id, user_id, 1.0 AS weight FROM likes WHERE created_at >= $1
+                             UNION ALL
+                             SELECT post_id, user_id, 2.0 FROM comments WHERE created_at >= $1 AND deleted = FALSE
+                             UNION ALL
+                             SELECT post_id, user_id, 1.0 FROM reactions WHERE created_at >= $1 AND post_id IS NOT NULL
+                             UNION ALL
+                             SELECT post_id, user_id, 2.0 FROM bookmarks WHERE created_at >= $1`
+
+type TrendingRepository interface {
+	Refresh(ctx context.Context, since, now time.Time, perAuthor, postLimit, hashtagLimit int) error
+	GetPosts(ctx context.Context, viewerID, limit, offset int) ([]models.PostWithAuthor, error)
+	GetHashtags(ctx context.Context, limit int) ([]models.TrendingHashtag, error)
+}
+
+type trendingRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewTrendingRepository(db *pgxpool.Pool) TrendingRepository {
+	return &trendingRepository{db: db}
+}
+
+// Refresh recomputes the trending posts and hashtags from the engagement
+// since the start of the window and swaps them in at once.
+//
+// Posts are scored by engagement velocity: the weighted engagement they got
+// in the window per hour they were live in it, damped so a burst on a brand
+// new post does not outrank sustained interest. At most perAuthor posts of
+// the same author are kept. Hashtags are scored by how many different people
+// posted with them or engaged with their posts, so a single account cannot
+// push a tag up. Nobody's engagement with their own posts counts.
+func (r *trendingRepository) Refresh(ctx context.Context, since, now time.Time, perAuthor, postLimit, hashtagLimit int) error {
+	tx, err := r.db.Begin(ctx)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback(ctx) //nolint:errcheck // no-op after commit
+
+	if _, err := tx.Exec(ctx, `DELETE FROM trending_posts`); err != nil {
+		return fmt.Errorf("failed to clear trending posts: %w", err)
+	}
+	postsQuery := `WITH engagement AS (` + recentEngagementSQL + `
+                   ), scored AS (
+                       SELECT p.id, p.user_id,
+                              SUM(e.weight) / power(EXTRACT(EPOCH FROM ($2::timestamp - GREATEST(p.created_at, $1))) / 3600 + 2, 1.5) AS score
+                       FROM engagement e
+                       JOIN posts p ON p.id = e.post_id
+                       JOIN users u ON u.id = p.user_id
+                       WHERE ` + trendingEligibleSQL + ` AND e.user_id <> p.user_id
+                       GROUP BY p.id, p.user_id, p.created_at
+                   ), diversified AS (
+                       SELECT id, score, ROW_NUMBER() OVER (PARTITION BY user_id ORDER BY score DESC, id DESC) AS author_rank
+                       FROM scored
+                   )
+                   INSERT INTO trending_posts (post_id, score, computed_at)
+                   SELECT id, score, $2 FROM diversified
+                   WHERE author_rank <= $3
+                   ORDER BY score DESC, id DESC
+                   LIMIT $4`
+	if _, err := tx.Exec(ctx, postsQuery, since, now, perAuthor, postLimit); err != nil {
+		return fmt.Errorf("failed to compute trending posts: %w", err)
+	}
+
+	if _, err := tx.Exec(ctx, `DELETE FROM trending_hashtags`); err != nil {
+		return fmt.Errorf("failed to clear trending hashtags: %w", err)
+	}
+	hashtagsQuery := `WITH engagement AS (` + recentEngagementSQL + `
+                      ), activity AS (
+                          SELECT pt.tag_id, p.user_id AS actor_id, p.id AS new_post_id
+                          FROM posts p
+                          JOIN users u ON u.id = p.user_id
+                          JOIN post_tags pt ON pt.post_id = p.id
+                          WHERE ` + trendingEligibleSQL + ` AND p.created_at >= $1
+                          UNION ALL
+                          SELECT pt.tag_id, e.user_id, NULL
+                          FROM engagement e
+                          JOIN posts p ON p.id = e.post_id
+                          JOIN users u ON u.id = p.user_id
+                          JOIN post_tags pt ON pt.post_id = p.id
+                          WHERE ` + trendingEligibleSQL + ` AND e.user_id <> p.user_id
+                      )
+                      INSERT INTO trending_hashtags (tag_id, score, recent_post_count, computed_at)
+                      SELECT a.tag_id, COUNT(DISTINCT a.actor_id), COUNT(DISTINCT a.new_post_id), $2
+                      FROM activity a
+                      JOIN hashtags h ON h.id = a.tag_id
+                      WHERE h.deleted = FALSE
+                      GROUP BY a.tag_id
+                      ORDER BY COUNT(DISTINCT a.actor_id) DESC, a.tag_id
+                      LIMIT $3`
+	if _, err := tx.Exec(ctx, hashtagsQuery, since, now, hashtagLimit); err != nil {
+		return fmt.Errorf("failed to compute trending hashtags: %w", err)
+	}
+
+	return tx.Commit(ctx)
+}
+
+// GetPosts pages through the trending posts, highest score first. Posts that
+// became hidden from the viewer, muted or NSFW since the last refresh are
+// left out.
+func (r *trendingRepository) GetPosts(ctx context.Context, viewerID, limit, offset int) ([]models.PostWithAuthor, error) {
+	query := `SELECT ` + postColumnsOf("p") + `, ` + userColumnsOf("u") + `
+              FROM trending_posts t
+              JOIN posts p ON p.id = t.post_id
+              JOIN users u ON u.id = p.user_id
+              WHERE ` + trendingEligibleSQL + `
+                AND ` + visibleToSQL("$1", "u.id", "u.is_private") + `
+                AND ` + notMutedSQL("$1", "u.id") + `
+              ORDER BY t.score DESC, t.post_id DESC
+              LIMIT $2 OFFSET $3`
+	rows, err := r.db.Query(ctx, query, viewerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending posts: %w", err)
+	}
+	return collectPostsWithAuthor(rows, limit)
+}
+
+// GetHashtags returns the trending hashtags, highest score first, skipping
+// tags removed by moderators since the last refresh
+func (r *trendingRepository) GetHashtags(ctx context.Context, limit int) ([]models.TrendingHashtag, error) {
+	query := `SELECT h.id, h.name, h.post_count, h.created_at, t.score, t.recent_post_count, t.computed_at
+              FROM trending_hashtags t
+              JOIN hashtags h ON h.id = t.tag_id
+              WHERE h.deleted = FALSE
+              ORDER BY t.score DESC, t.tag_id
+              LIMIT $1`
+	rows, err := r.db.Query(ctx, query, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query trending hashtags: %w", err)
+	}
+	defer rows.Close()
+
+	hashtags := make([]models.TrendingHashtag, 0, limit)
+	for rows.Next() {
+		var tag models.TrendingHashtag
+		if err := rows.Scan(&tag.ID, &tag.Name, &tag.PostCount, &tag.CreatedAt, &tag.Score, &tag.RecentPostCount,
+			&tag.ComputedAt); err != nil {
+			return nil, fmt.Errorf("failed to scan trending hashtag row: %w", err)
+		}
+		hashtags = append(hashtags, tag)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during trending hashtag rows iteration: %w", err)
+	}
+	return hashtags, nil
+}
@@ -4,6 +4,7 @@ package repositories
 import (
 	"context"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/escuadron-404/red404/backend/internal/models"
@@ -14,12 +15,57 @@ import (
 type UserRepository interface {
 	Create(ctx context.Context, user *models.User) error
 	GetByID(ctx context.Context, id int) (*models.User, error)
+	GetVisibleByID(ctx context.Context, viewerID, id int) (*models.User, error)
 	GetByEmail(ctx context.Context, email string) (*models.User, error)
-	GetAll(ctx context.Context, limit, offset int) ([]models.User, int, error)
+	GetByUsername(ctx context.Context, username string) (*models.User, error)
+	GetAll(ctx context.Context, viewerID, limit, offset int) ([]models.User, int, error)
 	Update(ctx context.Context, user *models.User) error
+	SetPrivate(ctx context.Context, id int, isPrivate bool) error
+	SetUsername(ctx context.Context, id int, username string) error
+	SetDMPolicy(ctx context.Context, id int, policy models.DMPolicy) error
 	Delete(ctx context.Context, id int) error
 }
 
+// userColumns is the column list shared by every query that loads a full user
+// and must match the order expected by scanUser
+const userColumns = `id, email, username, password, is_private, role, dm_policy, followers_count, following_count, created_at, updated_at`
+
+// userColumnsOf qualifies userColumns with a table alias for use in joins
+func userColumnsOf(alias string) string {
+	columns := strings.Split(userColumns, ", ")
+	for i, column := range columns {
+		columns[i] = alias + "." + column
+	}
+	return strings.Join(columns, ", ")
+}
+
+// userScanTargets returns the scan destinations matching userColumns
+func userScanTargets(user *models.User) []any {
+	return []any{&user.ID, &user.Email, &user.Username, &user.Password, &user.IsPrivate, &user.Role, &user.DMPolicy,
+		&user.FollowersCount, &user.FollowingCount, &user.CreatedAt, &user.UpdatedAt}
+}
+
+// scanUser reads a row selected with userColumns
+func scanUser(row pgx.Row, user *models.User) error {
+	return row.Scan(userScanTargets(user)...)
+}
+
+// collectUsers scans every row of a userColumns query
+func collectUsers(rows pgx.Rows, capacity int) ([]models.User, error) {
+	users := make([]models.User, 0, capacity)
+	for rows.Next() {
+		var user models.User
+		if err := scanUser(rows, &user); err != nil {
+			return nil, fmt.Errorf("failed to scan user row: %w", err)
+		}
+		users = append(users, user)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during user rows iteration: %w", err)
+	}
+	return users, nil
+}
+
 type userRepository struct {
 	db *pgxpool.Pool
 }
@@ -32,17 +78,35 @@ func (r *userRepository) Create(ctx context.Context, user *models.User) error {
 	now := time.Now()
 	user.CreatedAt = now
 	user.UpdatedAt = now
-	query := `INSERT INTO users (email, password, created_at, updated_at) 
-              VALUES ($1, $2, $3, $4) RETURNING id`
-	return r.db.QueryRow(ctx, query, user.Email, user.Password, user.CreatedAt, user.UpdatedAt).
-		Scan(&user.ID)
+	query := `INSERT INTO users (email, username, password, created_at, updated_at) 
+              VALUES ($1, $2, $3, $4, $5) RETURNING id, role, dm_policy`
+	err := r.db.QueryRow(ctx, query, user.Email, user.Username, user.Password, user.CreatedAt, user.UpdatedAt).
+		Scan(&user.ID, &user.Role, &user.DMPolicy)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
 }
 
 func (r *userRepository) GetByID(ctx context.Context, id int) (*models.User, error) {
-	query := `SELECT id, email, password, created_at, updated_at FROM users WHERE id = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1`
+	user := &models.User{}
+	err := scanUser(r.db.QueryRow(ctx, query, id), user)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, fmt.Errorf("user not found")
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+// GetVisibleByID behaves like GetByID but hides users that blocked the
+// viewer or were blocked by them
+func (r *userRepository) GetVisibleByID(ctx context.Context, viewerID, id int) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE id = $1 AND ` + notBlockedSQL("$2", "users.id")
 	user := &models.User{}
-	err := r.db.QueryRow(ctx, query, id).Scan(
-		&user.ID, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	err := scanUser(r.db.QueryRow(ctx, query, id, viewerID), user)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
@@ -53,10 +117,9 @@ func (r *userRepository) GetByID(ctx context.Context, id int) (*models.User, err
 }
 
 func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.User, error) {
-	query := `SELECT id, email, password, created_at, updated_at FROM users WHERE email = $1`
+	query := `SELECT ` + userColumns + ` FROM users WHERE email = $1`
 	user := &models.User{}
-	err := r.db.QueryRow(ctx, query, email).Scan(
-		&user.ID, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt)
+	err := scanUser(r.db.QueryRow(ctx, query, email), user)
 	if err != nil {
 		if err == pgx.ErrNoRows {
 			return nil, fmt.Errorf("user not found")
@@ -66,7 +129,20 @@ func (r *userRepository) GetByEmail(ctx context.Context, email string) (*models.
 	return user, nil
 }
 
-func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]models.User, int, error) {
+func (r *userRepository) GetByUsername(ctx context.Context, username string) (*models.User, error) {
+	query := `SELECT ` + userColumns + ` FROM users WHERE username = $1`
+	user := &models.User{}
+	err := scanUser(r.db.QueryRow(ctx, query, username), user)
+	if err != nil {
+		if err == pgx.ErrNoRows {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return user, nil
+}
+
+func (r *userRepository) GetAll(ctx context.Context, viewerID, limit, offset int) ([]models.User, int, error) {
 	const maxLimit = 100
 	if limit > maxLimit {
 		limit = maxLimit
@@ -75,8 +151,8 @@ func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]model
 	}
 
 	var totalUsers int
-	countQuery := `SELECT COUNT(*) FROM users`
-	err := r.db.QueryRow(ctx, countQuery).Scan(&totalUsers)
+	countQuery := `SELECT COUNT(*) FROM users WHERE ` + notBlockedSQL("$1", "users.id")
+	err := r.db.QueryRow(ctx, countQuery, viewerID).Scan(&totalUsers)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to count users for pagination: %w", err)
 	}
@@ -85,8 +161,8 @@ func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]model
 		return []models.User{}, 0, nil
 	}
 
-	query := `SELECT id, email, password, created_at, updated_at FROM users ORDER BY id LIMIT $1 OFFSET $2`
-	rows, err := r.db.Query(ctx, query, limit, offset)
+	query := `SELECT ` + userColumns + ` FROM users WHERE ` + notBlockedSQL("$3", "users.id") + ` ORDER BY id LIMIT $1 OFFSET $2`
+	rows, err := r.db.Query(ctx, query, limit, offset, viewerID)
 	if err != nil {
 		return nil, 0, fmt.Errorf("failed to query paginated users: %w", err)
 	}
@@ -96,7 +172,7 @@ func (r *userRepository) GetAll(ctx context.Context, limit, offset int) ([]model
 
 	for rows.Next() {
 		var user models.User
-		if err := rows.Scan(&user.ID, &user.Email, &user.Password, &user.CreatedAt, &user.UpdatedAt); err != nil {
+		if err := scanUser(rows, &user); err != nil {
 			return nil, 0, fmt.Errorf("failed to scan user row during pagination: %w", err)
 		}
 		users = append(users, user)
@@ -115,6 +191,29 @@ func (r *userRepository) Update(ctx context.Context, user *models.User) error {
 	return err
 }
 
+func (r *userRepository) SetPrivate(ctx context.Context, id int, isPrivate bool) error {
+	query := `UPDATE users SET is_private = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(ctx, query, isPrivate, time.Now(), id)
+	return err
+}
+
+func (r *userRepository) SetDMPolicy(ctx context.Context, id int, policy models.DMPolicy) error {
+	query := `UPDATE users SET dm_policy = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(ctx, query, policy, time.Now(), id)
+	return err
+}
+
+// SetUsername renames the user. It fails with ErrConflict when the name is
+// already taken.
+func (r *userRepository) SetUsername(ctx context.Context, id int, username string) error {
+	query := `UPDATE users SET username = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(ctx, query, username, time.Now(), id)
+	if isUniqueViolation(err) {
+		return ErrConflict
+	}
+	return err
+}
+
 func (r *userRepository) Delete(ctx context.Context, id int) error {
 	query := `DELETE FROM users WHERE id = $1`
 	_, err := r.db.Exec(ctx, query, id)
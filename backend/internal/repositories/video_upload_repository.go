@@ -0,0 +1,163 @@
+package repositories
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const videoUploadColumns = `id, user_id, upload_length, upload_offset, filename, status, error,
+       COALESCE(storage_key, ''), COALESCE(poster_key, ''), COALESCE(content_type, ''),
+       COALESCE(width, 0), COALESCE(height, 0), COALESCE(duration_ms, 0),
+       created_at, updated_at, expires_at`
+
+type VideoUploadRepository interface {
+	Create(ctx context.Context, upload *models.VideoUpload) error
+	GetByID(ctx context.Context, id string) (*models.VideoUpload, error)
+	UpdateOffset(ctx context.Context, id string, from, to int64) error
+	MarkReady(ctx context.Context, upload *models.VideoUpload) error
+	MarkFailed(ctx context.Context, id, reason string) error
+	Claim(ctx context.Context, userID int, id string) (*models.VideoUpload, error)
+	Release(ctx context.Context, id string) error
+	Delete(ctx context.Context, id string) error
+	GetExpired(ctx context.Context, now time.Time, limit int) ([]models.VideoUpload, error)
+}
+
+type videoUploadRepository struct {
+	db *pgxpool.Pool
+}
+
+func NewVideoUploadRepository(db *pgxpool.Pool) VideoUploadRepository {
+	return &videoUploadRepository{db: db}
+}
+
+func scanVideoUpload(row pgx.Row, upload *models.VideoUpload) error {
+	return row.Scan(&upload.ID, &upload.UserID, &upload.UploadLength, &upload.UploadOffset, &upload.Filename,
+		&upload.Status, &upload.Error, &upload.StorageKey, &upload.PosterKey, &upload.ContentType,
+		&upload.Width, &upload.Height, &upload.DurationMS, &upload.CreatedAt, &upload.UpdatedAt, &upload.ExpiresAt)
+}
+
+func (r *videoUploadRepository) Create(ctx context.Context, upload *models.VideoUpload) error {
+	now := time.Now()
+	upload.Status = models.VideoUploadUploading
+	upload.CreatedAt = now
+	upload.UpdatedAt = now
+	query := `INSERT INTO video_uploads (id, user_id, upload_length, upload_offset, filename, status, created_at, updated_at, expires_at)
+              VALUES ($1, $2, $3, 0, $4, $5, $6, $7, $8)`
+	_, err := r.db.Exec(ctx, query, upload.ID, upload.UserID, upload.UploadLength, upload.Filename,
+		upload.Status, upload.CreatedAt, upload.UpdatedAt, upload.ExpiresAt)
+	return err
+}
+
+func (r *videoUploadRepository) GetByID(ctx context.Context, id string) (*models.VideoUpload, error) {
+	query := `SELECT ` + videoUploadColumns + ` FROM video_uploads WHERE id = $1`
+	upload := &models.VideoUpload{}
+	if err := scanVideoUpload(r.db.QueryRow(ctx, query, id), upload); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return upload, nil
+}
+
+// UpdateOffset moves the offset of an upload in progress. It only succeeds
+// when the stored offset is still from, so concurrent PATCH requests cannot
+// both advance it.
+func (r *videoUploadRepository) UpdateOffset(ctx context.Context, id string, from, to int64) error {
+	query := `UPDATE video_uploads SET upload_offset = $1, updated_at = $2
+              WHERE id = $3 AND upload_offset = $4 AND status = 'uploading'`
+	tag, err := r.db.Exec(ctx, query, to, time.Now(), id, from)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+func (r *videoUploadRepository) MarkReady(ctx context.Context, upload *models.VideoUpload) error {
+	upload.Status = models.VideoUploadReady
+	upload.UpdatedAt = time.Now()
+	query := `UPDATE video_uploads
+              SET status = $1, storage_key = $2, poster_key = $3, content_type = $4,
+                  width = $5, height = $6, duration_ms = $7, updated_at = $8
+              WHERE id = $9`
+	_, err := r.db.Exec(ctx, query, upload.Status, upload.StorageKey, upload.PosterKey, upload.ContentType,
+		upload.Width, upload.Height, upload.DurationMS, upload.UpdatedAt, upload.ID)
+	return err
+}
+
+func (r *videoUploadRepository) MarkFailed(ctx context.Context, id, reason string) error {
+	query := `UPDATE video_uploads SET status = 'failed', error = $1, updated_at = $2 WHERE id = $3`
+	_, err := r.db.Exec(ctx, query, reason, time.Now(), id)
+	return err
+}
+
+// Claim marks a ready upload of userID as attached to a post so it cannot be
+// used twice
+func (r *videoUploadRepository) Claim(ctx context.Context, userID int, id string) (*models.VideoUpload, error) {
+	query := `UPDATE video_uploads SET status = 'attached', updated_at = $1
+              WHERE id = $2 AND user_id = $3 AND status = 'ready'
+              RETURNING ` + videoUploadColumns
+	upload := &models.VideoUpload{}
+	if err := scanVideoUpload(r.db.QueryRow(ctx, query, time.Now(), id, userID), upload); err != nil {
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+	return upload, nil
+}
+
+// Release makes a claimed upload available again after the post using it
+// could not be created
+func (r *videoUploadRepository) Release(ctx context.Context, id string) error {
+	query := `UPDATE video_uploads SET status = 'ready', updated_at = $1 WHERE id = $2 AND status = 'attached'`
+	_, err := r.db.Exec(ctx, query, time.Now(), id)
+	return err
+}
+
+func (r *videoUploadRepository) Delete(ctx context.Context, id string) error {
+	tag, err := r.db.Exec(ctx, `DELETE FROM video_uploads WHERE id = $1`, id)
+	if err != nil {
+		return err
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// GetExpired returns uploads past their expiration that never made it into
+// a post
+func (r *videoUploadRepository) GetExpired(ctx context.Context, now time.Time, limit int) ([]models.VideoUpload, error) {
+	query := `SELECT ` + videoUploadColumns + ` FROM video_uploads
+              WHERE expires_at < $1 AND status <> 'attached'
+              ORDER BY expires_at
+              LIMIT $2`
+	rows, err := r.db.Query(ctx, query, now, limit)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query expired uploads: %w", err)
+	}
+	defer rows.Close()
+
+	uploads := make([]models.VideoUpload, 0, limit)
+	for rows.Next() {
+		var upload models.VideoUpload
+		if err := scanVideoUpload(rows, &upload); err != nil {
+			return nil, fmt.Errorf("failed to scan upload row: %w", err)
+		}
+		uploads = append(uploads, upload)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, fmt.Errorf("error during upload rows iteration: %w", err)
+	}
+	return uploads, nil
+}
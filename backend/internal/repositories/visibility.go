@@ -0,0 +1,30 @@
+package repositories
+
+// Blocks and mutes are enforced in SQL so no query can forget about them.
+// Every repository query returning users, posts or comments to a viewer adds
+// these predicates for the column holding the author of each row.
+
+// notBlockedSQL is true when neither the viewer nor the user in userColumn has
+// blocked the other. viewerParam is the placeholder holding the viewer id.
+func notBlockedSQL(viewerParam, userColumn string) string {
+	return `NOT EXISTS (SELECT 1 FROM user_blocks ub
+                WHERE (ub.blocker_id = ` + viewerParam + ` AND ub.blocked_id = ` + userColumn + `)
+                   OR (ub.blocker_id = ` + userColumn + ` AND ub.blocked_id = ` + viewerParam + `))`
+}
+
+// notMutedSQL is true when the viewer has not muted the user in userColumn.
+// Muting only hides content from feeds, so it is applied to feed style
+// queries and not to direct lookups.
+func notMutedSQL(viewerParam, userColumn string) string {
+	return `NOT EXISTS (SELECT 1 FROM user_mutes um
+                WHERE um.muter_id = ` + viewerParam + ` AND um.muted_id = ` + userColumn + `)`
+}
+
+// visibleToSQL combines the block predicate with the privacy rule: content of
+// private accounts is only visible to the owner and approved followers
+func visibleToSQL(viewerParam, userColumn, isPrivateColumn string) string {
+	return `(` + notBlockedSQL(viewerParam, userColumn) + `
+            AND (NOT ` + isPrivateColumn + ` OR ` + userColumn + ` = ` + viewerParam + `
+                 OR EXISTS (SELECT 1 FROM followers vf
+                            WHERE vf.follower_id = ` + viewerParam + ` AND vf.followed_id = ` + userColumn + `)))`
+}
@@ -8,17 +8,74 @@ import (
 	"github.com/escuadron-404/red404/backend/pkg/middleware"
 )
 
+// Handlers groups every HTTP handler the routes are wired to
+type Handlers struct {
+	User   *handlers.UserHandler
+	Auth   *handlers.AuthHandler
+	Media  *handlers.MediaHandler
+	Follow *handlers.FollowHandler
+	Block  *handlers.BlockHandler
+	Post   *handlers.PostHandler
+
+	Comment      *handlers.CommentHandler
+	Reaction     *handlers.ReactionHandler
+	Notification *handlers.NotificationHandler
+	Search       *handlers.SearchHandler
+	Message      *handlers.MessageHandler
+	Realtime     *handlers.RealtimeHandler
+
+	VideoUpload *handlers.VideoUploadHandler
+
+	Suggestion *handlers.SuggestionHandler
+}
+
 // SetupRoutes configures all application routes.
-func SetupRoutes(userHandler *handlers.UserHandler, authHandler *handlers.AuthHandler, authMiddleware *middleware.AuthMiddleware) http.Handler {
+func SetupRoutes(h *Handlers, authMiddleware *middleware.AuthMiddleware) http.Handler {
 	mux := http.NewServeMux()
 	// Register static route / frontend
 	RegisterFrontendHandlers(mux)
 
 	// Register authentication-related routes
-	AuthRoutes(mux, authHandler)
+	AuthRoutes(mux, h.Auth)
 
 	// Register user-related routes
-	UserRoutes(mux, userHandler, authMiddleware)
+	UserRoutes(mux, h.User, authMiddleware)
+
+	// Register follow-related routes
+	FollowRoutes(mux, h.Follow, authMiddleware)
+
+	// Register block and mute routes
+	BlockRoutes(mux, h.Block, authMiddleware)
+
+	// Register post routes
+	PostRoutes(mux, h.Post, authMiddleware)
+
+	// Register comment routes
+	CommentRoutes(mux, h.Comment, authMiddleware)
+
+	// Register reaction routes
+	ReactionRoutes(mux, h.Reaction, authMiddleware)
+
+	// Register notification routes
+	NotificationRoutes(mux, h.Notification, authMiddleware)
+
+	// Register search routes
+	SearchRoutes(mux, h.Search, authMiddleware)
+
+	// Register direct message routes
+	MessageRoutes(mux, h.Message, authMiddleware)
+
+	// Register the realtime event streams
+	RealtimeRoutes(mux, h.Realtime, authMiddleware)
+
+	// Register resumable video upload routes
+	VideoUploadRoutes(mux, h.VideoUpload, authMiddleware)
+
+	// Register suggestion routes
+	SuggestionRoutes(mux, h.Suggestion, authMiddleware)
+
+	// Register media routes
+	MediaRoutes(mux, h.Media)
 
 	return mux
 }
@@ -31,4 +88,126 @@ func AuthRoutes(mux *http.ServeMux, authHandler *handlers.AuthHandler) {
 func UserRoutes(mux *http.ServeMux, userHandler *handlers.UserHandler, authMiddleware *middleware.AuthMiddleware) {
 	mux.HandleFunc("GET /api/users/{id}", authMiddleware.Auth(userHandler.GetUserByID))
 	mux.HandleFunc("GET /api/users", authMiddleware.Auth(userHandler.GetAllUsers))
+	mux.HandleFunc("PUT /api/me/privacy", authMiddleware.Auth(userHandler.UpdatePrivacy))
+	mux.HandleFunc("PUT /api/me/username", authMiddleware.Auth(userHandler.UpdateUsername))
+	mux.HandleFunc("PUT /api/me/dm-policy", authMiddleware.Auth(userHandler.UpdateDMPolicy))
+}
+
+func FollowRoutes(mux *http.ServeMux, followHandler *handlers.FollowHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("POST /api/users/{id}/follow", authMiddleware.Auth(followHandler.Follow))
+	mux.HandleFunc("DELETE /api/users/{id}/follow", authMiddleware.Auth(followHandler.Unfollow))
+	mux.HandleFunc("GET /api/users/{id}/followers", authMiddleware.Auth(followHandler.GetFollowers))
+	mux.HandleFunc("GET /api/users/{id}/following", authMiddleware.Auth(followHandler.GetFollowing))
+	mux.HandleFunc("POST /api/users/{id}/follow-requests", authMiddleware.Auth(followHandler.RequestFollow))
+	mux.HandleFunc("GET /api/me/follow-requests", authMiddleware.Auth(followHandler.GetIncomingRequests))
+	mux.HandleFunc("POST /api/follow-requests/{id}/accept", authMiddleware.Auth(followHandler.AcceptRequest))
+	mux.HandleFunc("POST /api/follow-requests/{id}/reject", authMiddleware.Auth(followHandler.RejectRequest))
+}
+
+func BlockRoutes(mux *http.ServeMux, blockHandler *handlers.BlockHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("POST /api/users/{id}/block", authMiddleware.Auth(blockHandler.Block))
+	mux.HandleFunc("DELETE /api/users/{id}/block", authMiddleware.Auth(blockHandler.Unblock))
+	mux.HandleFunc("GET /api/me/blocks", authMiddleware.Auth(blockHandler.GetBlocked))
+	mux.HandleFunc("POST /api/users/{id}/mute", authMiddleware.Auth(blockHandler.Mute))
+	mux.HandleFunc("DELETE /api/users/{id}/mute", authMiddleware.Auth(blockHandler.Unmute))
+	mux.HandleFunc("GET /api/me/mutes", authMiddleware.Auth(blockHandler.GetMuted))
+}
+
+func PostRoutes(mux *http.ServeMux, postHandler *handlers.PostHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("POST /api/posts", authMiddleware.Auth(postHandler.CreatePost))
+	mux.HandleFunc("GET /api/posts/{id}", authMiddleware.Auth(postHandler.GetPost))
+	mux.HandleFunc("PUT /api/posts/{id}", authMiddleware.Auth(postHandler.UpdatePost))
+	mux.HandleFunc("DELETE /api/posts/{id}", authMiddleware.Auth(postHandler.DeletePost))
+	mux.HandleFunc("PUT /api/posts/{id}/like", authMiddleware.Auth(postHandler.LikePost))
+	mux.HandleFunc("DELETE /api/posts/{id}/like", authMiddleware.Auth(postHandler.UnlikePost))
+	mux.HandleFunc("PUT /api/posts/{id}/bookmark", authMiddleware.Auth(postHandler.SavePost))
+	mux.HandleFunc("DELETE /api/posts/{id}/bookmark", authMiddleware.Auth(postHandler.UnsavePost))
+	mux.HandleFunc("PUT /api/posts/{id}/media/{mediaId}", authMiddleware.Auth(postHandler.UpdateMediaAltText))
+	mux.HandleFunc("GET /api/users/{id}/posts", authMiddleware.Auth(postHandler.GetUserPosts))
+	mux.HandleFunc("GET /api/videos", authMiddleware.Auth(postHandler.GetVideoPosts))
+	mux.HandleFunc("GET /api/feed", authMiddleware.Auth(postHandler.GetFeed))
+	mux.HandleFunc("GET /api/explore", authMiddleware.Auth(postHandler.GetExplore))
+	mux.HandleFunc("GET /api/hashtags/{name}", authMiddleware.Auth(postHandler.GetHashtag))
+	mux.HandleFunc("GET /api/hashtags/{name}/posts", authMiddleware.Auth(postHandler.GetHashtagPosts))
+	mux.HandleFunc("GET /api/me/saved", authMiddleware.Auth(postHandler.GetSavedPosts))
+	mux.HandleFunc("GET /api/me/collections", authMiddleware.Auth(postHandler.GetCollections))
+	mux.HandleFunc("POST /api/me/collections", authMiddleware.Auth(postHandler.CreateCollection))
+	mux.HandleFunc("PUT /api/me/collections/{id}", authMiddleware.Auth(postHandler.RenameCollection))
+	mux.HandleFunc("DELETE /api/me/collections/{id}", authMiddleware.Auth(postHandler.DeleteCollection))
+}
+
+func CommentRoutes(mux *http.ServeMux, commentHandler *handlers.CommentHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("POST /api/posts/{id}/comments", authMiddleware.Auth(commentHandler.CreateComment))
+	mux.HandleFunc("GET /api/posts/{id}/comments", authMiddleware.Auth(commentHandler.GetPostComments))
+	mux.HandleFunc("GET /api/comments/{id}/replies", authMiddleware.Auth(commentHandler.GetReplies))
+	mux.HandleFunc("PUT /api/comments/{id}", authMiddleware.Auth(commentHandler.UpdateComment))
+	mux.HandleFunc("DELETE /api/comments/{id}", authMiddleware.Auth(commentHandler.DeleteComment))
+	mux.HandleFunc("POST /api/comments/{id}/removal", authMiddleware.Auth(commentHandler.RemoveComment))
+}
+
+func ReactionRoutes(mux *http.ServeMux, reactionHandler *handlers.ReactionHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("GET /api/reactions", authMiddleware.Auth(reactionHandler.GetPalette))
+	mux.HandleFunc("PUT /api/posts/{id}/reaction", authMiddleware.Auth(reactionHandler.ReactToPost))
+	mux.HandleFunc("DELETE /api/posts/{id}/reaction", authMiddleware.Auth(reactionHandler.UnreactToPost))
+	mux.HandleFunc("GET /api/posts/{id}/reactions", authMiddleware.Auth(reactionHandler.GetPostReactions))
+	mux.HandleFunc("PUT /api/comments/{id}/reaction", authMiddleware.Auth(reactionHandler.ReactToComment))
+	mux.HandleFunc("DELETE /api/comments/{id}/reaction", authMiddleware.Auth(reactionHandler.UnreactToComment))
+	mux.HandleFunc("GET /api/comments/{id}/reactions", authMiddleware.Auth(reactionHandler.GetCommentReactions))
+}
+
+func NotificationRoutes(mux *http.ServeMux, notificationHandler *handlers.NotificationHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("GET /api/me/notifications", authMiddleware.Auth(notificationHandler.GetNotifications))
+	mux.HandleFunc("GET /api/me/notifications/unread-count", authMiddleware.Auth(notificationHandler.GetUnreadCount))
+	mux.HandleFunc("POST /api/me/notifications/read", authMiddleware.Auth(notificationHandler.MarkAllRead))
+	mux.HandleFunc("POST /api/notifications/{id}/read", authMiddleware.Auth(notificationHandler.MarkRead))
+}
+
+// SearchRoutes take q, lang, hashtag, mention, from and to query parameters
+func SearchRoutes(mux *http.ServeMux, searchHandler *handlers.SearchHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("GET /api/search/posts", authMiddleware.Auth(searchHandler.SearchPosts))
+	mux.HandleFunc("GET /api/search/comments", authMiddleware.Auth(searchHandler.SearchComments))
+}
+
+func MessageRoutes(mux *http.ServeMux, messageHandler *handlers.MessageHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("GET /api/conversations", authMiddleware.Auth(messageHandler.GetConversations))
+	mux.HandleFunc("POST /api/conversations", authMiddleware.Auth(messageHandler.StartConversation))
+	mux.HandleFunc("GET /api/conversations/{id}/messages", authMiddleware.Auth(messageHandler.GetMessages))
+	mux.HandleFunc("POST /api/conversations/{id}/messages", authMiddleware.Auth(messageHandler.SendMessage))
+	mux.HandleFunc("POST /api/conversations/{id}/read", authMiddleware.Auth(messageHandler.MarkRead))
+	mux.HandleFunc("POST /api/conversations/groups", authMiddleware.Auth(messageHandler.CreateGroup))
+	mux.HandleFunc("PUT /api/conversations/{id}", authMiddleware.Auth(messageHandler.RenameGroup))
+	mux.HandleFunc("POST /api/conversations/{id}/leave", authMiddleware.Auth(messageHandler.LeaveGroup))
+	mux.HandleFunc("GET /api/conversations/{id}/members", authMiddleware.Auth(messageHandler.GetMembers))
+	mux.HandleFunc("POST /api/conversations/{id}/members", authMiddleware.Auth(messageHandler.AddMembers))
+	mux.HandleFunc("DELETE /api/conversations/{id}/members/{userId}", authMiddleware.Auth(messageHandler.RemoveMember))
+	mux.HandleFunc("PUT /api/conversations/{id}/members/{userId}/role", authMiddleware.Auth(messageHandler.UpdateMemberRole))
+}
+
+// RealtimeRoutes serves the event stream over WebSocket, or Server-Sent
+// Events as a fallback. Browsers cannot set headers on either, so the token
+// can also be passed as access_token.
+func RealtimeRoutes(mux *http.ServeMux, realtimeHandler *handlers.RealtimeHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("GET /api/ws", authMiddleware.AuthStream(realtimeHandler.Connect))
+	mux.HandleFunc("GET /api/events", authMiddleware.AuthStream(realtimeHandler.Events))
+}
+
+// VideoUploadRoutes implements the tus protocol. Finished uploads are turned
+// into posts with POST /api/posts and a video_upload_id.
+func VideoUploadRoutes(mux *http.ServeMux, uploadHandler *handlers.VideoUploadHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("OPTIONS /api/uploads/videos", uploadHandler.Options)
+	mux.HandleFunc("POST /api/uploads/videos", authMiddleware.Auth(uploadHandler.Create))
+	mux.HandleFunc("HEAD /api/uploads/videos/{id}", authMiddleware.Auth(uploadHandler.Head))
+	mux.HandleFunc("GET /api/uploads/videos/{id}", authMiddleware.Auth(uploadHandler.GetStatus))
+	mux.HandleFunc("PATCH /api/uploads/videos/{id}", authMiddleware.Auth(uploadHandler.Patch))
+	mux.HandleFunc("DELETE /api/uploads/videos/{id}", authMiddleware.Auth(uploadHandler.Delete))
+}
+
+func SuggestionRoutes(mux *http.ServeMux, suggestionHandler *handlers.SuggestionHandler, authMiddleware *middleware.AuthMiddleware) {
+	mux.HandleFunc("GET /api/suggestions/users", authMiddleware.Auth(suggestionHandler.GetUserSuggestions))
+}
+
+// MediaRoutes serves signed media links. They carry their own signature, so
+// they are not behind the auth middleware and work in <img> and <video> tags.
+func MediaRoutes(mux *http.ServeMux, mediaHandler *handlers.MediaHandler) {
+	mux.HandleFunc("GET /api/media/{key...}", mediaHandler.ServeSigned)
 }
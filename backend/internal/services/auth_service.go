@@ -3,6 +3,7 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 
 	"github.com/escuadron-404/red404/backend/internal/dto"
@@ -40,7 +41,12 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*d
 	// Check if user already exists
 	existingUser, _ := s.userRepo.GetByEmail(ctx, req.Email)
 	if existingUser != nil {
-		return nil, fmt.Errorf("user with email %s already exists", req.Email)
+		return nil, conflict("a user with this email already exists")
+	}
+
+	username, err := chooseUsername(ctx, s.userRepo, req.Username, req.Email)
+	if err != nil {
+		return nil, err
 	}
 
 	// Hash password
@@ -52,12 +58,17 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*d
 	// Create user model
 	user := &models.User{
 		Email:    req.Email,
+		Username: username,
 		Password: hashedPassword,
 	}
 
 	// Save to database
 	if err := s.userRepo.Create(ctx, user); err != nil {
-		return nil, fmt.Errorf("failed to create user: %v", err)
+		// Someone else took the username since chooseUsername checked it
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, conflict("username is already taken")
+		}
+		return nil, fmt.Errorf("failed to create user: %w", err)
 	}
 
 	// Generate JWT token
@@ -71,6 +82,7 @@ func (s *authService) Register(ctx context.Context, req dto.RegisterRequest) (*d
 	userResponse := dto.UserResponse{
 		ID:        user.ID,
 		Email:     user.Email,
+		Username:  user.Username,
 		CreatedAt: user.CreatedAt,
 		UpdatedAt: user.UpdatedAt,
 	}
@@ -106,8 +118,9 @@ func (s *authService) Login(ctx context.Context, req dto.LoginRequest) (*dto.Aut
 
 	// Return response
 	userResponse := dto.UserResponse{
-		ID:    user.ID,
-		Email: user.Email,
+		ID:       user.ID,
+		Email:    user.Email,
+		Username: user.Username,
 	}
 
 	return &dto.AuthResponse{
@@ -0,0 +1,105 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+)
+
+type BlockService interface {
+	Block(ctx context.Context, blockerID, targetID int) error
+	Unblock(ctx context.Context, blockerID, targetID int) error
+	GetBlocked(ctx context.Context, blockerID, limit, offset int) ([]dto.UserResponse, int, error)
+	Mute(ctx context.Context, muterID, targetID int) error
+	Unmute(ctx context.Context, muterID, targetID int) error
+	GetMuted(ctx context.Context, muterID, limit, offset int) ([]dto.UserResponse, int, error)
+}
+
+type blockService struct {
+	blockRepo repositories.BlockRepository
+	userRepo  repositories.UserRepository
+}
+
+func NewBlockService(blockRepo repositories.BlockRepository, userRepo repositories.UserRepository) BlockService {
+	return &blockService{
+		blockRepo: blockRepo,
+		userRepo:  userRepo,
+	}
+}
+
+// Block hides both users from each other and removes the follows between
+// them. Blocking twice is not an error.
+func (s *blockService) Block(ctx context.Context, blockerID, targetID int) error {
+	if blockerID == targetID {
+		return invalid("you cannot block yourself")
+	}
+	if _, err := s.userRepo.GetByID(ctx, targetID); err != nil {
+		return notFound("user not found")
+	}
+
+	if err := s.blockRepo.Block(ctx, blockerID, targetID); err != nil {
+		return fmt.Errorf("failed to block user: %w", err)
+	}
+	return nil
+}
+
+func (s *blockService) Unblock(ctx context.Context, blockerID, targetID int) error {
+	removed, err := s.blockRepo.Unblock(ctx, blockerID, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to unblock user: %w", err)
+	}
+	if !removed {
+		return notFound("you have not blocked this user")
+	}
+	return nil
+}
+
+func (s *blockService) GetBlocked(ctx context.Context, blockerID, limit, offset int) ([]dto.UserResponse, int, error) {
+	users, total, err := s.blockRepo.GetBlocked(ctx, blockerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get blocked users from repo: %w", err)
+	}
+	return toUserResponses(users), total, nil
+}
+
+// Mute hides the target's content from the muter's feeds only, the target
+// does not notice anything
+func (s *blockService) Mute(ctx context.Context, muterID, targetID int) error {
+	if muterID == targetID {
+		return invalid("you cannot mute yourself")
+	}
+	if _, err := s.userRepo.GetVisibleByID(ctx, muterID, targetID); err != nil {
+		return notFound("user not found")
+	}
+
+	if err := s.blockRepo.Mute(ctx, muterID, targetID); err != nil {
+		return fmt.Errorf("failed to mute user: %w", err)
+	}
+	return nil
+}
+
+func (s *blockService) Unmute(ctx context.Context, muterID, targetID int) error {
+	removed, err := s.blockRepo.Unmute(ctx, muterID, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to unmute user: %w", err)
+	}
+	if !removed {
+		return notFound("you have not muted this user")
+	}
+	return nil
+}
+
+func (s *blockService) GetMuted(ctx context.Context, muterID, limit, offset int) ([]dto.UserResponse, int, error) {
+	users, total, err := s.blockRepo.GetMuted(ctx, muterID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get muted users from repo: %w", err)
+	}
+
+	userResponses := toUserResponses(users)
+	for i := range userResponses {
+		userResponses[i].IsMuted = true
+	}
+	return userResponses, total, nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+)
+
+// SearchComments runs a full-text search over the comments on posts the
+// viewer can see
+func (s *commentService) SearchComments(ctx context.Context, viewerID int, req dto.SearchRequest,
+	limit, offset int) ([]dto.CommentSearchResult, int, error) {
+	filter, err := toSearchFilter(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits, total, err := s.commentRepo.Search(ctx, viewerID, filter, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to search comments in repo: %w", err)
+	}
+
+	comments := make([]models.CommentWithAuthor, 0, len(hits))
+	for i := range hits {
+		comments = append(comments, hits[i].CommentWithAuthor)
+	}
+	responses, err := s.toCommentResponses(ctx, viewerID, comments)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]dto.CommentSearchResult, 0, len(responses))
+	for i := range responses {
+		results = append(results, dto.CommentSearchResult{
+			CommentResponse: responses[i],
+			Snippet:         highlightSnippet(hits[i].Snippet),
+		})
+	}
+	return results, total, nil
+}
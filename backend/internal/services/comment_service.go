@@ -0,0 +1,390 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+	"github.com/escuadron-404/red404/backend/pkg/realtime"
+)
+
+type CommentService interface {
+	CreateComment(ctx context.Context, authorID, postID int, req dto.CreateCommentRequest) (*dto.CommentResponse, error)
+	GetPostComments(ctx context.Context, viewerID, postID int, sort, cursor string, limit int) ([]dto.CommentResponse, string, error)
+	GetReplies(ctx context.Context, viewerID, commentID int, cursor string, limit int) ([]dto.CommentResponse, string, error)
+	UpdateComment(ctx context.Context, authorID, commentID int, req dto.UpdateCommentRequest) (*dto.CommentResponse, error)
+	DeleteComment(ctx context.Context, authorID, commentID int) error
+	RemoveComment(ctx context.Context, userID, commentID int, req dto.RemoveCommentRequest) error
+	SearchComments(ctx context.Context, viewerID int, req dto.SearchRequest, limit, offset int) ([]dto.CommentSearchResult, int, error)
+}
+
+type commentService struct {
+	commentRepo  repositories.CommentRepository
+	postRepo     repositories.PostRepository
+	userRepo     repositories.UserRepository
+	followRepo   repositories.FollowRepository
+	mentionRepo  repositories.MentionRepository
+	reactionRepo repositories.ReactionRepository
+	events       realtime.Publisher
+}
+
+func NewCommentService(commentRepo repositories.CommentRepository, postRepo repositories.PostRepository,
+	userRepo repositories.UserRepository, followRepo repositories.FollowRepository,
+	mentionRepo repositories.MentionRepository, reactionRepo repositories.ReactionRepository, events realtime.Publisher) CommentService {
+	return &commentService{
+		commentRepo:  commentRepo,
+		postRepo:     postRepo,
+		userRepo:     userRepo,
+		followRepo:   followRepo,
+		mentionRepo:  mentionRepo,
+		reactionRepo: reactionRepo,
+		events:       events,
+	}
+}
+
+// CreateComment comments on a post the author can see. Replies to a reply
+// join the thread of the top level comment, so threads stay one level deep.
+func (s *commentService) CreateComment(ctx context.Context, authorID, postID int, req dto.CreateCommentRequest) (*dto.CommentResponse, error) {
+	if err := s.checkPostVisible(ctx, authorID, postID); err != nil {
+		return nil, err
+	}
+
+	comment := &models.Comment{UserID: authorID, PostID: postID, Text: strings.TrimSpace(req.Text)}
+	if comment.Text == "" {
+		return nil, invalid("a comment needs some text")
+	}
+	if req.ParentID != nil {
+		parent, err := s.getVisibleComment(ctx, authorID, *req.ParentID)
+		if err != nil {
+			return nil, err
+		}
+		if parent.PostID != postID {
+			return nil, invalid("the parent comment belongs to another post")
+		}
+		comment.ParentID = &parent.ID
+		if parent.ParentID != nil {
+			comment.ParentID = parent.ParentID
+		}
+	}
+
+	mentions, err := extractMentions(comment.Text)
+	if err != nil {
+		return nil, err
+	}
+	notified, err := s.commentRepo.Create(ctx, comment, mentions)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create comment: %w", err)
+	}
+	publishMentions(s.events, notified, comment.UserID, comment.PostID, &comment.ID)
+	return s.toCommentResponse(ctx, authorID, comment)
+}
+
+// GetPostComments lists the top level comments of a post, newest first or
+// with sort "top" most popular first
+func (s *commentService) GetPostComments(ctx context.Context, viewerID, postID int, sort, cursor string,
+	limit int) ([]dto.CommentResponse, string, error) {
+	order := models.CommentSort(sort)
+	switch order {
+	case "":
+		order = models.CommentSortNew
+	case models.CommentSortNew, models.CommentSortTop:
+	default:
+		return nil, "", invalid("sort must be one of: new, top")
+	}
+	after, err := commentCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+	if err := s.checkPostVisible(ctx, viewerID, postID); err != nil {
+		return nil, "", err
+	}
+	// The first page fixes the time popularity is counted at for all of them
+	if order == models.CommentSortTop {
+		if after == nil {
+			after = &models.CommentCursor{}
+		}
+		if after.At.IsZero() {
+			after.At = time.Now()
+		}
+	}
+
+	comments, err := s.commentRepo.GetByPost(ctx, viewerID, postID, order, after, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("service failed to get comments from repo: %w", err)
+	}
+	return s.page(ctx, viewerID, comments, limit, func(c *models.CommentWithAuthor) models.CommentCursor {
+		if order == models.CommentSortTop {
+			return models.CommentCursor{Score: c.Score, At: after.At, ID: c.ID}
+		}
+		return models.CommentCursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	})
+}
+
+// GetReplies lists the replies to a top level comment, oldest first
+func (s *commentService) GetReplies(ctx context.Context, viewerID, commentID int, cursor string,
+	limit int) ([]dto.CommentResponse, string, error) {
+	after, err := commentCursor(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	replies, err := s.commentRepo.GetReplies(ctx, viewerID, commentID, after, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("service failed to get replies from repo: %w", err)
+	}
+	return s.page(ctx, viewerID, replies, limit, func(c *models.CommentWithAuthor) models.CommentCursor {
+		return models.CommentCursor{CreatedAt: c.CreatedAt, ID: c.ID}
+	})
+}
+
+// UpdateComment edits the text of a comment. Only its author can edit it.
+func (s *commentService) UpdateComment(ctx context.Context, authorID, commentID int, req dto.UpdateCommentRequest) (*dto.CommentResponse, error) {
+	comment, err := s.getComment(ctx, commentID)
+	if err != nil {
+		return nil, err
+	}
+	if comment.UserID != authorID {
+		return nil, forbidden("you can only edit your own comments")
+	}
+
+	comment.Text = strings.TrimSpace(req.Text)
+	if comment.Text == "" {
+		return nil, invalid("a comment needs some text")
+	}
+	mentions, err := extractMentions(comment.Text)
+	if err != nil {
+		return nil, err
+	}
+	comment.UpdatedAt = time.Now()
+
+	notified, err := s.commentRepo.Update(ctx, comment, mentions)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("comment not found")
+		}
+		return nil, fmt.Errorf("failed to update comment: %w", err)
+	}
+	publishMentions(s.events, notified, comment.UserID, comment.PostID, &comment.ID)
+	return s.toCommentResponse(ctx, authorID, comment)
+}
+
+// DeleteComment soft deletes a comment. Only its author can delete it, others
+// go through RemoveComment.
+func (s *commentService) DeleteComment(ctx context.Context, authorID, commentID int) error {
+	comment, err := s.getComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.UserID != authorID {
+		return forbidden("you can only delete your own comments")
+	}
+
+	comment.DeletedBy = &authorID
+	return s.softDelete(ctx, comment)
+}
+
+// RemoveComment soft deletes someone else's comment and records why. The
+// author of the post and moderators can remove comments. Removing one's own
+// comment is a plain deletion, so it is not recorded as a removal.
+func (s *commentService) RemoveComment(ctx context.Context, userID, commentID int, req dto.RemoveCommentRequest) error {
+	comment, err := s.getComment(ctx, commentID)
+	if err != nil {
+		return err
+	}
+	if comment.UserID == userID {
+		comment.DeletedBy = &userID
+		return s.softDelete(ctx, comment)
+	}
+	if err := s.checkCanModerate(ctx, userID, comment); err != nil {
+		return err
+	}
+
+	reason := strings.TrimSpace(req.Reason)
+	if reason == "" {
+		return invalid("a removal needs a reason")
+	}
+	comment.DeletedBy = &userID
+	comment.DeletionReason = &reason
+	return s.softDelete(ctx, comment)
+}
+
+// checkCanModerate makes sure userID wrote the post the comment is on or is a
+// moderator
+func (s *commentService) checkCanModerate(ctx context.Context, userID int, comment *models.Comment) error {
+	post, err := s.postRepo.GetByID(ctx, comment.PostID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return fmt.Errorf("failed to get post: %w", err)
+	}
+	if post != nil && post.UserID == userID {
+		return nil
+	}
+
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Role != models.RoleModerator && user.Role != models.RoleAdmin {
+		return forbidden("only the post author and moderators can remove comments")
+	}
+	return nil
+}
+
+func (s *commentService) softDelete(ctx context.Context, comment *models.Comment) error {
+	if err := s.commentRepo.SoftDelete(ctx, comment); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("comment not found")
+		}
+		return fmt.Errorf("failed to delete comment: %w", err)
+	}
+	return nil
+}
+
+func (s *commentService) checkPostVisible(ctx context.Context, viewerID, postID int) error {
+	if _, err := s.postRepo.GetVisibleByID(ctx, viewerID, postID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("post not found")
+		}
+		return fmt.Errorf("failed to get post: %w", err)
+	}
+	return nil
+}
+
+func (s *commentService) getVisibleComment(ctx context.Context, viewerID, commentID int) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetVisibleByID(ctx, viewerID, commentID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("comment not found")
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	return comment, nil
+}
+
+func (s *commentService) getComment(ctx context.Context, commentID int) (*models.Comment, error) {
+	comment, err := s.commentRepo.GetByID(ctx, commentID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("comment not found")
+		}
+		return nil, fmt.Errorf("failed to get comment: %w", err)
+	}
+	return comment, nil
+}
+
+// commentCursor decodes the cursor query parameter of comment lists
+func commentCursor(cursor string) (*models.CommentCursor, error) {
+	var after models.CommentCursor
+	ok, err := decodeCursor(cursor, &after)
+	if err != nil || !ok {
+		return nil, err
+	}
+	if after.ID <= 0 {
+		return nil, invalid("invalid cursor")
+	}
+	return &after, nil
+}
+
+// page turns up to limit+1 comments into a page of responses and the cursor
+// of the next page, which is empty when the extra comment was not found
+func (s *commentService) page(ctx context.Context, viewerID int, comments []models.CommentWithAuthor, limit int,
+	position func(*models.CommentWithAuthor) models.CommentCursor) ([]dto.CommentResponse, string, error) {
+	next := ""
+	if len(comments) > limit {
+		comments = comments[:limit]
+		cursor, err := encodeCursor(position(&comments[limit-1]))
+		if err != nil {
+			return nil, "", err
+		}
+		next = cursor
+	}
+
+	responses, err := s.toCommentResponses(ctx, viewerID, comments)
+	if err != nil {
+		return nil, "", err
+	}
+	return responses, next, nil
+}
+
+// toCommentResponse builds the response for a comment just written by the
+// viewer
+func (s *commentService) toCommentResponse(ctx context.Context, viewerID int, comment *models.Comment) (*dto.CommentResponse, error) {
+	author, err := s.userRepo.GetByID(ctx, comment.UserID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment author: %w", err)
+	}
+	responses, err := s.toCommentResponses(ctx, viewerID, []models.CommentWithAuthor{{Comment: *comment, Author: *author}})
+	if err != nil {
+		return nil, err
+	}
+	return &responses[0], nil
+}
+
+func (s *commentService) toCommentResponses(ctx context.Context, viewerID int, comments []models.CommentWithAuthor) ([]dto.CommentResponse, error) {
+	authors := make([]dto.UserResponse, 0, len(comments))
+	ids := make([]int, 0, len(comments))
+	for i := range comments {
+		authors = append(authors, *toUserResponse(&comments[i].Author))
+		ids = append(ids, comments[i].ID)
+	}
+	if err := attachRelationships(ctx, s.followRepo, viewerID, authors); err != nil {
+		return nil, err
+	}
+
+	mentions, err := s.mentionRepo.GetByComments(ctx, viewerID, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment mentions: %w", err)
+	}
+	reactions, err := s.reactionRepo.GetCounts(ctx, models.ReactionOnComment, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get comment reactions: %w", err)
+	}
+	myReactions, err := s.reactionRepo.GetUserReactions(ctx, viewerID, models.ReactionOnComment, ids)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get viewer reactions: %w", err)
+	}
+
+	responses := make([]dto.CommentResponse, 0, len(comments))
+	for i := range comments {
+		c := &comments[i].Comment
+		response := dto.CommentResponse{
+			ID:         c.ID,
+			PostID:     c.PostID,
+			ParentID:   c.ParentID,
+			Mentions:   []dto.MentionEntity{},
+			Deleted:    c.Deleted,
+			ReplyCount: c.ReplyCount,
+			Reactions:  toReactionCounts(reactions[c.ID]),
+			MyReaction: myReactions[c.ID],
+			CreatedAt:  c.CreatedAt,
+			UpdatedAt:  c.UpdatedAt,
+		}
+		// Deleted comments only hold the place of their replies
+		if c.Deleted {
+			response.Deletion, response.DeletionReason = commentDeletion(viewerID, c)
+		} else {
+			response.Author = &authors[i]
+			response.Text = c.Text
+			response.Mentions = toMentionEntities(mentions[c.ID])
+		}
+		responses = append(responses, response)
+	}
+	return responses, nil
+}
+
+// commentDeletion describes how a deleted comment went away. The reason of a
+// removal is only told to the author of the comment and to the remover.
+func commentDeletion(viewerID int, comment *models.Comment) (kind, reason string) {
+	if comment.DeletedBy != nil && *comment.DeletedBy == comment.UserID {
+		return string(models.CommentDeletedBySelf), ""
+	}
+	if comment.DeletionReason != nil &&
+		(viewerID == comment.UserID || (comment.DeletedBy != nil && viewerID == *comment.DeletedBy)) {
+		reason = *comment.DeletionReason
+	}
+	return string(models.CommentRemovedModeration), reason
+}
@@ -0,0 +1,32 @@
+package services
+
+import (
+	"encoding/base64"
+	"encoding/json"
+)
+
+// encodeCursor turns the position of the last item of a page into the opaque
+// string clients send back to get the next page
+func encodeCursor(position any) (string, error) {
+	data, err := json.Marshal(position)
+	if err != nil {
+		return "", err
+	}
+	return base64.RawURLEncoding.EncodeToString(data), nil
+}
+
+// decodeCursor reads a cursor made by encodeCursor into position. An empty
+// cursor leaves position untouched and reports false.
+func decodeCursor(cursor string, position any) (bool, error) {
+	if cursor == "" {
+		return false, nil
+	}
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return false, invalid("invalid cursor")
+	}
+	if err := json.Unmarshal(data, position); err != nil {
+		return false, invalid("invalid cursor")
+	}
+	return true, nil
+}
@@ -0,0 +1,51 @@
+package services
+
+import "errors"
+
+// Error kinds that handlers map to HTTP status codes
+var (
+	ErrNotFound  = errors.New("not found")
+	ErrForbidden = errors.New("forbidden")
+	ErrConflict  = errors.New("conflict")
+	ErrInvalid   = errors.New("invalid request")
+	ErrTooLarge  = errors.New("too large")
+	ErrGone      = errors.New("gone")
+)
+
+// Error is a business error whose message is safe to show to the client
+type Error struct {
+	Kind    error
+	Message string
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Kind
+}
+
+func notFound(message string) error {
+	return &Error{Kind: ErrNotFound, Message: message}
+}
+
+func forbidden(message string) error {
+	return &Error{Kind: ErrForbidden, Message: message}
+}
+
+func conflict(message string) error {
+	return &Error{Kind: ErrConflict, Message: message}
+}
+
+func invalid(message string) error {
+	return &Error{Kind: ErrInvalid, Message: message}
+}
+
+func tooLarge(message string) error {
+	return &Error{Kind: ErrTooLarge, Message: message}
+}
+
+func gone(message string) error {
+	return &Error{Kind: ErrGone, Message: message}
+}
@@ -0,0 +1,35 @@
+package services
+
+import (
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/pkg/realtime"
+)
+
+// Types of the realtime events pushed to users
+const (
+	EventNotificationCreated = "notification.created"
+	EventFollowCreated       = "follow.created"
+	EventFollowRequested     = "follow.requested"
+	EventFollowAccepted      = "follow.accepted"
+	EventMessageCreated      = "message.created"
+	EventTyping              = "typing"
+	EventPostLikes           = "post.likes"
+)
+
+// publishMentions tells the users mentioned by actorID about their new
+// notification
+func publishMentions(events realtime.Publisher, userIDs []int, actorID, postID int, commentID *int) {
+	if len(userIDs) == 0 {
+		return
+	}
+	events.Publish(userIDs, realtime.Event{
+		Type: EventNotificationCreated,
+		Data: dto.NotificationEvent{
+			Type:      string(models.NotificationMention),
+			ActorID:   actorID,
+			PostID:    postID,
+			CommentID: commentID,
+		},
+	})
+}
@@ -0,0 +1,73 @@
+package services
+
+import (
+	"context"
+	"log"
+
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+)
+
+// fanoutBatchSize caps the jobs one run of the fan-out worker processes, so
+// a long queue is drained over several ticks instead of one long run
+const fanoutBatchSize = 500
+
+// FeedOptions configures how home feeds are built
+type FeedOptions struct {
+	// Fanout pushes new posts into per-follower timelines in the background
+	// instead of gathering the feed from every followed account on read
+	Fanout bool
+	Limits models.FanoutLimits
+
+	Ranking RankingOptions
+}
+
+type FanoutService interface {
+	ProcessJobs(ctx context.Context) error
+}
+
+type fanoutService struct {
+	timelineRepo repositories.TimelineRepository
+	feed         FeedOptions
+}
+
+func NewFanoutService(timelineRepo repositories.TimelineRepository, feed FeedOptions) FanoutService {
+	return &fanoutService{
+		timelineRepo: timelineRepo,
+		feed:         feed,
+	}
+}
+
+// ProcessJobs drains due fan-out jobs. Failing jobs are logged and retried
+// later by the repository, so one bad job does not hold up the others.
+func (s *fanoutService) ProcessJobs(ctx context.Context) error {
+	for range fanoutBatchSize {
+		if ctx.Err() != nil {
+			return nil
+		}
+		processed, err := s.timelineRepo.ProcessNext(ctx, s.feed.Limits)
+		if err != nil {
+			if !processed {
+				return err
+			}
+			log.Printf("Fan-out job failed: %v", err)
+			continue
+		}
+		if !processed {
+			return nil
+		}
+	}
+	return nil
+}
+
+// enqueueFanout queues a timeline job when fan-out is enabled. The post or
+// follow it is for already exists, so a failure is logged rather than
+// failing the request; the feed of affected users only misses entries.
+func enqueueFanout(feed FeedOptions, enqueue func() error) {
+	if !feed.Fanout {
+		return
+	}
+	if err := enqueue(); err != nil {
+		log.Printf("Failed to enqueue fan-out job: %v", err)
+	}
+}
@@ -0,0 +1,171 @@
+package services
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+)
+
+// Feed modes accepted by GetFeed
+const (
+	FeedModeChronological = "chronological"
+	FeedModeRanked        = "ranked"
+)
+
+// RankingOptions configures the ranked feed
+type RankingOptions struct {
+	// Window is how far back candidate posts and the viewer's interactions
+	// are looked for
+	Window        time.Duration
+	MaxCandidates int
+	// HalfLife is the age at which the recency signal of a post has halved
+	HalfLife time.Duration
+	Weights  RankingWeights
+}
+
+// RankingWeights multiply each signal of a post before they are summed into
+// its score. A zero weight turns the signal off.
+type RankingWeights struct {
+	Recency  float64
+	Likes    float64
+	Comments float64
+	Saves    float64
+	Affinity float64
+	Hashtags float64
+}
+
+// rankedCursor pins the time the ranking was computed at, so later pages use
+// the same candidates and decay, and where the next page starts
+type rankedCursor struct {
+	AsOf   time.Time `json:"as_of"`
+	Offset int       `json:"o"`
+}
+
+type rankedPost struct {
+	candidate   *models.RankingCandidate
+	explanation dto.RankingExplanation
+}
+
+// getRankedFeed scores the candidate posts of the viewer and returns a page
+// of them, best first. With debug, which only admins may ask for, every post
+// carries the breakdown of its score.
+func (s *postService) getRankedFeed(ctx context.Context, viewerID int, cursor string, limit int,
+	debug bool) ([]dto.PostResponse, string, error) {
+	position := rankedCursor{AsOf: time.Now()}
+	if _, err := decodeCursor(cursor, &position); err != nil {
+		return nil, "", err
+	}
+	if position.Offset < 0 || position.AsOf.IsZero() {
+		return nil, "", invalid("invalid cursor")
+	}
+	if debug {
+		if err := s.checkAdmin(ctx, viewerID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	options := s.feed.Ranking
+	candidates, err := s.rankingRepo.GetCandidates(ctx, viewerID, position.AsOf.Add(-options.Window), position.AsOf,
+		options.MaxCandidates)
+	if err != nil {
+		return nil, "", fmt.Errorf("service failed to get ranking candidates from repo: %w", err)
+	}
+	ranked := rankCandidates(candidates, position.AsOf, &options)
+
+	start := min(position.Offset, len(ranked))
+	end := min(start+limit, len(ranked))
+	next := ""
+	if end < len(ranked) {
+		if next, err = encodeCursor(rankedCursor{AsOf: position.AsOf, Offset: end}); err != nil {
+			return nil, "", err
+		}
+	}
+
+	page := ranked[start:end]
+	posts := make([]models.PostWithAuthor, 0, len(page))
+	for _, post := range page {
+		posts = append(posts, post.candidate.PostWithAuthor)
+	}
+	responses, err := s.toPostResponses(ctx, viewerID, posts)
+	if err != nil {
+		return nil, "", err
+	}
+	if debug {
+		for i := range responses {
+			responses[i].Ranking = &page[i].explanation
+		}
+	}
+	return responses, next, nil
+}
+
+func (s *postService) checkAdmin(ctx context.Context, userID int) error {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		return fmt.Errorf("failed to get user: %w", err)
+	}
+	if user.Role != models.RoleAdmin {
+		return forbidden("only admins can see how the feed is ranked")
+	}
+	return nil
+}
+
+// rankCandidates scores every candidate and sorts them best first, newest
+// first among equal scores
+func rankCandidates(candidates []models.RankingCandidate, asOf time.Time, options *RankingOptions) []rankedPost {
+	ranked := make([]rankedPost, 0, len(candidates))
+	for i := range candidates {
+		ranked = append(ranked, rankedPost{
+			candidate:   &candidates[i],
+			explanation: scoreCandidate(&candidates[i], asOf, options),
+		})
+	}
+
+	slices.SortFunc(ranked, func(a, b rankedPost) int {
+		if c := cmp.Compare(b.explanation.Score, a.explanation.Score); c != 0 {
+			return c
+		}
+		if c := b.candidate.CreatedAt.Compare(a.candidate.CreatedAt); c != 0 {
+			return c
+		}
+		return cmp.Compare(b.candidate.ID, a.candidate.ID)
+	})
+	return ranked
+}
+
+// scoreCandidate sums the weighted signals of a post. Recency decays
+// exponentially with age, and counts are damped with log(1+n) so a few very
+// popular posts do not drown everything else.
+func scoreCandidate(candidate *models.RankingCandidate, asOf time.Time, options *RankingOptions) dto.RankingExplanation {
+	age := max(asOf.Sub(candidate.CreatedAt).Hours(), 0)
+	weights := options.Weights
+	signals := []struct {
+		name   string
+		value  float64
+		weight float64
+	}{
+		{"recency", math.Exp2(-age / options.HalfLife.Hours()), weights.Recency},
+		{"likes", math.Log1p(float64(candidate.LikeCount)), weights.Likes},
+		{"comments", math.Log1p(float64(candidate.CommentCount)), weights.Comments},
+		{"saves", math.Log1p(float64(candidate.SaveCount)), weights.Saves},
+		{"affinity", math.Log1p(float64(candidate.AuthorInteractions)), weights.Affinity},
+		{"hashtags", math.Log1p(float64(candidate.HashtagInteractions)), weights.Hashtags},
+	}
+
+	explanation := dto.RankingExplanation{Signals: make(map[string]dto.RankingSignal, len(signals))}
+	for _, signal := range signals {
+		contribution := signal.value * signal.weight
+		explanation.Score += contribution
+		explanation.Signals[signal.name] = dto.RankingSignal{
+			Value:        signal.value,
+			Weight:       signal.weight,
+			Contribution: contribution,
+		}
+	}
+	return explanation
+}
@@ -0,0 +1,299 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+	"github.com/escuadron-404/red404/backend/pkg/realtime"
+)
+
+const (
+	FollowStatusFollowing = "following"
+	FollowStatusRequested = "requested"
+)
+
+type FollowService interface {
+	Follow(ctx context.Context, followerID, targetID int) (*dto.FollowResponse, error)
+	Unfollow(ctx context.Context, followerID, targetID int) error
+	GetFollowers(ctx context.Context, viewerID, userID, limit, offset int) ([]dto.UserResponse, int, error)
+	GetFollowing(ctx context.Context, viewerID, userID, limit, offset int) ([]dto.UserResponse, int, error)
+	RequestFollow(ctx context.Context, senderID, targetID int) (*dto.FollowRequestResponse, error)
+	GetIncomingRequests(ctx context.Context, receiverID, limit, offset int) ([]dto.FollowRequestResponse, int, error)
+	AcceptRequest(ctx context.Context, receiverID, requestID int) error
+	RejectRequest(ctx context.Context, receiverID, requestID int) error
+}
+
+type followService struct {
+	followRepo   repositories.FollowRepository
+	userRepo     repositories.UserRepository
+	timelineRepo repositories.TimelineRepository
+	events       realtime.Publisher
+	feed         FeedOptions
+}
+
+func NewFollowService(followRepo repositories.FollowRepository, userRepo repositories.UserRepository,
+	timelineRepo repositories.TimelineRepository, events realtime.Publisher, feed FeedOptions) FollowService {
+	return &followService{
+		followRepo:   followRepo,
+		userRepo:     userRepo,
+		timelineRepo: timelineRepo,
+		events:       events,
+		feed:         feed,
+	}
+}
+
+// Follow follows public accounts right away and sends a follow request to
+// private ones. Following someone twice is not an error.
+func (s *followService) Follow(ctx context.Context, followerID, targetID int) (*dto.FollowResponse, error) {
+	if followerID == targetID {
+		return nil, invalid("you cannot follow yourself")
+	}
+
+	// Blocked users in either direction are invisible, so they look missing
+	target, err := s.userRepo.GetVisibleByID(ctx, followerID, targetID)
+	if err != nil {
+		return nil, notFound("user not found")
+	}
+
+	if target.IsPrivate {
+		following, err := s.followRepo.IsFollowing(ctx, followerID, targetID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check follow: %w", err)
+		}
+		if following {
+			return &dto.FollowResponse{Status: FollowStatusFollowing}, nil
+		}
+
+		_, err = s.RequestFollow(ctx, followerID, targetID)
+		if err != nil && !errors.Is(err, ErrConflict) {
+			return nil, err
+		}
+		return &dto.FollowResponse{Status: FollowStatusRequested}, nil
+	}
+
+	created, err := s.followRepo.Follow(ctx, followerID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to follow user: %w", err)
+	}
+	if created {
+		enqueueFanout(s.feed, func() error { return s.timelineRepo.EnqueueFollow(ctx, followerID, targetID) })
+		s.publishFollow(ctx, EventFollowCreated, targetID, followerID)
+	}
+	return &dto.FollowResponse{Status: FollowStatusFollowing}, nil
+}
+
+// Unfollow removes the follow, or withdraws the pending request if the target
+// has not answered yet
+func (s *followService) Unfollow(ctx context.Context, followerID, targetID int) error {
+	removed, err := s.followRepo.Unfollow(ctx, followerID, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to unfollow user: %w", err)
+	}
+	if removed {
+		return nil
+	}
+
+	canceled, err := s.followRepo.CancelRequest(ctx, followerID, targetID)
+	if err != nil {
+		return fmt.Errorf("failed to cancel follow request: %w", err)
+	}
+	if !canceled {
+		return notFound("you do not follow this user")
+	}
+	return nil
+}
+
+func (s *followService) GetFollowers(ctx context.Context, viewerID, userID, limit, offset int) ([]dto.UserResponse, int, error) {
+	if err := s.checkCanSeeConnections(ctx, viewerID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	users, total, err := s.followRepo.GetFollowers(ctx, viewerID, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get followers from repo: %w", err)
+	}
+	return s.toUserResponses(ctx, viewerID, users, total)
+}
+
+func (s *followService) GetFollowing(ctx context.Context, viewerID, userID, limit, offset int) ([]dto.UserResponse, int, error) {
+	if err := s.checkCanSeeConnections(ctx, viewerID, userID); err != nil {
+		return nil, 0, err
+	}
+
+	users, total, err := s.followRepo.GetFollowing(ctx, viewerID, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get following from repo: %w", err)
+	}
+	return s.toUserResponses(ctx, viewerID, users, total)
+}
+
+func (s *followService) toUserResponses(ctx context.Context, viewerID int, users []models.User, total int) ([]dto.UserResponse, int, error) {
+	userResponses := toUserResponses(users)
+	if err := attachRelationships(ctx, s.followRepo, viewerID, userResponses); err != nil {
+		return nil, 0, err
+	}
+	return userResponses, total, nil
+}
+
+// checkCanSeeConnections hides the follower lists of private accounts from
+// everyone but the owner and approved followers
+func (s *followService) checkCanSeeConnections(ctx context.Context, viewerID, userID int) error {
+	user, err := s.userRepo.GetVisibleByID(ctx, viewerID, userID)
+	if err != nil {
+		return notFound("user not found")
+	}
+	if !user.IsPrivate || viewerID == userID {
+		return nil
+	}
+
+	following, err := s.followRepo.IsFollowing(ctx, viewerID, userID)
+	if err != nil {
+		return fmt.Errorf("failed to check follow: %w", err)
+	}
+	if !following {
+		return forbidden("this account is private")
+	}
+	return nil
+}
+
+// RequestFollow asks a private account for permission to follow it
+func (s *followService) RequestFollow(ctx context.Context, senderID, targetID int) (*dto.FollowRequestResponse, error) {
+	if senderID == targetID {
+		return nil, invalid("you cannot follow yourself")
+	}
+
+	target, err := s.userRepo.GetVisibleByID(ctx, senderID, targetID)
+	if err != nil {
+		return nil, notFound("user not found")
+	}
+	if !target.IsPrivate {
+		return nil, invalid("this account is public, follow it directly")
+	}
+
+	following, err := s.followRepo.IsFollowing(ctx, senderID, targetID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check follow: %w", err)
+	}
+	if following {
+		return nil, conflict("you already follow this user")
+	}
+
+	if _, err := s.followRepo.GetPendingRequest(ctx, senderID, targetID); err == nil {
+		return nil, conflict("a follow request is already pending")
+	} else if !errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("failed to check pending follow request: %w", err)
+	}
+
+	sender, err := s.userRepo.GetByID(ctx, senderID)
+	if err != nil {
+		return nil, notFound("user not found")
+	}
+
+	request := &models.FollowRequest{
+		SenderID:   senderID,
+		ReceiverID: targetID,
+	}
+	if err := s.followRepo.CreateRequest(ctx, request); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, conflict("a follow request is already pending")
+		}
+		return nil, fmt.Errorf("failed to create follow request: %w", err)
+	}
+
+	response := &dto.FollowRequestResponse{
+		ID:        request.ID,
+		Sender:    *toUserResponse(sender),
+		Status:    string(request.Status),
+		CreatedAt: request.CreatedAt,
+	}
+	s.events.Publish([]int{targetID}, realtime.Event{Type: EventFollowRequested, Data: response})
+	return response, nil
+}
+
+func (s *followService) GetIncomingRequests(ctx context.Context, receiverID, limit, offset int) ([]dto.FollowRequestResponse, int, error) {
+	requests, total, err := s.followRepo.GetIncomingRequests(ctx, receiverID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get follow requests from repo: %w", err)
+	}
+
+	responses := make([]dto.FollowRequestResponse, 0, len(requests))
+	for i := range requests {
+		responses = append(responses, dto.FollowRequestResponse{
+			ID:        requests[i].ID,
+			Sender:    *toUserResponse(&requests[i].Sender),
+			Status:    string(requests[i].Status),
+			CreatedAt: requests[i].CreatedAt,
+		})
+	}
+
+	return responses, total, nil
+}
+
+func (s *followService) AcceptRequest(ctx context.Context, receiverID, requestID int) error {
+	request, err := s.checkPendingFor(ctx, receiverID, requestID)
+	if err != nil {
+		return err
+	}
+
+	if err := s.followRepo.AcceptRequest(ctx, requestID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("follow request not found")
+		}
+		return fmt.Errorf("failed to accept follow request: %w", err)
+	}
+	enqueueFanout(s.feed, func() error { return s.timelineRepo.EnqueueFollow(ctx, request.SenderID, receiverID) })
+	s.publishFollow(ctx, EventFollowAccepted, request.SenderID, receiverID)
+	return nil
+}
+
+// publishFollow sends a follow event about userID to recipientID
+func (s *followService) publishFollow(ctx context.Context, eventType string, recipientID, userID int) {
+	user, err := s.userRepo.GetByID(ctx, userID)
+	if err != nil {
+		log.Printf("Failed to get user %d for %s event: %v", userID, eventType, err)
+		return
+	}
+	s.events.Publish([]int{recipientID}, realtime.Event{
+		Type: eventType,
+		Data: dto.FollowEvent{User: *toUserResponse(user)},
+	})
+}
+
+func (s *followService) RejectRequest(ctx context.Context, receiverID, requestID int) error {
+	if _, err := s.checkPendingFor(ctx, receiverID, requestID); err != nil {
+		return err
+	}
+
+	if err := s.followRepo.RejectRequest(ctx, requestID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("follow request not found")
+		}
+		return fmt.Errorf("failed to reject follow request: %w", err)
+	}
+	return nil
+}
+
+// checkPendingFor makes sure the request exists, is still pending and was
+// sent to receiverID. Requests addressed to someone else are reported as
+// missing so their ids cannot be probed.
+func (s *followService) checkPendingFor(ctx context.Context, receiverID, requestID int) (*models.FollowRequest, error) {
+	request, err := s.followRepo.GetRequestByID(ctx, requestID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("follow request not found")
+		}
+		return nil, fmt.Errorf("failed to get follow request: %w", err)
+	}
+	if request.ReceiverID != receiverID {
+		return nil, notFound("follow request not found")
+	}
+	if request.Status != models.FollowRequestPending {
+		return nil, conflict(fmt.Sprintf("follow request was already %s", request.Status))
+	}
+	return request, nil
+}
@@ -0,0 +1,319 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+)
+
+// maxGroupMembers caps the size of a group, owner included
+const maxGroupMembers = 50
+
+// CreateGroup starts a group owned by the user with the given members. Every
+// member must be someone the user could start a conversation with.
+func (s *messageService) CreateGroup(ctx context.Context, userID int, req dto.CreateGroupRequest) (*dto.ConversationResponse, error) {
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return nil, invalid("a group needs a title")
+	}
+
+	memberIDs := uniqueMemberIDs(userID, req.UserIDs)
+	if len(memberIDs) == 0 {
+		return nil, invalid("a group needs at least one other member")
+	}
+	if len(memberIDs)+1 > maxGroupMembers {
+		return nil, invalid(fmt.Sprintf("a group can have at most %d members", maxGroupMembers))
+	}
+	if err := s.checkCanAdd(ctx, userID, memberIDs); err != nil {
+		return nil, err
+	}
+
+	conversation := &models.Conversation{Title: &title, OwnerID: &userID}
+	if err := s.messageRepo.CreateGroup(ctx, conversation, memberIDs); err != nil {
+		return nil, fmt.Errorf("failed to create group: %w", err)
+	}
+	return &dto.ConversationResponse{
+		ID:             conversation.ID,
+		Kind:           string(conversation.Kind),
+		Title:          title,
+		MemberCount:    len(memberIDs) + 1,
+		MyRole:         string(models.MemberRoleOwner),
+		LastActivityAt: conversation.LastActivityAt,
+		CreatedAt:      conversation.CreatedAt,
+	}, nil
+}
+
+// RenameGroup changes the title of a group. Only owners and admins can do it.
+func (s *messageService) RenameGroup(ctx context.Context, userID, conversationID int, req dto.UpdateGroupRequest) error {
+	member, err := s.getGroupMember(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+	if !canManageGroup(member.Role) {
+		return forbidden("only group admins can rename the group")
+	}
+
+	title := strings.TrimSpace(req.Title)
+	if title == "" {
+		return invalid("a group needs a title")
+	}
+	if err := s.messageRepo.Rename(ctx, conversationID, userID, title); err != nil {
+		return fmt.Errorf("failed to rename group: %w", err)
+	}
+	return nil
+}
+
+// GetMembers lists the members of a conversation with their roles and read
+// positions
+func (s *messageService) GetMembers(ctx context.Context, userID, conversationID int) ([]dto.ConversationMemberResponse, error) {
+	if _, err := s.getConversation(ctx, userID, conversationID); err != nil {
+		return nil, err
+	}
+	return s.members(ctx, userID, conversationID)
+}
+
+// AddMembers adds users to a group. Only owners and admins can add members,
+// and only people they could start a conversation with.
+func (s *messageService) AddMembers(ctx context.Context, userID, conversationID int,
+	req dto.AddMembersRequest) ([]dto.ConversationMemberResponse, error) {
+	member, err := s.getGroupMember(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if !canManageGroup(member.Role) {
+		return nil, forbidden("only group admins can add members")
+	}
+
+	current, err := s.messageRepo.GetMembers(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get group members: %w", err)
+	}
+	isMember := make(map[int]bool, len(current))
+	for i := range current {
+		isMember[current[i].UserID] = true
+	}
+	var newIDs []int
+	for _, id := range uniqueMemberIDs(userID, req.UserIDs) {
+		if !isMember[id] {
+			newIDs = append(newIDs, id)
+		}
+	}
+	if len(current)+len(newIDs) > maxGroupMembers {
+		return nil, invalid(fmt.Sprintf("a group can have at most %d members", maxGroupMembers))
+	}
+
+	if len(newIDs) > 0 {
+		if err := s.checkCanAdd(ctx, userID, newIDs); err != nil {
+			return nil, err
+		}
+		if err := s.messageRepo.AddMembers(ctx, conversationID, userID, newIDs); err != nil {
+			return nil, fmt.Errorf("failed to add group members: %w", err)
+		}
+	}
+	return s.members(ctx, userID, conversationID)
+}
+
+// RemoveMember takes someone out of a group. The owner can remove anyone,
+// admins can only remove members who are not admins.
+func (s *messageService) RemoveMember(ctx context.Context, userID, conversationID, memberID int) error {
+	if memberID == userID {
+		return invalid("leave the group instead of removing yourself")
+	}
+	member, err := s.getGroupMember(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+	target, err := s.getMember(ctx, conversationID, memberID)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case member.Role == models.MemberRoleOwner:
+	case member.Role == models.MemberRoleAdmin && target.Role == models.MemberRoleMember:
+	default:
+		return forbidden("you cannot remove this member")
+	}
+
+	if err := s.messageRepo.RemoveMember(ctx, conversationID, userID, memberID, models.EventMemberRemoved, nil); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("member not found")
+		}
+		return fmt.Errorf("failed to remove group member: %w", err)
+	}
+	return nil
+}
+
+// LeaveGroup takes the user out of a group. When the owner leaves, the
+// longest standing admin takes over, or the longest standing member when
+// there are no admins.
+func (s *messageService) LeaveGroup(ctx context.Context, userID, conversationID int) error {
+	member, err := s.getGroupMember(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+
+	var successorID *int
+	if member.Role == models.MemberRoleOwner {
+		members, err := s.messageRepo.GetMembers(ctx, conversationID)
+		if err != nil {
+			return fmt.Errorf("failed to get group members: %w", err)
+		}
+		successorID = pickSuccessor(userID, members)
+	}
+
+	if err := s.messageRepo.RemoveMember(ctx, conversationID, userID, userID, models.EventMemberLeft, successorID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("conversation not found")
+		}
+		return fmt.Errorf("failed to leave group: %w", err)
+	}
+	return nil
+}
+
+// UpdateMemberRole promotes a member to admin, demotes an admin, or hands the
+// group over to another member. Only the owner can change roles.
+func (s *messageService) UpdateMemberRole(ctx context.Context, userID, conversationID, memberID int,
+	req dto.UpdateMemberRoleRequest) error {
+	if memberID == userID {
+		return invalid("you cannot change your own role")
+	}
+	member, err := s.getGroupMember(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+	if member.Role != models.MemberRoleOwner {
+		return forbidden("only the group owner can change roles")
+	}
+	target, err := s.getMember(ctx, conversationID, memberID)
+	if err != nil {
+		return err
+	}
+
+	role := models.MemberRole(req.Role)
+	if target.Role == role {
+		return nil
+	}
+
+	switch role {
+	case models.MemberRoleOwner:
+		err = s.messageRepo.TransferOwnership(ctx, conversationID, userID, memberID)
+	case models.MemberRoleAdmin:
+		err = s.messageRepo.SetRole(ctx, conversationID, userID, memberID, role, models.EventAdminAdded)
+	default:
+		err = s.messageRepo.SetRole(ctx, conversationID, userID, memberID, role, models.EventAdminRemoved)
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("member not found")
+		}
+		return fmt.Errorf("failed to update member role: %w", err)
+	}
+	return nil
+}
+
+// getGroupMember returns the membership of the user in a group they belong
+// to
+func (s *messageService) getGroupMember(ctx context.Context, userID, conversationID int) (*models.ConversationMember, error) {
+	conversation, err := s.getConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+	if conversation.Kind != models.ConversationGroup {
+		return nil, invalid("this is not a group conversation")
+	}
+	return s.getMember(ctx, conversationID, userID)
+}
+
+func (s *messageService) getMember(ctx context.Context, conversationID, userID int) (*models.ConversationMember, error) {
+	member, err := s.messageRepo.GetMember(ctx, conversationID, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("member not found")
+		}
+		return nil, fmt.Errorf("failed to get group member: %w", err)
+	}
+	return member, nil
+}
+
+// checkCanAdd makes sure every user exists, is not blocked in either
+// direction and accepts messages from the user adding them
+func (s *messageService) checkCanAdd(ctx context.Context, userID int, memberIDs []int) error {
+	for _, id := range memberIDs {
+		user, err := s.userRepo.GetVisibleByID(ctx, userID, id)
+		if err != nil {
+			return notFound(fmt.Sprintf("user %d not found", id))
+		}
+		if err := s.checkCanMessage(ctx, userID, user); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *messageService) members(ctx context.Context, viewerID, conversationID int) ([]dto.ConversationMemberResponse, error) {
+	members, err := s.messageRepo.GetMembers(ctx, conversationID)
+	if err != nil {
+		return nil, fmt.Errorf("service failed to get members from repo: %w", err)
+	}
+
+	users := make([]models.User, 0, len(members))
+	for i := range members {
+		users = append(users, members[i].User)
+	}
+	userResponses := toUserResponses(users)
+	if err := attachRelationships(ctx, s.followRepo, viewerID, userResponses); err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.ConversationMemberResponse, 0, len(members))
+	for i := range members {
+		responses = append(responses, dto.ConversationMemberResponse{
+			User:              userResponses[i],
+			Role:              string(members[i].Role),
+			LastReadMessageID: members[i].LastReadMessageID,
+			JoinedAt:          members[i].JoinedAt,
+		})
+	}
+	return responses, nil
+}
+
+func canManageGroup(role models.MemberRole) bool {
+	return role == models.MemberRoleOwner || role == models.MemberRoleAdmin
+}
+
+// uniqueMemberIDs drops duplicates and the user themselves from ids
+func uniqueMemberIDs(userID int, ids []int) []int {
+	seen := map[int]bool{userID: true}
+	unique := make([]int, 0, len(ids))
+	for _, id := range ids {
+		if !seen[id] {
+			seen[id] = true
+			unique = append(unique, id)
+		}
+	}
+	return unique
+}
+
+// pickSuccessor chooses the next owner of a group among the members other
+// than ownerID, which are ordered by how long they have been in the group
+func pickSuccessor(ownerID int, members []models.ConversationMemberWithUser) *int {
+	var successor *int
+	for i := range members {
+		if members[i].UserID == ownerID {
+			continue
+		}
+		if members[i].Role == models.MemberRoleAdmin {
+			return &members[i].UserID
+		}
+		if successor == nil {
+			successor = &members[i].UserID
+		}
+	}
+	return successor
+}
@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+	"github.com/escuadron-404/red404/backend/pkg/realtime"
+)
+
+type MessageService interface {
+	StartConversation(ctx context.Context, userID int, req dto.StartConversationRequest) (*dto.ConversationResponse, error)
+	GetConversations(ctx context.Context, userID int, cursor string, limit int) ([]dto.ConversationResponse, string, error)
+	GetMessages(ctx context.Context, userID, conversationID int, cursor string, limit int) ([]dto.MessageResponse, string, error)
+	SendMessage(ctx context.Context, userID, conversationID int, req dto.SendMessageRequest) (*dto.MessageResponse, error)
+	MarkRead(ctx context.Context, userID, conversationID int) error
+	Typing(ctx context.Context, userID, conversationID int) error
+
+	CreateGroup(ctx context.Context, userID int, req dto.CreateGroupRequest) (*dto.ConversationResponse, error)
+	RenameGroup(ctx context.Context, userID, conversationID int, req dto.UpdateGroupRequest) error
+	GetMembers(ctx context.Context, userID, conversationID int) ([]dto.ConversationMemberResponse, error)
+	AddMembers(ctx context.Context, userID, conversationID int, req dto.AddMembersRequest) ([]dto.ConversationMemberResponse, error)
+	RemoveMember(ctx context.Context, userID, conversationID, memberID int) error
+	LeaveGroup(ctx context.Context, userID, conversationID int) error
+	UpdateMemberRole(ctx context.Context, userID, conversationID, memberID int, req dto.UpdateMemberRoleRequest) error
+}
+
+type messageService struct {
+	messageRepo repositories.MessageRepository
+	userRepo    repositories.UserRepository
+	followRepo  repositories.FollowRepository
+	events      realtime.Publisher
+}
+
+func NewMessageService(messageRepo repositories.MessageRepository, userRepo repositories.UserRepository,
+	followRepo repositories.FollowRepository, events realtime.Publisher) MessageService {
+	return &messageService{
+		messageRepo: messageRepo,
+		userRepo:    userRepo,
+		followRepo:  followRepo,
+		events:      events,
+	}
+}
+
+// StartConversation opens the conversation with another user, or returns the
+// existing one. A new conversation needs the other user's message settings
+// to allow it.
+func (s *messageService) StartConversation(ctx context.Context, userID int, req dto.StartConversationRequest) (*dto.ConversationResponse, error) {
+	if req.UserID == userID {
+		return nil, invalid("you cannot message yourself")
+	}
+
+	// Blocked users in either direction are invisible, so they look missing
+	other, err := s.userRepo.GetVisibleByID(ctx, userID, req.UserID)
+	if err != nil {
+		return nil, notFound("user not found")
+	}
+	if err := s.checkCanMessage(ctx, userID, other); err != nil {
+		return nil, err
+	}
+
+	conversation, err := s.messageRepo.GetOrCreateDirect(ctx, userID, other.ID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to start conversation: %w", err)
+	}
+
+	participant := toUserResponses([]models.User{*other})
+	if err := attachRelationships(ctx, s.followRepo, userID, participant); err != nil {
+		return nil, err
+	}
+	return &dto.ConversationResponse{
+		ID:             conversation.ID,
+		Kind:           string(conversation.Kind),
+		Participant:    &participant[0],
+		MemberCount:    2,
+		LastActivityAt: conversation.LastActivityAt,
+		CreatedAt:      conversation.CreatedAt,
+	}, nil
+}
+
+// GetConversations lists the inbox of the user, most recent activity first,
+// with the number of unread messages in each conversation
+func (s *messageService) GetConversations(ctx context.Context, userID int, cursor string,
+	limit int) ([]dto.ConversationResponse, string, error) {
+	var after *models.ConversationCursor
+	var position models.ConversationCursor
+	ok, err := decodeCursor(cursor, &position)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		if position.ID <= 0 {
+			return nil, "", invalid("invalid cursor")
+		}
+		after = &position
+	}
+
+	conversations, err := s.messageRepo.GetConversations(ctx, userID, after, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("service failed to get conversations from repo: %w", err)
+	}
+	next := ""
+	if len(conversations) > limit {
+		conversations = conversations[:limit]
+		last := conversations[limit-1]
+		if next, err = encodeCursor(models.ConversationCursor{LastActivityAt: last.LastActivityAt, ID: last.ID}); err != nil {
+			return nil, "", err
+		}
+	}
+
+	var participants []models.User
+	for i := range conversations {
+		if conversations[i].Other != nil {
+			participants = append(participants, *conversations[i].Other)
+		}
+	}
+	participantResponses := toUserResponses(participants)
+	if err := attachRelationships(ctx, s.followRepo, userID, participantResponses); err != nil {
+		return nil, "", err
+	}
+
+	responses := make([]dto.ConversationResponse, 0, len(conversations))
+	for i := range conversations {
+		c := &conversations[i]
+		response := dto.ConversationResponse{
+			ID:             c.ID,
+			Kind:           string(c.Kind),
+			MemberCount:    c.MemberCount,
+			UnreadCount:    c.UnreadCount,
+			LastActivityAt: c.LastActivityAt,
+			CreatedAt:      c.CreatedAt,
+		}
+		if c.Kind == models.ConversationGroup {
+			response.Title = *c.Title
+			response.MyRole = string(c.MyRole)
+		} else {
+			response.Participant = &participantResponses[0]
+			participantResponses = participantResponses[1:]
+		}
+		if c.LastMessage != nil {
+			response.LastMessage = toMessageResponse(c.LastMessage)
+		}
+		responses = append(responses, response)
+	}
+	return responses, next, nil
+}
+
+// GetMessages pages through the history of a conversation, newest first
+func (s *messageService) GetMessages(ctx context.Context, userID, conversationID int, cursor string,
+	limit int) ([]dto.MessageResponse, string, error) {
+	var before *models.MessageCursor
+	var position models.MessageCursor
+	ok, err := decodeCursor(cursor, &position)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		if position.ID <= 0 {
+			return nil, "", invalid("invalid cursor")
+		}
+		before = &position
+	}
+	if _, err := s.getConversation(ctx, userID, conversationID); err != nil {
+		return nil, "", err
+	}
+
+	messages, err := s.messageRepo.GetMessages(ctx, conversationID, before, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("service failed to get messages from repo: %w", err)
+	}
+	next := ""
+	if len(messages) > limit {
+		messages = messages[:limit]
+		if next, err = encodeCursor(models.MessageCursor{ID: messages[limit-1].ID}); err != nil {
+			return nil, "", err
+		}
+	}
+
+	responses := make([]dto.MessageResponse, 0, len(messages))
+	for i := range messages {
+		responses = append(responses, *toMessageResponse(&messages[i]))
+	}
+	return responses, next, nil
+}
+
+// SendMessage posts a message to a conversation. Any member can write in a
+// group. In a direct conversation blocks in either direction stop it, and the
+// message settings of the other member apply until they have replied.
+func (s *messageService) SendMessage(ctx context.Context, userID, conversationID int, req dto.SendMessageRequest) (*dto.MessageResponse, error) {
+	conversation, err := s.getConversation(ctx, userID, conversationID)
+	if err != nil {
+		return nil, err
+	}
+
+	text := strings.TrimSpace(req.Text)
+	if text == "" {
+		return nil, invalid("a message needs some text")
+	}
+	if conversation.Kind == models.ConversationDirect {
+		if err := s.checkCanReply(ctx, userID, conversation); err != nil {
+			return nil, err
+		}
+	}
+
+	message := &models.Message{
+		ConversationID: conversationID,
+		SenderID:       userID,
+		Text:           text,
+	}
+	if err := s.messageRepo.Create(ctx, message); err != nil {
+		return nil, fmt.Errorf("failed to send message: %w", err)
+	}
+
+	response := toMessageResponse(message)
+	s.publish(ctx, conversationID, 0, realtime.Event{Type: EventMessageCreated, Data: response})
+	return response, nil
+}
+
+// Typing tells the other members of a conversation that the user is writing
+// to it
+func (s *messageService) Typing(ctx context.Context, userID, conversationID int) error {
+	conversation, err := s.getConversation(ctx, userID, conversationID)
+	if err != nil {
+		return err
+	}
+	if conversation.Kind == models.ConversationDirect {
+		if err := s.checkCanReply(ctx, userID, conversation); err != nil {
+			return err
+		}
+	}
+
+	s.publish(ctx, conversationID, userID, realtime.Event{
+		Type: EventTyping,
+		Data: dto.TypingEvent{ConversationID: conversationID, UserID: userID},
+	})
+	return nil
+}
+
+// publish sends the event to the members of the conversation other than
+// skipID. The sender of a message gets it too, for their other devices.
+func (s *messageService) publish(ctx context.Context, conversationID, skipID int, event realtime.Event) {
+	memberIDs, err := s.messageRepo.GetMemberIDs(ctx, conversationID)
+	if err != nil {
+		log.Printf("Failed to get members of conversation %d: %v", conversationID, err)
+		return
+	}
+	recipients := make([]int, 0, len(memberIDs))
+	for _, id := range memberIDs {
+		if id != skipID {
+			recipients = append(recipients, id)
+		}
+	}
+	s.events.Publish(recipients, event)
+}
+
+// checkCanReply applies blocks and the message settings of the other member
+// of a direct conversation
+func (s *messageService) checkCanReply(ctx context.Context, userID int, conversation *models.Conversation) error {
+	other, err := s.userRepo.GetVisibleByID(ctx, userID, conversation.OtherMember(userID))
+	if err != nil {
+		return forbidden("you cannot message this user")
+	}
+	replied, err := s.messageRepo.HasSent(ctx, conversation.ID, other.ID)
+	if err != nil {
+		return fmt.Errorf("failed to check conversation history: %w", err)
+	}
+	if replied {
+		return nil
+	}
+	return s.checkCanMessage(ctx, userID, other)
+}
+
+// MarkRead marks every message of the conversation as read
+func (s *messageService) MarkRead(ctx context.Context, userID, conversationID int) error {
+	if _, err := s.getConversation(ctx, userID, conversationID); err != nil {
+		return err
+	}
+	if err := s.messageRepo.MarkRead(ctx, conversationID, userID); err != nil {
+		return fmt.Errorf("failed to mark conversation read: %w", err)
+	}
+	return nil
+}
+
+// checkCanMessage applies the message settings of recipient to a message
+// from senderID
+func (s *messageService) checkCanMessage(ctx context.Context, senderID int, recipient *models.User) error {
+	switch recipient.DMPolicy {
+	case models.DMPolicyNobody:
+		return forbidden("this user does not accept messages")
+	case models.DMPolicyFollowers:
+		following, err := s.followRepo.IsFollowing(ctx, senderID, recipient.ID)
+		if err != nil {
+			return fmt.Errorf("failed to check follow: %w", err)
+		}
+		if !following {
+			return forbidden("only followers of this user can message them")
+		}
+	}
+	return nil
+}
+
+func (s *messageService) getConversation(ctx context.Context, userID, conversationID int) (*models.Conversation, error) {
+	conversation, err := s.messageRepo.GetForMember(ctx, conversationID, userID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("conversation not found")
+		}
+		return nil, fmt.Errorf("failed to get conversation: %w", err)
+	}
+	return conversation, nil
+}
+
+func toMessageResponse(message *models.Message) *dto.MessageResponse {
+	response := &dto.MessageResponse{
+		ID:             message.ID,
+		ConversationID: message.ConversationID,
+		SenderID:       message.SenderID,
+		Kind:           string(message.Kind),
+		TargetUserID:   message.TargetUserID,
+		Text:           message.Text,
+		CreatedAt:      message.CreatedAt,
+	}
+	if message.Event != nil {
+		response.Event = string(*message.Event)
+	}
+	return response
+}
@@ -0,0 +1,84 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+)
+
+type NotificationService interface {
+	GetNotifications(ctx context.Context, userID, limit, offset int) ([]dto.NotificationResponse, int, error)
+	GetUnreadCount(ctx context.Context, userID int) (*dto.UnreadCountResponse, error)
+	MarkRead(ctx context.Context, userID, notificationID int) error
+	MarkAllRead(ctx context.Context, userID int) error
+}
+
+type notificationService struct {
+	notificationRepo repositories.NotificationRepository
+	followRepo       repositories.FollowRepository
+}
+
+func NewNotificationService(notificationRepo repositories.NotificationRepository, followRepo repositories.FollowRepository) NotificationService {
+	return &notificationService{
+		notificationRepo: notificationRepo,
+		followRepo:       followRepo,
+	}
+}
+
+func (s *notificationService) GetNotifications(ctx context.Context, userID, limit, offset int) ([]dto.NotificationResponse, int, error) {
+	notifications, total, err := s.notificationRepo.GetByUser(ctx, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get notifications from repo: %w", err)
+	}
+
+	actors := make([]dto.UserResponse, 0, len(notifications))
+	for i := range notifications {
+		actors = append(actors, *toUserResponse(&notifications[i].Actor))
+	}
+	if err := attachRelationships(ctx, s.followRepo, userID, actors); err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.NotificationResponse, 0, len(notifications))
+	for i := range notifications {
+		n := &notifications[i]
+		responses = append(responses, dto.NotificationResponse{
+			ID:        n.ID,
+			Type:      string(n.Type),
+			Actor:     actors[i],
+			PostID:    n.PostID,
+			CommentID: n.CommentID,
+			Read:      n.ReadAt != nil,
+			CreatedAt: n.CreatedAt,
+		})
+	}
+	return responses, total, nil
+}
+
+func (s *notificationService) GetUnreadCount(ctx context.Context, userID int) (*dto.UnreadCountResponse, error) {
+	count, err := s.notificationRepo.CountUnread(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.UnreadCountResponse{UnreadCount: count}, nil
+}
+
+func (s *notificationService) MarkRead(ctx context.Context, userID, notificationID int) error {
+	if err := s.notificationRepo.MarkRead(ctx, userID, notificationID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("notification not found")
+		}
+		return fmt.Errorf("failed to mark notification as read: %w", err)
+	}
+	return nil
+}
+
+func (s *notificationService) MarkAllRead(ctx context.Context, userID int) error {
+	if err := s.notificationRepo.MarkAllRead(ctx, userID); err != nil {
+		return fmt.Errorf("failed to mark notifications as read: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,182 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+)
+
+// SavePost bookmarks a post the user can see, in one of their collections
+// when req names one. Saving again moves the bookmark between collections.
+func (s *postService) SavePost(ctx context.Context, userID, postID int, req dto.SaveBookmarkRequest) (*dto.BookmarkResponse, error) {
+	if _, err := s.postRepo.GetVisibleByID(ctx, userID, postID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("post not found")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if req.CollectionID != nil {
+		if _, err := s.getCollection(ctx, userID, *req.CollectionID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := s.bookmarkRepo.Save(ctx, userID, postID, req.CollectionID); err != nil {
+		return nil, fmt.Errorf("failed to save post: %w", err)
+	}
+	return &dto.BookmarkResponse{SavedByMe: true, CollectionID: req.CollectionID}, nil
+}
+
+// UnsavePost removes a bookmark. Like unliking, it works on posts that became
+// hidden since they were saved.
+func (s *postService) UnsavePost(ctx context.Context, userID, postID int) (*dto.BookmarkResponse, error) {
+	if err := s.bookmarkRepo.Remove(ctx, userID, postID); err != nil {
+		return nil, fmt.Errorf("failed to unsave post: %w", err)
+	}
+	return &dto.BookmarkResponse{SavedByMe: false}, nil
+}
+
+// GetSavedPosts lists the posts the user saved, most recently saved first.
+// Posts deleted since or whose author is now hidden from the user are left
+// out.
+func (s *postService) GetSavedPosts(ctx context.Context, userID int, collectionID *int, cursor string,
+	limit int) ([]dto.SavedPostResponse, string, error) {
+	var after *models.BookmarkCursor
+	var position models.BookmarkCursor
+	ok, err := decodeCursor(cursor, &position)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		if position.ID <= 0 {
+			return nil, "", invalid("invalid cursor")
+		}
+		after = &position
+	}
+	if collectionID != nil {
+		if _, err := s.getCollection(ctx, userID, *collectionID); err != nil {
+			return nil, "", err
+		}
+	}
+
+	saved, err := s.bookmarkRepo.GetSaved(ctx, userID, collectionID, after, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("service failed to get saved posts from repo: %w", err)
+	}
+	next := ""
+	if len(saved) > limit {
+		saved = saved[:limit]
+		last := saved[limit-1]
+		if next, err = encodeCursor(models.BookmarkCursor{SavedAt: last.SavedAt, ID: last.BookmarkID}); err != nil {
+			return nil, "", err
+		}
+	}
+
+	posts := make([]models.PostWithAuthor, 0, len(saved))
+	for i := range saved {
+		posts = append(posts, saved[i].PostWithAuthor)
+	}
+	postResponses, err := s.toPostResponses(ctx, userID, posts)
+	if err != nil {
+		return nil, "", err
+	}
+
+	responses := make([]dto.SavedPostResponse, 0, len(saved))
+	for i := range saved {
+		responses = append(responses, dto.SavedPostResponse{
+			PostResponse: postResponses[i],
+			CollectionID: saved[i].CollectionID,
+			SavedAt:      saved[i].SavedAt,
+		})
+	}
+	return responses, next, nil
+}
+
+func (s *postService) CreateCollection(ctx context.Context, userID int, req dto.CollectionRequest) (*dto.CollectionResponse, error) {
+	collection := &models.Collection{UserID: userID, Name: strings.TrimSpace(req.Name)}
+	if collection.Name == "" {
+		return nil, invalid("a collection needs a name")
+	}
+
+	if err := s.bookmarkRepo.CreateCollection(ctx, collection); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, conflict("you already have a collection with this name")
+		}
+		return nil, fmt.Errorf("failed to create collection: %w", err)
+	}
+	return toCollectionResponse(collection), nil
+}
+
+func (s *postService) GetCollections(ctx context.Context, userID int) ([]dto.CollectionResponse, error) {
+	collections, err := s.bookmarkRepo.GetCollections(ctx, userID)
+	if err != nil {
+		return nil, fmt.Errorf("service failed to get collections from repo: %w", err)
+	}
+
+	responses := make([]dto.CollectionResponse, 0, len(collections))
+	for i := range collections {
+		responses = append(responses, *toCollectionResponse(&collections[i]))
+	}
+	return responses, nil
+}
+
+func (s *postService) RenameCollection(ctx context.Context, userID, collectionID int, req dto.CollectionRequest) (*dto.CollectionResponse, error) {
+	name := strings.TrimSpace(req.Name)
+	if name == "" {
+		return nil, invalid("a collection needs a name")
+	}
+
+	if err := s.bookmarkRepo.RenameCollection(ctx, userID, collectionID, name); err != nil {
+		switch {
+		case errors.Is(err, repositories.ErrNotFound):
+			return nil, notFound("collection not found")
+		case errors.Is(err, repositories.ErrConflict):
+			return nil, conflict("you already have a collection with this name")
+		}
+		return nil, fmt.Errorf("failed to rename collection: %w", err)
+	}
+	collection, err := s.getCollection(ctx, userID, collectionID)
+	if err != nil {
+		return nil, err
+	}
+	return toCollectionResponse(collection), nil
+}
+
+// DeleteCollection deletes a collection. Its posts stay in the saved list.
+func (s *postService) DeleteCollection(ctx context.Context, userID, collectionID int) error {
+	if err := s.bookmarkRepo.DeleteCollection(ctx, userID, collectionID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("collection not found")
+		}
+		return fmt.Errorf("failed to delete collection: %w", err)
+	}
+	return nil
+}
+
+// getCollection loads a collection of userID. Other users' collections are
+// reported as missing.
+func (s *postService) getCollection(ctx context.Context, userID, collectionID int) (*models.Collection, error) {
+	collection, err := s.bookmarkRepo.GetCollection(ctx, userID, collectionID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("collection not found")
+		}
+		return nil, fmt.Errorf("failed to get collection: %w", err)
+	}
+	return collection, nil
+}
+
+func toCollectionResponse(collection *models.Collection) *dto.CollectionResponse {
+	return &dto.CollectionResponse{
+		ID:        collection.ID,
+		Name:      collection.Name,
+		PostCount: collection.PostCount,
+		CreatedAt: collection.CreatedAt,
+		UpdatedAt: collection.UpdatedAt,
+	}
+}
@@ -0,0 +1,48 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+)
+
+// exploreHashtags is how many trending hashtags the explore page shows
+const exploreHashtags = 10
+
+// GetExplore returns a page of the trending posts the viewer can see, along
+// with the trending hashtags on the first page. Both come from the last run
+// of the trending job.
+func (s *postService) GetExplore(ctx context.Context, viewerID, limit, offset int) (*dto.ExploreResponse, error) {
+	posts, err := s.trendingRepo.GetPosts(ctx, viewerID, limit, offset)
+	if err != nil {
+		return nil, fmt.Errorf("service failed to get trending posts from repo: %w", err)
+	}
+	responses, err := s.toPostResponses(ctx, viewerID, posts)
+	if err != nil {
+		return nil, err
+	}
+
+	explore := &dto.ExploreResponse{
+		Posts:    responses,
+		Hashtags: []dto.TrendingHashtagResponse{},
+		Limit:    limit,
+		Offset:   offset,
+	}
+	if offset > 0 {
+		return explore, nil
+	}
+
+	hashtags, err := s.trendingRepo.GetHashtags(ctx, exploreHashtags)
+	if err != nil {
+		return nil, fmt.Errorf("service failed to get trending hashtags from repo: %w", err)
+	}
+	for i := range hashtags {
+		explore.Hashtags = append(explore.Hashtags, dto.TrendingHashtagResponse{
+			Name:            hashtags[i].Name,
+			PostCount:       hashtags[i].PostCount,
+			RecentPostCount: hashtags[i].RecentPostCount,
+		})
+	}
+	return explore, nil
+}
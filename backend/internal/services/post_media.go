@@ -0,0 +1,163 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"image"
+	_ "image/gif"  // register GIF for image.DecodeConfig
+	_ "image/jpeg" // register JPEG for image.DecodeConfig
+	_ "image/png"  // register PNG for image.DecodeConfig
+	"io"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/pkg/storage"
+)
+
+// imageExtensions lists the accepted image content types and the extension
+// used for their storage keys
+var imageExtensions = map[string]string{
+	"image/jpeg": ".jpg",
+	"image/png":  ".png",
+	"image/gif":  ".gif",
+}
+
+const maxAltTextLength = 1000
+
+// MediaUpload is a file received with a new post
+type MediaUpload struct {
+	File    io.ReadSeeker
+	Size    int64
+	AltText string
+}
+
+// PostMediaOptions configures where post media is stored and its limits
+type PostMediaOptions struct {
+	Storage       storage.Storage
+	URLTTL        time.Duration
+	MaxItems      int
+	MaxImageBytes int64
+}
+
+// storeImages validates every upload and writes it to blob storage. Already
+// stored files are removed again when a later one is rejected.
+func (s *postService) storeImages(ctx context.Context, authorID int, uploads []MediaUpload) ([]models.PostMedia, error) {
+	if len(uploads) > s.media.MaxItems {
+		return nil, invalid(fmt.Sprintf("a post can have at most %d images", s.media.MaxItems))
+	}
+
+	media := make([]models.PostMedia, 0, len(uploads))
+	for i := range uploads {
+		item, err := s.storeImage(ctx, authorID, &uploads[i])
+		if err != nil {
+			s.deleteMedia(ctx, media)
+			return nil, err
+		}
+		media = append(media, *item)
+	}
+	return media, nil
+}
+
+func (s *postService) storeImage(ctx context.Context, authorID int, upload *MediaUpload) (*models.PostMedia, error) {
+	if upload.Size > s.media.MaxImageBytes {
+		return nil, invalid(fmt.Sprintf("images must be at most %d MB", s.media.MaxImageBytes>>20))
+	}
+	if len([]rune(upload.AltText)) > maxAltTextLength {
+		return nil, invalid(fmt.Sprintf("alt text must be at most %d characters long", maxAltTextLength))
+	}
+
+	head := make([]byte, 512)
+	n, err := io.ReadFull(upload.File, head)
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, invalid("could not read uploaded image")
+	}
+	contentType := http.DetectContentType(head[:n])
+	ext, ok := imageExtensions[contentType]
+	if !ok {
+		return nil, invalid("only JPEG, PNG and GIF images are supported")
+	}
+
+	if _, err := upload.File.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind upload: %w", err)
+	}
+	config, _, err := image.DecodeConfig(upload.File)
+	if err != nil {
+		return nil, invalid("uploaded image is corrupted")
+	}
+	if _, err := upload.File.Seek(0, io.SeekStart); err != nil {
+		return nil, fmt.Errorf("failed to rewind upload: %w", err)
+	}
+
+	key, err := newMediaKey(authorID, ext)
+	if err != nil {
+		return nil, err
+	}
+	if err := s.media.Storage.Put(ctx, key, upload.File, upload.Size, contentType); err != nil {
+		return nil, fmt.Errorf("failed to store image: %w", err)
+	}
+
+	return &models.PostMedia{
+		MediaType:   models.MediaTypeImage,
+		StorageKey:  key,
+		ContentType: contentType,
+		Width:       config.Width,
+		Height:      config.Height,
+		AltText:     upload.AltText,
+	}, nil
+}
+
+// deleteMedia removes stored files that never made it into a post
+func (s *postService) deleteMedia(ctx context.Context, media []models.PostMedia) {
+	for i := range media {
+		if err := s.media.Storage.Delete(ctx, media[i].StorageKey); err != nil {
+			log.Printf("Failed to delete orphan media %s: %v", media[i].StorageKey, err)
+		}
+	}
+}
+
+// toMediaResponses signs a short lived URL for every media item
+func (s *postService) toMediaResponses(ctx context.Context, media []models.PostMedia) ([]dto.PostMediaResponse, error) {
+	responses := make([]dto.PostMediaResponse, 0, len(media))
+	for i := range media {
+		url, err := s.media.Storage.SignedURL(ctx, media[i].StorageKey, s.media.URLTTL)
+		if err != nil {
+			return nil, fmt.Errorf("failed to sign media url: %w", err)
+		}
+		var posterURL string
+		if media[i].PosterKey != "" {
+			posterURL, err = s.media.Storage.SignedURL(ctx, media[i].PosterKey, s.media.URLTTL)
+			if err != nil {
+				return nil, fmt.Errorf("failed to sign poster url: %w", err)
+			}
+		}
+		responses = append(responses, dto.PostMediaResponse{
+			ID:          media[i].ID,
+			Position:    media[i].Position,
+			MediaType:   string(media[i].MediaType),
+			URL:         url,
+			ContentType: media[i].ContentType,
+			Width:       media[i].Width,
+			Height:      media[i].Height,
+			AltText:     media[i].AltText,
+			PosterURL:   posterURL,
+			DurationMS:  media[i].DurationMS,
+		})
+	}
+	return responses, nil
+}
+
+// newMediaKey returns a random, unguessable storage key under the author's
+// folder
+func newMediaKey(authorID int, ext string) (string, error) {
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate media key: %w", err)
+	}
+	return fmt.Sprintf("posts/%d/%s%s", authorID, hex.EncodeToString(buf), ext), nil
+}
@@ -0,0 +1,42 @@
+package services
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+)
+
+// SearchPosts runs a full-text search over the descriptions of the posts the
+// viewer can see
+func (s *postService) SearchPosts(ctx context.Context, viewerID int, req dto.SearchRequest,
+	limit, offset int) ([]dto.PostSearchResult, int, error) {
+	filter, err := toSearchFilter(req)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	hits, total, err := s.postRepo.Search(ctx, viewerID, filter, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to search posts in repo: %w", err)
+	}
+
+	posts := make([]models.PostWithAuthor, 0, len(hits))
+	for i := range hits {
+		posts = append(posts, hits[i].PostWithAuthor)
+	}
+	responses, err := s.toPostResponses(ctx, viewerID, posts)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	results := make([]dto.PostSearchResult, 0, len(responses))
+	for i := range responses {
+		results = append(results, dto.PostSearchResult{
+			PostResponse: responses[i],
+			Snippet:      highlightSnippet(hits[i].Snippet),
+		})
+	}
+	return results, total, nil
+}
@@ -0,0 +1,574 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+	"github.com/escuadron-404/red404/backend/pkg/hashtag"
+	"github.com/escuadron-404/red404/backend/pkg/mention"
+	"github.com/escuadron-404/red404/backend/pkg/realtime"
+)
+
+// maxMentionedUsers caps how many people one text can notify
+const maxMentionedUsers = 20
+
+type PostService interface {
+	CreatePost(ctx context.Context, authorID int, req dto.CreatePostRequest, uploads []MediaUpload) (*dto.PostResponse, error)
+	GetPost(ctx context.Context, viewerID, postID int) (*dto.PostResponse, error)
+	GetUserPosts(ctx context.Context, viewerID, userID, limit, offset int) ([]dto.PostResponse, int, error)
+	GetVideoPosts(ctx context.Context, viewerID, limit, offset int) ([]dto.PostResponse, int, error)
+	GetFeed(ctx context.Context, viewerID int, mode, cursor string, limit int, debug bool) ([]dto.PostResponse, string, error)
+	GetExplore(ctx context.Context, viewerID, limit, offset int) (*dto.ExploreResponse, error)
+	GetHashtag(ctx context.Context, name string) (*dto.HashtagResponse, error)
+	GetHashtagPosts(ctx context.Context, viewerID int, name string, limit, offset int) ([]dto.PostResponse, int, error)
+	SearchPosts(ctx context.Context, viewerID int, req dto.SearchRequest, limit, offset int) ([]dto.PostSearchResult, int, error)
+	UpdatePost(ctx context.Context, authorID, postID int, req dto.UpdatePostRequest) (*dto.PostResponse, error)
+	DeletePost(ctx context.Context, authorID, postID int) error
+	UpdateMediaAltText(ctx context.Context, authorID, postID, mediaID int, req dto.UpdateMediaAltTextRequest) error
+	LikePost(ctx context.Context, userID, postID int) (*dto.LikeResponse, error)
+	UnlikePost(ctx context.Context, userID, postID int) (*dto.LikeResponse, error)
+
+	SavePost(ctx context.Context, userID, postID int, req dto.SaveBookmarkRequest) (*dto.BookmarkResponse, error)
+	UnsavePost(ctx context.Context, userID, postID int) (*dto.BookmarkResponse, error)
+	GetSavedPosts(ctx context.Context, userID int, collectionID *int, cursor string, limit int) ([]dto.SavedPostResponse, string, error)
+	CreateCollection(ctx context.Context, userID int, req dto.CollectionRequest) (*dto.CollectionResponse, error)
+	GetCollections(ctx context.Context, userID int) ([]dto.CollectionResponse, error)
+	RenameCollection(ctx context.Context, userID, collectionID int, req dto.CollectionRequest) (*dto.CollectionResponse, error)
+	DeleteCollection(ctx context.Context, userID, collectionID int) error
+}
+
+type postService struct {
+	postRepo     repositories.PostRepository
+	userRepo     repositories.UserRepository
+	followRepo   repositories.FollowRepository
+	videoRepo    repositories.VideoUploadRepository
+	hashtagRepo  repositories.HashtagRepository
+	mentionRepo  repositories.MentionRepository
+	likeRepo     repositories.LikeRepository
+	reactionRepo repositories.ReactionRepository
+	bookmarkRepo repositories.BookmarkRepository
+	timelineRepo repositories.TimelineRepository
+	rankingRepo  repositories.RankingRepository
+	trendingRepo repositories.TrendingRepository
+	events       realtime.Publisher
+	media        PostMediaOptions
+	feed         FeedOptions
+}
+
+func NewPostService(postRepo repositories.PostRepository, userRepo repositories.UserRepository, followRepo repositories.FollowRepository,
+	videoRepo repositories.VideoUploadRepository, hashtagRepo repositories.HashtagRepository, mentionRepo repositories.MentionRepository,
+	likeRepo repositories.LikeRepository, reactionRepo repositories.ReactionRepository, bookmarkRepo repositories.BookmarkRepository,
+	timelineRepo repositories.TimelineRepository, rankingRepo repositories.RankingRepository,
+	trendingRepo repositories.TrendingRepository, events realtime.Publisher, media PostMediaOptions, feed FeedOptions) PostService {
+	return &postService{
+		postRepo:     postRepo,
+		userRepo:     userRepo,
+		followRepo:   followRepo,
+		videoRepo:    videoRepo,
+		hashtagRepo:  hashtagRepo,
+		mentionRepo:  mentionRepo,
+		likeRepo:     likeRepo,
+		reactionRepo: reactionRepo,
+		bookmarkRepo: bookmarkRepo,
+		timelineRepo: timelineRepo,
+		rankingRepo:  rankingRepo,
+		trendingRepo: trendingRepo,
+		events:       events,
+		media:        media,
+		feed:         feed,
+	}
+}
+
+// CreatePost stores the uploaded images in order, or attaches a finished
+// video upload, and creates the post with them. Nothing is left behind in
+// storage when the post cannot be created.
+func (s *postService) CreatePost(ctx context.Context, authorID int, req dto.CreatePostRequest, uploads []MediaUpload) (*dto.PostResponse, error) {
+	post := &models.Post{
+		UserID:      authorID,
+		ImageURL:    strings.TrimSpace(req.ImageURL),
+		Description: strings.TrimSpace(req.Description),
+		NSFW:        req.NSFW,
+	}
+	if post.ImageURL == "" && post.Description == "" && len(uploads) == 0 && req.VideoUploadID == "" {
+		return nil, invalid("a post needs an image or a description")
+	}
+	mentions, err := extractMentions(post.Description)
+	if err != nil {
+		return nil, err
+	}
+	if req.VideoUploadID != "" {
+		return s.createVideoPost(ctx, post, mentions, req.VideoUploadID, len(uploads))
+	}
+
+	media, err := s.storeImages(ctx, authorID, uploads)
+	if err != nil {
+		return nil, err
+	}
+
+	notified, err := s.postRepo.Create(ctx, post, media, hashtag.Extract(post.Description), mentions)
+	if err != nil {
+		s.deleteMedia(ctx, media)
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+	publishMentions(s.events, notified, post.UserID, post.ID, nil)
+	enqueueFanout(s.feed, func() error { return s.timelineRepo.EnqueuePost(ctx, post.ID, post.UserID) })
+	return s.GetPost(ctx, authorID, post.ID)
+}
+
+// createVideoPost claims the upload so it cannot be attached twice and
+// releases it again if the post cannot be created
+func (s *postService) createVideoPost(ctx context.Context, post *models.Post, mentions []models.Mention, uploadID string,
+	images int) (*dto.PostResponse, error) {
+	if images > 0 {
+		return nil, invalid("a video post cannot have images")
+	}
+
+	upload, err := s.videoRepo.Claim(ctx, post.UserID, uploadID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("video upload not found or not ready")
+		}
+		return nil, fmt.Errorf("failed to claim video upload: %w", err)
+	}
+
+	media := []models.PostMedia{{
+		MediaType:   models.MediaTypeVideo,
+		StorageKey:  upload.StorageKey,
+		ContentType: upload.ContentType,
+		Width:       upload.Width,
+		Height:      upload.Height,
+		PosterKey:   upload.PosterKey,
+		DurationMS:  upload.DurationMS,
+	}}
+
+	notified, err := s.postRepo.Create(ctx, post, media, hashtag.Extract(post.Description), mentions)
+	if err != nil {
+		if releaseErr := s.videoRepo.Release(ctx, uploadID); releaseErr != nil {
+			log.Printf("Failed to release video upload %s: %v", uploadID, releaseErr)
+		}
+		return nil, fmt.Errorf("failed to create post: %w", err)
+	}
+	publishMentions(s.events, notified, post.UserID, post.ID, nil)
+	enqueueFanout(s.feed, func() error { return s.timelineRepo.EnqueuePost(ctx, post.ID, post.UserID) })
+	return s.GetPost(ctx, post.UserID, post.ID)
+}
+
+// GetPost returns a post if the viewer may see it. Posts hidden by a block or
+// by a private account are reported as missing.
+func (s *postService) GetPost(ctx context.Context, viewerID, postID int) (*dto.PostResponse, error) {
+	post, err := s.postRepo.GetVisibleByID(ctx, viewerID, postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("post not found")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	posts, err := s.toPostResponses(ctx, viewerID, []models.PostWithAuthor{*post})
+	if err != nil {
+		return nil, err
+	}
+	return &posts[0], nil
+}
+
+func (s *postService) GetUserPosts(ctx context.Context, viewerID, userID, limit, offset int) ([]dto.PostResponse, int, error) {
+	user, err := s.userRepo.GetVisibleByID(ctx, viewerID, userID)
+	if err != nil {
+		return nil, 0, notFound("user not found")
+	}
+	if user.IsPrivate && viewerID != userID {
+		following, err := s.followRepo.IsFollowing(ctx, viewerID, userID)
+		if err != nil {
+			return nil, 0, fmt.Errorf("failed to check follow: %w", err)
+		}
+		if !following {
+			return nil, 0, forbidden("this account is private")
+		}
+	}
+
+	posts, total, err := s.postRepo.GetByUser(ctx, viewerID, userID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get posts from repo: %w", err)
+	}
+
+	responses, err := s.toPostResponses(ctx, viewerID, posts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return responses, total, nil
+}
+
+// GetVideoPosts lists the newest video posts the viewer can see, for the
+// Brainrot tab
+func (s *postService) GetVideoPosts(ctx context.Context, viewerID, limit, offset int) ([]dto.PostResponse, int, error) {
+	posts, total, err := s.postRepo.GetVideos(ctx, viewerID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get videos from repo: %w", err)
+	}
+
+	responses, err := s.toPostResponses(ctx, viewerID, posts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return responses, total, nil
+}
+
+// GetFeed returns the home feed of the viewer. The chronological mode, the
+// default, has their posts and those of the accounts they follow, newest
+// first. The ranked mode orders recent posts by how likely the viewer is to
+// care about them.
+func (s *postService) GetFeed(ctx context.Context, viewerID int, mode, cursor string, limit int,
+	debug bool) ([]dto.PostResponse, string, error) {
+	switch mode {
+	case "", FeedModeChronological:
+		return s.getChronologicalFeed(ctx, viewerID, cursor, limit)
+	case FeedModeRanked:
+		return s.getRankedFeed(ctx, viewerID, cursor, limit, debug)
+	default:
+		return nil, "", invalid(fmt.Sprintf("mode must be one of: %s, %s", FeedModeChronological, FeedModeRanked))
+	}
+}
+
+func (s *postService) getChronologicalFeed(ctx context.Context, viewerID int, cursor string, limit int) ([]dto.PostResponse, string, error) {
+	var after *models.PostCursor
+	var position models.PostCursor
+	ok, err := decodeCursor(cursor, &position)
+	if err != nil {
+		return nil, "", err
+	}
+	if ok {
+		if position.ID <= 0 {
+			return nil, "", invalid("invalid cursor")
+		}
+		after = &position
+	}
+
+	posts, err := s.feedPosts(ctx, viewerID, after, limit+1)
+	if err != nil {
+		return nil, "", fmt.Errorf("service failed to get feed from repo: %w", err)
+	}
+	next := ""
+	if len(posts) > limit {
+		posts = posts[:limit]
+		last := posts[limit-1]
+		if next, err = encodeCursor(models.PostCursor{CreatedAt: last.CreatedAt, ID: last.ID}); err != nil {
+			return nil, "", err
+		}
+	}
+
+	responses, err := s.toPostResponses(ctx, viewerID, posts)
+	if err != nil {
+		return nil, "", err
+	}
+	return responses, next, nil
+}
+
+// feedPosts reads the materialized timeline when fan-out is enabled. Until
+// the timeline of the viewer has been backfilled, such as for everyone right
+// after the mode is turned on, the feed is gathered at read time and the
+// backfill is queued.
+func (s *postService) feedPosts(ctx context.Context, viewerID int, after *models.PostCursor, limit int) ([]models.PostWithAuthor, error) {
+	if s.feed.Fanout {
+		materialized, err := s.timelineRepo.IsMaterialized(ctx, viewerID)
+		if err != nil {
+			return nil, err
+		}
+		if materialized {
+			return s.timelineRepo.GetTimeline(ctx, viewerID, after, limit, s.feed.Limits.MaxFollowers)
+		}
+		enqueueFanout(s.feed, func() error { return s.timelineRepo.EnqueueTimeline(ctx, viewerID) })
+	}
+	return s.postRepo.GetFeed(ctx, viewerID, after, limit)
+}
+
+func (s *postService) GetHashtag(ctx context.Context, name string) (*dto.HashtagResponse, error) {
+	tag, err := s.getHashtag(ctx, name)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.HashtagResponse{Name: tag.Name, PostCount: tag.PostCount}, nil
+}
+
+func (s *postService) GetHashtagPosts(ctx context.Context, viewerID int, name string, limit, offset int) ([]dto.PostResponse, int, error) {
+	tag, err := s.getHashtag(ctx, name)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	posts, total, err := s.postRepo.GetByHashtag(ctx, viewerID, tag.ID, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get hashtag posts from repo: %w", err)
+	}
+
+	responses, err := s.toPostResponses(ctx, viewerID, posts)
+	if err != nil {
+		return nil, 0, err
+	}
+	return responses, total, nil
+}
+
+// getHashtag accepts the tag as typed, with or without '#' and in any case
+func (s *postService) getHashtag(ctx context.Context, name string) (*models.Hashtag, error) {
+	name = hashtag.Normalize(name)
+	if !hashtag.Valid(name) {
+		return nil, invalid("invalid hashtag")
+	}
+
+	tag, err := s.hashtagRepo.GetByName(ctx, name)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("hashtag not found")
+		}
+		return nil, fmt.Errorf("failed to get hashtag: %w", err)
+	}
+	return tag, nil
+}
+
+// UpdatePost edits the fields present in req. Only the author can edit a post.
+func (s *postService) UpdatePost(ctx context.Context, authorID, postID int, req dto.UpdatePostRequest) (*dto.PostResponse, error) {
+	post, err := s.getOwnPost(ctx, authorID, postID)
+	if err != nil {
+		return nil, err
+	}
+
+	if req.ImageURL != nil {
+		post.ImageURL = strings.TrimSpace(*req.ImageURL)
+	}
+	if req.Description != nil {
+		post.Description = strings.TrimSpace(*req.Description)
+	}
+	if req.NSFW != nil {
+		post.NSFW = *req.NSFW
+	}
+	if post.ImageURL == "" && post.Description == "" {
+		media, err := s.postRepo.GetMedia(ctx, []int{postID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get post media: %w", err)
+		}
+		if len(media[postID]) == 0 {
+			return nil, invalid("a post needs an image or a description")
+		}
+	}
+	mentions, err := extractMentions(post.Description)
+	if err != nil {
+		return nil, err
+	}
+	post.UpdatedAt = time.Now()
+
+	notified, err := s.postRepo.Update(ctx, post, hashtag.Extract(post.Description), mentions)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("post not found")
+		}
+		return nil, fmt.Errorf("failed to update post: %w", err)
+	}
+	publishMentions(s.events, notified, post.UserID, post.ID, nil)
+	return s.GetPost(ctx, authorID, postID)
+}
+
+// DeletePost soft deletes the post. Only the author can delete it.
+func (s *postService) DeletePost(ctx context.Context, authorID, postID int) error {
+	if _, err := s.getOwnPost(ctx, authorID, postID); err != nil {
+		return err
+	}
+
+	if err := s.postRepo.SoftDelete(ctx, postID); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("post not found")
+		}
+		return fmt.Errorf("failed to delete post: %w", err)
+	}
+	return nil
+}
+
+func (s *postService) UpdateMediaAltText(ctx context.Context, authorID, postID, mediaID int, req dto.UpdateMediaAltTextRequest) error {
+	if _, err := s.getOwnPost(ctx, authorID, postID); err != nil {
+		return err
+	}
+
+	if err := s.postRepo.UpdateMediaAltText(ctx, postID, mediaID, strings.TrimSpace(req.AltText)); err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound("media not found")
+		}
+		return fmt.Errorf("failed to update alt text: %w", err)
+	}
+	return nil
+}
+
+// LikePost likes a post the user can see. Liking twice is not an error.
+func (s *postService) LikePost(ctx context.Context, userID, postID int) (*dto.LikeResponse, error) {
+	post, err := s.postRepo.GetVisibleByID(ctx, userID, postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("post not found")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	count, err := s.likeRepo.Like(ctx, userID, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to like post: %w", err)
+	}
+	s.publishLikes(post.UserID, postID, count)
+	return &dto.LikeResponse{LikeCount: count, LikedByMe: true}, nil
+}
+
+// UnlikePost removes a like. It works even if the post became hidden from
+// the user since, so a like can always be taken back.
+func (s *postService) UnlikePost(ctx context.Context, userID, postID int) (*dto.LikeResponse, error) {
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("post not found")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+
+	count, err := s.likeRepo.Unlike(ctx, userID, postID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to unlike post: %w", err)
+	}
+	s.publishLikes(post.UserID, postID, count)
+	return &dto.LikeResponse{LikeCount: count, LikedByMe: false}, nil
+}
+
+// publishLikes sends the new like count of a post to its author
+func (s *postService) publishLikes(authorID, postID, count int) {
+	s.events.Publish([]int{authorID}, realtime.Event{
+		Type: EventPostLikes,
+		Data: dto.LikeCountEvent{PostID: postID, LikeCount: count},
+	})
+}
+
+// getOwnPost loads a live post and makes sure authorID wrote it
+func (s *postService) getOwnPost(ctx context.Context, authorID, postID int) (*models.Post, error) {
+	post, err := s.postRepo.GetByID(ctx, postID)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("post not found")
+		}
+		return nil, fmt.Errorf("failed to get post: %w", err)
+	}
+	if post.UserID != authorID {
+		return nil, forbidden("you can only modify your own posts")
+	}
+	return post, nil
+}
+
+func (s *postService) toPostResponses(ctx context.Context, viewerID int, posts []models.PostWithAuthor) ([]dto.PostResponse, error) {
+	authors := make([]dto.UserResponse, 0, len(posts))
+	for i := range posts {
+		authors = append(authors, *toUserResponse(&posts[i].Author))
+	}
+	if err := attachRelationships(ctx, s.followRepo, viewerID, authors); err != nil {
+		return nil, err
+	}
+
+	ids := make([]int, 0, len(posts))
+	for i := range posts {
+		ids = append(ids, posts[i].ID)
+	}
+	details, err := s.loadPostDetails(ctx, viewerID, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	responses := make([]dto.PostResponse, 0, len(posts))
+	for i := range posts {
+		id := posts[i].ID
+		mediaResponses, err := s.toMediaResponses(ctx, details.media[id])
+		if err != nil {
+			return nil, err
+		}
+		responses = append(responses, dto.PostResponse{
+			ID:           id,
+			Author:       authors[i],
+			ImageURL:     posts[i].ImageURL,
+			Description:  posts[i].Description,
+			Media:        mediaResponses,
+			Mentions:     toMentionEntities(details.mentions[id]),
+			LikeCount:    posts[i].LikeCount,
+			LikedByMe:    details.liked[id],
+			CommentCount: posts[i].CommentCount,
+			NSFW:         posts[i].NSFW,
+			SavedByMe:    details.saved[id],
+			Reactions:    toReactionCounts(details.reactions[id]),
+			MyReaction:   details.myReactions[id],
+			CreatedAt:    posts[i].CreatedAt,
+			UpdatedAt:    posts[i].UpdatedAt,
+		})
+	}
+	return responses, nil
+}
+
+// postDetails holds everything shown with a post besides its own row, keyed
+// by post id
+type postDetails struct {
+	media       map[int][]models.PostMedia
+	mentions    map[int][]models.Mention
+	liked       map[int]bool
+	saved       map[int]bool
+	reactions   map[int][]models.ReactionCount
+	myReactions map[int]string
+}
+
+// loadPostDetails fetches the details of every post in ids with one query
+// per kind of detail
+func (s *postService) loadPostDetails(ctx context.Context, viewerID int, ids []int) (*postDetails, error) {
+	details := &postDetails{}
+	var err error
+	if details.media, err = s.postRepo.GetMedia(ctx, ids); err != nil {
+		return nil, fmt.Errorf("failed to get post media: %w", err)
+	}
+	if details.mentions, err = s.mentionRepo.GetByPosts(ctx, viewerID, ids); err != nil {
+		return nil, fmt.Errorf("failed to get post mentions: %w", err)
+	}
+	if details.liked, err = s.likeRepo.GetLikedPostIDs(ctx, viewerID, ids); err != nil {
+		return nil, fmt.Errorf("failed to get post likes: %w", err)
+	}
+	if details.saved, err = s.bookmarkRepo.GetSavedPostIDs(ctx, viewerID, ids); err != nil {
+		return nil, fmt.Errorf("failed to get bookmarks: %w", err)
+	}
+	if details.reactions, err = s.reactionRepo.GetCounts(ctx, models.ReactionOnPost, ids); err != nil {
+		return nil, fmt.Errorf("failed to get post reactions: %w", err)
+	}
+	if details.myReactions, err = s.reactionRepo.GetUserReactions(ctx, viewerID, models.ReactionOnPost, ids); err != nil {
+		return nil, fmt.Errorf("failed to get viewer reactions: %w", err)
+	}
+	return details, nil
+}
+
+// extractMentions finds the @usernames of text. They are resolved to users
+// when the text is saved.
+func extractMentions(text string) ([]models.Mention, error) {
+	found := mention.Extract(text)
+	if len(mention.Usernames(found)) > maxMentionedUsers {
+		return nil, invalid(fmt.Sprintf("you can mention at most %d people", maxMentionedUsers))
+	}
+
+	mentions := make([]models.Mention, 0, len(found))
+	for _, m := range found {
+		mentions = append(mentions, models.Mention{Username: m.Username, Offset: m.Offset, Length: m.Length})
+	}
+	return mentions, nil
+}
+
+func toMentionEntities(mentions []models.Mention) []dto.MentionEntity {
+	entities := make([]dto.MentionEntity, 0, len(mentions))
+	for _, m := range mentions {
+		entities = append(entities, dto.MentionEntity{
+			Offset:   m.Offset,
+			Length:   m.Length,
+			UserID:   m.MentionedUserID,
+			Username: m.Username,
+		})
+	}
+	return entities
+}
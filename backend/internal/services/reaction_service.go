@@ -0,0 +1,139 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+)
+
+// ReactionPalette lists the emoji users can react with, in display order
+var ReactionPalette = []string{"❤️", "😂", "😮", "😢", "😡", "👍"}
+
+type ReactionService interface {
+	React(ctx context.Context, userID int, target models.ReactionTarget, targetID int, req dto.ReactRequest) (*dto.ReactionSummaryResponse, error)
+	Unreact(ctx context.Context, userID int, target models.ReactionTarget, targetID int) (*dto.ReactionSummaryResponse, error)
+	GetReactions(ctx context.Context, viewerID int, target models.ReactionTarget, targetID int, emoji string,
+		limit, offset int) ([]dto.ReactionResponse, int, error)
+}
+
+type reactionService struct {
+	reactionRepo repositories.ReactionRepository
+	postRepo     repositories.PostRepository
+	commentRepo  repositories.CommentRepository
+	followRepo   repositories.FollowRepository
+}
+
+func NewReactionService(reactionRepo repositories.ReactionRepository, postRepo repositories.PostRepository,
+	commentRepo repositories.CommentRepository, followRepo repositories.FollowRepository) ReactionService {
+	return &reactionService{
+		reactionRepo: reactionRepo,
+		postRepo:     postRepo,
+		commentRepo:  commentRepo,
+		followRepo:   followRepo,
+	}
+}
+
+// React sets the reaction of the user on an item they can see, replacing
+// their previous one
+func (s *reactionService) React(ctx context.Context, userID int, target models.ReactionTarget, targetID int,
+	req dto.ReactRequest) (*dto.ReactionSummaryResponse, error) {
+	if !slices.Contains(ReactionPalette, req.Emoji) {
+		return nil, invalid("unsupported reaction")
+	}
+	if err := s.checkVisible(ctx, userID, target, targetID); err != nil {
+		return nil, err
+	}
+
+	if err := s.reactionRepo.Set(ctx, userID, target, targetID, req.Emoji); err != nil {
+		return nil, fmt.Errorf("failed to save reaction: %w", err)
+	}
+	return s.summary(ctx, userID, target, targetID)
+}
+
+// Unreact removes the reaction of the user. Removing a missing reaction is
+// not an error.
+func (s *reactionService) Unreact(ctx context.Context, userID int, target models.ReactionTarget, targetID int) (*dto.ReactionSummaryResponse, error) {
+	if err := s.reactionRepo.Remove(ctx, userID, target, targetID); err != nil {
+		return nil, fmt.Errorf("failed to remove reaction: %w", err)
+	}
+	return s.summary(ctx, userID, target, targetID)
+}
+
+// GetReactions lists who reacted to an item, optionally with a single emoji
+func (s *reactionService) GetReactions(ctx context.Context, viewerID int, target models.ReactionTarget, targetID int, emoji string,
+	limit, offset int) ([]dto.ReactionResponse, int, error) {
+	if emoji != "" && !slices.Contains(ReactionPalette, emoji) {
+		return nil, 0, invalid("unsupported reaction")
+	}
+	if err := s.checkVisible(ctx, viewerID, target, targetID); err != nil {
+		return nil, 0, err
+	}
+
+	reactions, total, err := s.reactionRepo.GetByTarget(ctx, viewerID, target, targetID, emoji, limit, offset)
+	if err != nil {
+		return nil, 0, fmt.Errorf("service failed to get reactions from repo: %w", err)
+	}
+
+	users := make([]dto.UserResponse, 0, len(reactions))
+	for i := range reactions {
+		users = append(users, *toUserResponse(&reactions[i].User))
+	}
+	if err := attachRelationships(ctx, s.followRepo, viewerID, users); err != nil {
+		return nil, 0, err
+	}
+
+	responses := make([]dto.ReactionResponse, 0, len(reactions))
+	for i := range reactions {
+		responses = append(responses, dto.ReactionResponse{
+			User:      users[i],
+			Emoji:     reactions[i].Emoji,
+			CreatedAt: reactions[i].CreatedAt,
+		})
+	}
+	return responses, total, nil
+}
+
+// checkVisible reports items hidden from the viewer as missing
+func (s *reactionService) checkVisible(ctx context.Context, viewerID int, target models.ReactionTarget, targetID int) error {
+	var err error
+	if target == models.ReactionOnComment {
+		_, err = s.commentRepo.GetVisibleByID(ctx, viewerID, targetID)
+	} else {
+		_, err = s.postRepo.GetVisibleByID(ctx, viewerID, targetID)
+	}
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return notFound(fmt.Sprintf("%s not found", target))
+		}
+		return fmt.Errorf("failed to get %s: %w", target, err)
+	}
+	return nil
+}
+
+func (s *reactionService) summary(ctx context.Context, userID int, target models.ReactionTarget, targetID int) (*dto.ReactionSummaryResponse, error) {
+	counts, err := s.reactionRepo.GetCounts(ctx, target, []int{targetID})
+	if err != nil {
+		return nil, err
+	}
+	mine, err := s.reactionRepo.GetUserReactions(ctx, userID, target, []int{targetID})
+	if err != nil {
+		return nil, err
+	}
+	return &dto.ReactionSummaryResponse{
+		Reactions:  toReactionCounts(counts[targetID]),
+		MyReaction: mine[targetID],
+	}, nil
+}
+
+func toReactionCounts(counts []models.ReactionCount) []dto.ReactionCountResponse {
+	responses := make([]dto.ReactionCountResponse, 0, len(counts))
+	for _, count := range counts {
+		responses = append(responses, dto.ReactionCountResponse{Emoji: count.Emoji, Count: count.Count})
+	}
+	return responses
+}
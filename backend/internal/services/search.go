@@ -0,0 +1,50 @@
+package services
+
+import (
+	"html"
+	"strings"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/pkg/hashtag"
+	"github.com/escuadron-404/red404/backend/pkg/mention"
+)
+
+// snippetReplacer turns the match markers of an escaped snippet into HTML
+var snippetReplacer = strings.NewReplacer(models.SnippetMatchStart, "<mark>", models.SnippetMatchEnd, "</mark>")
+
+// toSearchFilter normalizes the search parameters. A search needs some text,
+// a hashtag or a mention so it never lists everything.
+func toSearchFilter(req dto.SearchRequest) (models.SearchFilter, error) {
+	filter := models.SearchFilter{
+		Text:     strings.TrimSpace(req.Query),
+		Language: models.SearchLanguage(req.Language),
+		From:     req.From,
+		To:       req.To,
+	}
+	if tag := strings.TrimSpace(req.Hashtag); tag != "" {
+		filter.Hashtag = hashtag.Normalize(tag)
+		if !hashtag.Valid(filter.Hashtag) {
+			return filter, invalid("invalid hashtag")
+		}
+	}
+	if username := strings.TrimSpace(req.Mention); username != "" {
+		filter.Mention = mention.Normalize(username)
+		if !mention.Valid(filter.Mention) {
+			return filter, invalid("invalid username")
+		}
+	}
+
+	if filter.Text == "" && filter.Hashtag == "" && filter.Mention == "" {
+		return filter, invalid("a search needs some text, a hashtag or a mention")
+	}
+	if filter.From != nil && filter.To != nil && !filter.From.Before(*filter.To) {
+		return filter, invalid("the date range is empty")
+	}
+	return filter, nil
+}
+
+// highlightSnippet escapes a snippet and wraps its matches in <mark> tags
+func highlightSnippet(snippet string) string {
+	return snippetReplacer.Replace(html.EscapeString(snippet))
+}
@@ -0,0 +1,115 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+)
+
+const (
+	// Suggestions cached per user, requests can ask for fewer
+	cachedSuggestions = 50
+	// Users refreshed per background job run
+	suggestionRefreshBatch = 200
+)
+
+type SuggestionService interface {
+	GetSuggestions(ctx context.Context, userID, limit int) ([]dto.SuggestionResponse, error)
+	RefreshStale(ctx context.Context) error
+}
+
+type suggestionService struct {
+	suggestionRepo repositories.SuggestionRepository
+	followRepo     repositories.FollowRepository
+	ttl            time.Duration
+}
+
+func NewSuggestionService(suggestionRepo repositories.SuggestionRepository, followRepo repositories.FollowRepository, ttl time.Duration) SuggestionService {
+	return &suggestionService{
+		suggestionRepo: suggestionRepo,
+		followRepo:     followRepo,
+		ttl:            ttl,
+	}
+}
+
+// GetSuggestions serves the cached suggestions, computing them on the spot
+// when the background job has not reached this user yet
+func (s *suggestionService) GetSuggestions(ctx context.Context, userID, limit int) ([]dto.SuggestionResponse, error) {
+	if limit > cachedSuggestions {
+		limit = cachedSuggestions
+	}
+
+	computedAt, err := s.suggestionRepo.GetComputedAt(ctx, userID)
+	if err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return nil, fmt.Errorf("failed to check suggestions cache: %w", err)
+	}
+	if errors.Is(err, repositories.ErrNotFound) || time.Since(computedAt) > s.ttl {
+		if err := s.suggestionRepo.Compute(ctx, userID, cachedSuggestions); err != nil {
+			return nil, fmt.Errorf("failed to compute suggestions: %w", err)
+		}
+	}
+
+	suggestions, err := s.suggestionRepo.GetForUser(ctx, userID, limit)
+	if err != nil {
+		return nil, fmt.Errorf("service failed to get suggestions from repo: %w", err)
+	}
+
+	responses := make([]dto.SuggestionResponse, 0, len(suggestions))
+	users := make([]dto.UserResponse, 0, len(suggestions))
+	for i := range suggestions {
+		users = append(users, *toUserResponse(&suggestions[i].User))
+	}
+	if err := attachRelationships(ctx, s.followRepo, userID, users); err != nil {
+		return nil, err
+	}
+
+	for i := range suggestions {
+		responses = append(responses, dto.SuggestionResponse{
+			User:            users[i],
+			MutualFollowers: suggestions[i].MutualCount,
+			SharedHashtags:  suggestions[i].SharedTags,
+			Reason:          suggestionReason(suggestions[i].MutualCount, suggestions[i].SharedTags),
+		})
+	}
+
+	return responses, nil
+}
+
+// RefreshStale recomputes the oldest caches. It is run periodically by the
+// suggestions job.
+func (s *suggestionService) RefreshStale(ctx context.Context) error {
+	userIDs, err := s.suggestionRepo.GetStaleUserIDs(ctx, time.Now().Add(-s.ttl), suggestionRefreshBatch)
+	if err != nil {
+		return err
+	}
+
+	for _, userID := range userIDs {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+		if err := s.suggestionRepo.Compute(ctx, userID, cachedSuggestions); err != nil {
+			log.Printf("Failed to refresh suggestions for user %d: %v", userID, err)
+		}
+	}
+
+	if len(userIDs) > 0 {
+		log.Printf("Refreshed follow suggestions for %d users", len(userIDs))
+	}
+	return nil
+}
+
+func suggestionReason(mutualCount, sharedTags int) string {
+	switch {
+	case mutualCount > 0:
+		return "followed_by_people_you_follow"
+	case sharedTags > 0:
+		return "shared_interests"
+	default:
+		return "popular"
+	}
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+)
+
+const (
+	// Trending posts and hashtags kept by each refresh, explore pages
+	// through these
+	trendingPostsKept    = 500
+	trendingHashtagsKept = 50
+)
+
+// TrendingOptions configures how the explore page is computed
+type TrendingOptions struct {
+	// Window is how far back engagement counts towards trending
+	Window time.Duration
+	// MaxPostsPerAuthor keeps one account from filling the explore page
+	MaxPostsPerAuthor int
+}
+
+type TrendingService interface {
+	Refresh(ctx context.Context) error
+}
+
+type trendingService struct {
+	trendingRepo repositories.TrendingRepository
+	options      TrendingOptions
+}
+
+func NewTrendingService(trendingRepo repositories.TrendingRepository, options TrendingOptions) TrendingService {
+	return &trendingService{
+		trendingRepo: trendingRepo,
+		options:      options,
+	}
+}
+
+// Refresh recomputes the trending posts and hashtags over the sliding window
+// ending now. It runs as a background job so explore reads stay cheap.
+func (s *trendingService) Refresh(ctx context.Context) error {
+	now := time.Now()
+	if err := s.trendingRepo.Refresh(ctx, now.Add(-s.options.Window), now, s.options.MaxPostsPerAuthor,
+		trendingPostsKept, trendingHashtagsKept); err != nil {
+		return fmt.Errorf("failed to refresh trending: %w", err)
+	}
+	return nil
+}
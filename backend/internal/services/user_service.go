@@ -2,33 +2,49 @@ package services
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/escuadron-404/red404/backend/internal/dto"
 	"github.com/escuadron-404/red404/backend/internal/models"
 	"github.com/escuadron-404/red404/backend/internal/repositories"
+	"github.com/escuadron-404/red404/backend/pkg/mention"
+	"github.com/escuadron-404/red404/backend/pkg/realtime"
 	"github.com/escuadron-404/red404/backend/pkg/utils"
 	"github.com/go-playground/validator/v10"
 )
 
 type UserService interface {
 	CreateUser(ctx context.Context, req dto.CreateUserRequest) (*dto.UserResponse, error)
-	GetUserByID(ctx context.Context, id int) (*dto.UserResponse, error)
-	GetAllUsers(ctx context.Context, limit, offset int) ([]dto.UserResponse, int, error)
+	GetUserByID(ctx context.Context, viewerID, id int) (*dto.UserResponse, error)
+	GetAllUsers(ctx context.Context, viewerID, limit, offset int) ([]dto.UserResponse, int, error)
 	UpdateUser(ctx context.Context, id int, req dto.UpdateUserRequest) (*dto.UserResponse, error)
 	DeleteUser(ctx context.Context, id int) error
+	UpdatePrivacy(ctx context.Context, id int, req dto.UpdatePrivacyRequest) (*dto.UserResponse, error)
+	UpdateUsername(ctx context.Context, id int, req dto.UpdateUsernameRequest) (*dto.UserResponse, error)
+	UpdateDMPolicy(ctx context.Context, id int, req dto.UpdateDMPolicyRequest) (*dto.UserResponse, error)
 }
 
 type userService struct {
-	repo      repositories.UserRepository
-	validator *validator.Validate
+	repo         repositories.UserRepository
+	followRepo   repositories.FollowRepository
+	timelineRepo repositories.TimelineRepository
+	events       realtime.Publisher
+	validator    *validator.Validate
+	feed         FeedOptions
 }
 
-func NewUserService(repo repositories.UserRepository, userValidator *validator.Validate) UserService {
+func NewUserService(repo repositories.UserRepository, followRepo repositories.FollowRepository,
+	timelineRepo repositories.TimelineRepository, events realtime.Publisher, userValidator *validator.Validate,
+	feed FeedOptions) UserService {
 	return &userService{
-		repo:      repo,
-		validator: userValidator,
+		repo:         repo,
+		followRepo:   followRepo,
+		timelineRepo: timelineRepo,
+		events:       events,
+		validator:    userValidator,
+		feed:         feed,
 	}
 }
 
@@ -44,6 +60,11 @@ func (s *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 		return nil, fmt.Errorf("user with email %s already exists", req.Email)
 	}
 
+	username, err := chooseUsername(ctx, s.repo, "", req.Email)
+	if err != nil {
+		return nil, err
+	}
+
 	// Hash password
 	hashedPassword, err := utils.HashPassword(req.Password)
 	if err != nil {
@@ -54,6 +75,7 @@ func (s *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 	now := time.Now()
 	user := &models.User{
 		Email:     req.Email,
+		Username:  username,
 		Password:  hashedPassword,
 		CreatedAt: now,
 		UpdatedAt: now,
@@ -65,42 +87,32 @@ func (s *userService) CreateUser(ctx context.Context, req dto.CreateUserRequest)
 	}
 
 	// Return response
-	return &dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}, nil
+	return toUserResponse(user), nil
 }
 
-func (s *userService) GetUserByID(ctx context.Context, id int) (*dto.UserResponse, error) {
-	user, err := s.repo.GetByID(ctx, id)
+func (s *userService) GetUserByID(ctx context.Context, viewerID, id int) (*dto.UserResponse, error) {
+	user, err := s.repo.GetVisibleByID(ctx, viewerID, id)
 	if err != nil {
 		return nil, err
 	}
 
-	return &dto.UserResponse{
-		ID:        user.ID,
-		Email:     user.Email,
-		CreatedAt: user.CreatedAt,
-		UpdatedAt: user.UpdatedAt,
-	}, nil
+	userResponses := []dto.UserResponse{*toUserResponse(user)}
+	if err := attachRelationships(ctx, s.followRepo, viewerID, userResponses); err != nil {
+		return nil, err
+	}
+
+	return &userResponses[0], nil
 }
 
-func (s *userService) GetAllUsers(ctx context.Context, limit, offset int) ([]dto.UserResponse, int, error) {
-	users, totalCount, err := s.repo.GetAll(ctx, limit, offset)
+func (s *userService) GetAllUsers(ctx context.Context, viewerID, limit, offset int) ([]dto.UserResponse, int, error) {
+	users, totalCount, err := s.repo.GetAll(ctx, viewerID, limit, offset)
 	if err != nil {
 		return nil, 0, fmt.Errorf("service failed to get paginated users from repo: %w", err)
 	}
 
-	userResponses := make([]dto.UserResponse, 0, len(users))
-	for _, user := range users {
-		userResponses = append(userResponses, dto.UserResponse{
-			ID:        user.ID,
-			Email:     user.Email,
-			CreatedAt: user.CreatedAt,
-			UpdatedAt: user.UpdatedAt,
-		})
+	userResponses := toUserResponses(users)
+	if err := attachRelationships(ctx, s.followRepo, viewerID, userResponses); err != nil {
+		return nil, 0, err
 	}
 
 	return userResponses, totalCount, nil
@@ -137,12 +149,7 @@ func (s *userService) UpdateUser(ctx context.Context, id int, req dto.UpdateUser
 	}
 
 	// Return response
-	return &dto.UserResponse{
-		ID:        existingUser.ID,
-		Email:     existingUser.Email,
-		CreatedAt: existingUser.CreatedAt,
-		UpdatedAt: existingUser.UpdatedAt,
-	}, nil
+	return toUserResponse(existingUser), nil
 }
 
 func (s *userService) DeleteUser(ctx context.Context, id int) error {
@@ -159,3 +166,136 @@ func (s *userService) DeleteUser(ctx context.Context, id int) error {
 
 	return nil
 }
+
+func (s *userService) UpdatePrivacy(ctx context.Context, id int, req dto.UpdatePrivacyRequest) (*dto.UserResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound("user not found")
+	}
+
+	if err := s.repo.SetPrivate(ctx, id, *req.IsPrivate); err != nil {
+		return nil, fmt.Errorf("failed to update privacy: %w", err)
+	}
+
+	wentPublic := user.IsPrivate && !*req.IsPrivate
+	user.IsPrivate = *req.IsPrivate
+	user.UpdatedAt = time.Now()
+	response := toUserResponse(user)
+
+	// Nobody has to wait for approval on a public account. The new followers
+	// are handled like requests accepted one by one.
+	if wentPublic {
+		senders, err := s.followRepo.AcceptAllPending(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("failed to accept pending follow requests: %w", err)
+		}
+		for _, senderID := range senders {
+			enqueueFanout(s.feed, func() error { return s.timelineRepo.EnqueueFollow(ctx, senderID, id) })
+		}
+		s.events.Publish(senders, realtime.Event{Type: EventFollowAccepted, Data: dto.FollowEvent{User: *response}})
+	}
+	return response, nil
+}
+
+// UpdateUsername renames the user. Existing mentions keep pointing at them
+// since they are stored by user id.
+func (s *userService) UpdateUsername(ctx context.Context, id int, req dto.UpdateUsernameRequest) (*dto.UserResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	username := mention.Normalize(req.Username)
+	if !mention.Valid(username) {
+		return nil, errInvalidUsername
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound("user not found")
+	}
+	if user.Username == username {
+		return toUserResponse(user), nil
+	}
+
+	if err := s.repo.SetUsername(ctx, id, username); err != nil {
+		if errors.Is(err, repositories.ErrConflict) {
+			return nil, conflict("username is already taken")
+		}
+		return nil, fmt.Errorf("failed to update username: %w", err)
+	}
+
+	user.Username = username
+	user.UpdatedAt = time.Now()
+	return toUserResponse(user), nil
+}
+
+// UpdateDMPolicy sets who can message the user. Conversations the user
+// already replied in are not affected.
+func (s *userService) UpdateDMPolicy(ctx context.Context, id int, req dto.UpdateDMPolicyRequest) (*dto.UserResponse, error) {
+	if err := s.validator.Struct(req); err != nil {
+		return nil, err
+	}
+
+	user, err := s.repo.GetByID(ctx, id)
+	if err != nil {
+		return nil, notFound("user not found")
+	}
+
+	policy := models.DMPolicy(req.DMPolicy)
+	if err := s.repo.SetDMPolicy(ctx, id, policy); err != nil {
+		return nil, fmt.Errorf("failed to update message settings: %w", err)
+	}
+
+	user.DMPolicy = policy
+	user.UpdatedAt = time.Now()
+	return toUserResponse(user), nil
+}
+
+func toUserResponse(user *models.User) *dto.UserResponse {
+	return &dto.UserResponse{
+		ID:        user.ID,
+		Email:     user.Email,
+		Username:  user.Username,
+		IsPrivate: user.IsPrivate,
+		DMPolicy:  string(user.DMPolicy),
+		CreatedAt: user.CreatedAt,
+		UpdatedAt: user.UpdatedAt,
+
+		FollowersCount: user.FollowersCount,
+		FollowingCount: user.FollowingCount,
+	}
+}
+
+func toUserResponses(users []models.User) []dto.UserResponse {
+	userResponses := make([]dto.UserResponse, 0, len(users))
+	for i := range users {
+		userResponses = append(userResponses, *toUserResponse(&users[i]))
+	}
+	return userResponses
+}
+
+// attachRelationships fills the viewer relative flags of every user response
+func attachRelationships(ctx context.Context, followRepo repositories.FollowRepository, viewerID int, users []dto.UserResponse) error {
+	ids := make([]int, 0, len(users))
+	for i := range users {
+		ids = append(ids, users[i].ID)
+	}
+
+	relationships, err := followRepo.GetRelationships(ctx, viewerID, ids)
+	if err != nil {
+		return fmt.Errorf("failed to load relationships: %w", err)
+	}
+
+	for i := range users {
+		relationship := relationships[users[i].ID]
+		users[i].IsFollowing = relationship.IsFollowing
+		users[i].FollowsYou = relationship.FollowsYou
+		users[i].FollowRequested = relationship.FollowRequested
+		users[i].IsMuted = relationship.IsMuted
+	}
+	return nil
+}
@@ -0,0 +1,83 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"strings"
+
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+	"github.com/escuadron-404/red404/backend/pkg/mention"
+)
+
+// usernameAttempts bounds how many random suffixes are tried before giving up
+const usernameAttempts = 5
+
+var errInvalidUsername = invalid(fmt.Sprintf(
+	"username must be %d to %d lowercase letters, digits, '_' or '.'", mention.MinLength, mention.MaxLength))
+
+// chooseUsername checks the username asked for at sign up or, when none was
+// given, derives a free one from the local part of the email
+func chooseUsername(ctx context.Context, userRepo repositories.UserRepository, requested, email string) (string, error) {
+	if requested != "" {
+		username := mention.Normalize(requested)
+		if !mention.Valid(username) {
+			return "", errInvalidUsername
+		}
+		taken, err := usernameTaken(ctx, userRepo, username)
+		if err != nil {
+			return "", err
+		}
+		if taken {
+			return "", conflict("username is already taken")
+		}
+		return username, nil
+	}
+
+	base := usernameFromEmail(email)
+	candidate := base
+	for range usernameAttempts {
+		taken, err := usernameTaken(ctx, userRepo, candidate)
+		if err != nil {
+			return "", err
+		}
+		if !taken {
+			return candidate, nil
+		}
+		candidate = fmt.Sprintf("%s%04d", base, rand.IntN(10000))
+	}
+	return "", conflict("could not find a free username, please pick one")
+}
+
+// usernameFromEmail keeps the characters of the email local part allowed in
+// usernames, leaving room for a numeric suffix
+func usernameFromEmail(email string) string {
+	local, _, _ := strings.Cut(strings.ToLower(email), "@")
+	var b strings.Builder
+	for _, r := range local {
+		if r >= 'a' && r <= 'z' || r >= '0' && r <= '9' || r == '_' || r == '.' {
+			b.WriteRune(r)
+		}
+	}
+
+	username := strings.Trim(b.String(), ".")
+	if len(username) > mention.MaxLength-4 {
+		username = username[:mention.MaxLength-4]
+	}
+	if len(username) < mention.MinLength {
+		username = "user" + username
+	}
+	return username
+}
+
+func usernameTaken(ctx context.Context, userRepo repositories.UserRepository, username string) (bool, error) {
+	_, err := userRepo.GetByUsername(ctx, username)
+	if errors.Is(err, repositories.ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("failed to check username: %w", err)
+	}
+	return true, nil
+}
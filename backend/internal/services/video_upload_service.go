@@ -0,0 +1,356 @@
+package services
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/internal/dto"
+	"github.com/escuadron-404/red404/backend/internal/models"
+	"github.com/escuadron-404/red404/backend/internal/repositories"
+	"github.com/escuadron-404/red404/backend/pkg/storage"
+	"github.com/escuadron-404/red404/backend/pkg/video"
+)
+
+const (
+	// Poster frames are taken one second in, or halfway through shorter clips
+	posterOffset   = time.Second
+	posterMaxWidth = 720
+	// Upper bound for ffprobe and ffmpeg on a single upload
+	videoProcessTimeout = 2 * time.Minute
+	// Expired uploads removed per cleanup job run
+	videoCleanupBatch = 100
+)
+
+// VideoUploadOptions configures resumable video uploads
+type VideoUploadOptions struct {
+	Storage     storage.Storage
+	Tools       video.Tools
+	SpoolDir    string
+	MaxBytes    int64
+	MaxDuration time.Duration
+	UploadTTL   time.Duration
+}
+
+type VideoUploadService interface {
+	CreateUpload(ctx context.Context, userID int, length int64, filename string) (*models.VideoUpload, error)
+	GetUpload(ctx context.Context, userID int, id string) (*models.VideoUpload, error)
+	GetUploadStatus(ctx context.Context, userID int, id string) (*dto.VideoUploadResponse, error)
+	AppendChunk(ctx context.Context, userID int, id string, offset int64, chunk io.Reader) (*models.VideoUpload, error)
+	DeleteUpload(ctx context.Context, userID int, id string) error
+	CleanupExpired(ctx context.Context) error
+	MaxBytes() int64
+}
+
+type videoUploadService struct {
+	uploadRepo repositories.VideoUploadRepository
+	opts       VideoUploadOptions
+
+	// locks serializes chunks of the same upload so their writes to the
+	// spool file cannot interleave
+	locks sync.Map
+}
+
+func NewVideoUploadService(uploadRepo repositories.VideoUploadRepository, opts VideoUploadOptions) (VideoUploadService, error) {
+	if err := os.MkdirAll(opts.SpoolDir, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create video spool directory: %w", err)
+	}
+	return &videoUploadService{
+		uploadRepo: uploadRepo,
+		opts:       opts,
+	}, nil
+}
+
+func (s *videoUploadService) MaxBytes() int64 {
+	return s.opts.MaxBytes
+}
+
+// CreateUpload registers a new upload of length bytes and creates its empty
+// spool file
+func (s *videoUploadService) CreateUpload(ctx context.Context, userID int, length int64, filename string) (*models.VideoUpload, error) {
+	if length <= 0 {
+		return nil, invalid("upload length must be positive")
+	}
+	if length > s.opts.MaxBytes {
+		return nil, tooLarge(fmt.Sprintf("videos must be at most %d MB", s.opts.MaxBytes>>20))
+	}
+
+	buf := make([]byte, 16)
+	if _, err := rand.Read(buf); err != nil {
+		return nil, fmt.Errorf("failed to generate upload id: %w", err)
+	}
+
+	upload := &models.VideoUpload{
+		ID:           hex.EncodeToString(buf),
+		UserID:       userID,
+		UploadLength: length,
+		Filename:     truncate(filename, 255),
+		ExpiresAt:    time.Now().Add(s.opts.UploadTTL),
+	}
+
+	file, err := os.OpenFile(s.spoolPath(upload.ID), os.O_CREATE|os.O_EXCL|os.O_WRONLY, 0o600)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create spool file: %w", err)
+	}
+	file.Close()
+
+	if err := s.uploadRepo.Create(ctx, upload); err != nil {
+		os.Remove(s.spoolPath(upload.ID))
+		return nil, fmt.Errorf("failed to create upload: %w", err)
+	}
+	return upload, nil
+}
+
+// GetUpload returns an upload of userID. Uploads of other users are reported
+// as missing.
+func (s *videoUploadService) GetUpload(ctx context.Context, userID int, id string) (*models.VideoUpload, error) {
+	upload, err := s.uploadRepo.GetByID(ctx, id)
+	if err != nil {
+		if errors.Is(err, repositories.ErrNotFound) {
+			return nil, notFound("upload not found")
+		}
+		return nil, fmt.Errorf("failed to get upload: %w", err)
+	}
+	if upload.UserID != userID {
+		return nil, notFound("upload not found")
+	}
+	return upload, nil
+}
+
+func (s *videoUploadService) GetUploadStatus(ctx context.Context, userID int, id string) (*dto.VideoUploadResponse, error) {
+	upload, err := s.GetUpload(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	return &dto.VideoUploadResponse{
+		ID:           upload.ID,
+		Status:       string(upload.Status),
+		Error:        upload.Error,
+		UploadLength: upload.UploadLength,
+		UploadOffset: upload.UploadOffset,
+		Width:        upload.Width,
+		Height:       upload.Height,
+		DurationMS:   upload.DurationMS,
+		ExpiresAt:    upload.ExpiresAt,
+	}, nil
+}
+
+// AppendChunk writes chunk at offset, which must match the bytes received so
+// far. Whatever arrived before the client disconnected is kept so the upload
+// can be resumed. The last chunk triggers validation and processing.
+func (s *videoUploadService) AppendChunk(ctx context.Context, userID int, id string, offset int64, chunk io.Reader) (*models.VideoUpload, error) {
+	lock, _ := s.locks.LoadOrStore(id, &sync.Mutex{})
+	lock.(*sync.Mutex).Lock()
+	defer lock.(*sync.Mutex).Unlock()
+
+	upload, err := s.GetUpload(ctx, userID, id)
+	if err != nil {
+		return nil, err
+	}
+	if upload.Status != models.VideoUploadUploading {
+		return nil, conflict(fmt.Sprintf("upload is already %s", upload.Status))
+	}
+	if time.Now().After(upload.ExpiresAt) {
+		return nil, gone("upload has expired")
+	}
+	if offset != upload.UploadOffset {
+		return nil, conflict(fmt.Sprintf("upload offset is %d", upload.UploadOffset))
+	}
+
+	written, writeErr := s.writeChunk(upload, chunk)
+	if written > 0 {
+		if err := s.uploadRepo.UpdateOffset(ctx, id, upload.UploadOffset, upload.UploadOffset+written); err != nil {
+			return nil, fmt.Errorf("failed to update upload offset: %w", err)
+		}
+		upload.UploadOffset += written
+	}
+	if writeErr != nil {
+		return nil, writeErr
+	}
+
+	if upload.UploadOffset == upload.UploadLength {
+		// Later requests find the upload ready or failed, or retry a
+		// processing that broke down after the last byte arrived
+		defer s.locks.Delete(id)
+		if err := s.process(ctx, upload); err != nil {
+			return nil, err
+		}
+	}
+	return upload, nil
+}
+
+// writeChunk appends at most the missing bytes of the upload to its spool
+// file and reports how many were written
+func (s *videoUploadService) writeChunk(upload *models.VideoUpload, chunk io.Reader) (int64, error) {
+	file, err := os.OpenFile(s.spoolPath(upload.ID), os.O_WRONLY, 0o600)
+	if err != nil {
+		return 0, fmt.Errorf("failed to open spool file: %w", err)
+	}
+	defer file.Close()
+
+	if _, err := file.Seek(upload.UploadOffset, io.SeekStart); err != nil {
+		return 0, fmt.Errorf("failed to seek spool file: %w", err)
+	}
+	remaining := upload.UploadLength - upload.UploadOffset
+	written, err := io.Copy(file, io.LimitReader(chunk, remaining))
+	if err != nil {
+		// An interrupted request still counts what was received
+		log.Printf("Video upload %s interrupted after %d bytes: %v", upload.ID, written, err)
+		err = nil
+	}
+	return written, err
+}
+
+// process validates the finished upload and moves the video and its poster
+// frame to storage. Rejected files mark the upload as failed. The spool file
+// is kept after unexpected errors so processing can be retried.
+func (s *videoUploadService) process(ctx context.Context, upload *models.VideoUpload) error {
+	// Processing goes on even if the client stops waiting for the response
+	ctx, cancel := context.WithTimeout(context.WithoutCancel(ctx), videoProcessTimeout)
+	defer cancel()
+
+	src := s.spoolPath(upload.ID)
+
+	info, err := s.opts.Tools.Probe(ctx, src)
+	if err != nil {
+		if errors.Is(err, video.ErrUnsupported) {
+			return s.fail(ctx, upload, "unsupported video, use H.264/HEVC in MP4 or VP8/VP9/AV1 in WebM")
+		}
+		return fmt.Errorf("failed to probe video: %w", err)
+	}
+	if info.Duration > s.opts.MaxDuration {
+		return s.fail(ctx, upload, fmt.Sprintf("videos must be at most %d seconds long", int(s.opts.MaxDuration.Seconds())))
+	}
+
+	poster := src + ".jpg"
+	defer os.Remove(poster)
+	if err := s.opts.Tools.ExtractPoster(ctx, src, poster, min(posterOffset, info.Duration/2), posterMaxWidth); err != nil {
+		return s.fail(ctx, upload, "could not extract a frame from the video")
+	}
+
+	ext := ".mp4"
+	if info.ContentType == "video/webm" {
+		ext = ".webm"
+	}
+	upload.StorageKey = fmt.Sprintf("posts/%d/%s%s", upload.UserID, upload.ID, ext)
+	upload.PosterKey = fmt.Sprintf("posts/%d/%s-poster.jpg", upload.UserID, upload.ID)
+	upload.ContentType = info.ContentType
+	upload.Width = info.Width
+	upload.Height = info.Height
+	upload.DurationMS = int(info.Duration.Milliseconds())
+
+	if err := s.putFile(ctx, upload.StorageKey, src, info.ContentType); err != nil {
+		return err
+	}
+	if err := s.putFile(ctx, upload.PosterKey, poster, "image/jpeg"); err != nil {
+		s.deleteObjects(ctx, upload.StorageKey)
+		return err
+	}
+
+	if err := s.uploadRepo.MarkReady(ctx, upload); err != nil {
+		s.deleteObjects(ctx, upload.StorageKey, upload.PosterKey)
+		return fmt.Errorf("failed to mark upload ready: %w", err)
+	}
+	s.removeSpool(upload.ID)
+	return nil
+}
+
+func (s *videoUploadService) fail(ctx context.Context, upload *models.VideoUpload, reason string) error {
+	if err := s.uploadRepo.MarkFailed(ctx, upload.ID, reason); err != nil {
+		return fmt.Errorf("failed to mark upload failed: %w", err)
+	}
+	upload.Status = models.VideoUploadFailed
+	upload.Error = reason
+	s.removeSpool(upload.ID)
+	return invalid(reason)
+}
+
+func (s *videoUploadService) putFile(ctx context.Context, key, path, contentType string) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	stat, err := file.Stat()
+	if err != nil {
+		return err
+	}
+	if err := s.opts.Storage.Put(ctx, key, file, stat.Size(), contentType); err != nil {
+		return fmt.Errorf("failed to store %s: %w", key, err)
+	}
+	return nil
+}
+
+// DeleteUpload cancels an upload that has not been attached to a post yet
+func (s *videoUploadService) DeleteUpload(ctx context.Context, userID int, id string) error {
+	upload, err := s.GetUpload(ctx, userID, id)
+	if err != nil {
+		return err
+	}
+	if upload.Status == models.VideoUploadAttached {
+		return conflict("upload is already attached to a post")
+	}
+	return s.remove(ctx, upload)
+}
+
+// CleanupExpired removes uploads that were abandoned or never used in a post
+func (s *videoUploadService) CleanupExpired(ctx context.Context) error {
+	uploads, err := s.uploadRepo.GetExpired(ctx, time.Now(), videoCleanupBatch)
+	if err != nil {
+		return err
+	}
+	for i := range uploads {
+		if err := s.remove(ctx, &uploads[i]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *videoUploadService) remove(ctx context.Context, upload *models.VideoUpload) error {
+	if err := s.uploadRepo.Delete(ctx, upload.ID); err != nil && !errors.Is(err, repositories.ErrNotFound) {
+		return fmt.Errorf("failed to delete upload: %w", err)
+	}
+	s.locks.Delete(upload.ID)
+	s.removeSpool(upload.ID)
+	s.deleteObjects(ctx, upload.StorageKey, upload.PosterKey)
+	return nil
+}
+
+func (s *videoUploadService) deleteObjects(ctx context.Context, keys ...string) {
+	for _, key := range keys {
+		if key == "" {
+			continue
+		}
+		if err := s.opts.Storage.Delete(ctx, key); err != nil {
+			log.Printf("Failed to delete media %s: %v", key, err)
+		}
+	}
+}
+
+func (s *videoUploadService) removeSpool(id string) {
+	if err := os.Remove(s.spoolPath(id)); err != nil && !errors.Is(err, os.ErrNotExist) {
+		log.Printf("Failed to remove spool file of upload %s: %v", id, err)
+	}
+}
+
+func (s *videoUploadService) spoolPath(id string) string {
+	return filepath.Join(s.opts.SpoolDir, id)
+}
+
+func truncate(value string, maxRunes int) string {
+	runes := []rune(value)
+	if len(runes) <= maxRunes {
+		return value
+	}
+	return string(runes[:maxRunes])
+}
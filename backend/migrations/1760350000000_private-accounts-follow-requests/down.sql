@@ -0,0 +1,14 @@
+DROP TABLE follow_request;
+
+CREATE TABLE follow_request (
+    id UUID PRIMARY KEY,
+    sender_id UUID,
+    receiver_id UUID,
+    status VARCHAR(8) CHECK (status IN ('pending', 'accepted', 'rejected')),
+    UNIQUE (sender_id, receiver_id, status)
+);
+
+CREATE INDEX idx_follow_request_sender_id ON follow_request(sender_id);
+CREATE INDEX idx_follow_request_receiver_id ON follow_request(receiver_id);
+
+ALTER TABLE users DROP COLUMN is_private;
@@ -0,0 +1,19 @@
+ALTER TABLE users ADD COLUMN is_private BOOLEAN NOT NULL DEFAULT FALSE;
+
+-- follow_request was declared with UUID columns while users.id is SERIAL.
+-- Nothing ever wrote to it, so it is rebuilt with integer references.
+DROP TABLE follow_request;
+
+CREATE TABLE follow_request (
+    id SERIAL PRIMARY KEY,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    receiver_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    status VARCHAR(8) NOT NULL DEFAULT 'pending' CHECK (status IN ('pending', 'accepted', 'rejected')),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    CHECK (sender_id <> receiver_id)
+);
+
+CREATE UNIQUE INDEX idx_follow_request_pending ON follow_request(sender_id, receiver_id) WHERE status = 'pending';
+CREATE INDEX idx_follow_request_sender_id ON follow_request(sender_id);
+CREATE INDEX idx_follow_request_receiver_id ON follow_request(receiver_id, status);
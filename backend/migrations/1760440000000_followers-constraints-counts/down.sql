@@ -0,0 +1,16 @@
+ALTER TABLE users DROP COLUMN following_count;
+ALTER TABLE users DROP COLUMN followers_count;
+
+DROP INDEX idx_followers_follower_created;
+DROP INDEX idx_followers_followed_created;
+
+ALTER TABLE followers DROP CONSTRAINT followers_followed_id_fkey;
+ALTER TABLE followers DROP CONSTRAINT followers_follower_id_fkey;
+ALTER TABLE followers DROP CONSTRAINT followers_no_self_follow;
+ALTER TABLE followers DROP CONSTRAINT followers_unique_pair;
+
+ALTER TABLE followers
+    ALTER COLUMN follower_id DROP NOT NULL,
+    ALTER COLUMN followed_id DROP NOT NULL,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at DROP DEFAULT;
@@ -0,0 +1,33 @@
+-- Drop rows that would violate the new constraints before adding them
+DELETE FROM followers a USING followers b
+WHERE a.follower_id = b.follower_id AND a.followed_id = b.followed_id AND a.id > b.id;
+
+DELETE FROM followers
+WHERE follower_id IS NULL OR followed_id IS NULL OR follower_id = followed_id
+   OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = followers.follower_id)
+   OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = followers.followed_id);
+
+UPDATE followers SET created_at = now() WHERE created_at IS NULL;
+
+ALTER TABLE followers
+    ALTER COLUMN follower_id SET NOT NULL,
+    ALTER COLUMN followed_id SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT now();
+
+ALTER TABLE followers ADD CONSTRAINT followers_unique_pair UNIQUE (follower_id, followed_id);
+ALTER TABLE followers ADD CONSTRAINT followers_no_self_follow CHECK (follower_id <> followed_id);
+ALTER TABLE followers ADD CONSTRAINT followers_follower_id_fkey FOREIGN KEY (follower_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE followers ADD CONSTRAINT followers_followed_id_fkey FOREIGN KEY (followed_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Lists are ordered by most recent follow first
+CREATE INDEX idx_followers_followed_created ON followers(followed_id, created_at DESC, id DESC);
+CREATE INDEX idx_followers_follower_created ON followers(follower_id, created_at DESC, id DESC);
+
+-- Denormalized counters shown on profiles, maintained by the follow repository
+ALTER TABLE users ADD COLUMN followers_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE users ADD COLUMN following_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE users SET
+    followers_count = (SELECT COUNT(*) FROM followers WHERE followed_id = users.id),
+    following_count = (SELECT COUNT(*) FROM followers WHERE follower_id = users.id);
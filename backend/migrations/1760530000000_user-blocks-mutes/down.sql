@@ -0,0 +1,5 @@
+DROP INDEX idx_user_mutes_muted_id;
+DROP INDEX idx_user_blocks_blocked_id;
+
+DROP TABLE user_mutes;
+DROP TABLE user_blocks;
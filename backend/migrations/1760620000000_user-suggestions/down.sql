@@ -0,0 +1,6 @@
+DROP INDEX idx_users_followers_count;
+DROP INDEX idx_user_suggestions_state_computed_at;
+DROP INDEX idx_user_suggestions_user_score;
+
+DROP TABLE user_suggestions_state;
+DROP TABLE user_suggestions;
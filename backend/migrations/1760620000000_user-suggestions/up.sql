@@ -0,0 +1,22 @@
+-- Precomputed follow suggestions, refreshed by a background job
+CREATE TABLE user_suggestions (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    suggested_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    mutual_count INTEGER NOT NULL DEFAULT 0,
+    shared_tags INTEGER NOT NULL DEFAULT 0,
+    computed_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (user_id, suggested_id)
+);
+
+CREATE INDEX idx_user_suggestions_user_score ON user_suggestions(user_id, score DESC);
+
+-- Tracks when each user's suggestions were last computed, including users
+-- for whom nothing could be suggested
+CREATE TABLE user_suggestions_state (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    computed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_user_suggestions_state_computed_at ON user_suggestions_state(computed_at);
+CREATE INDEX idx_users_followers_count ON users(followers_count DESC);
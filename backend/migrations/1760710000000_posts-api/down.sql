@@ -0,0 +1,12 @@
+DROP INDEX idx_posts_user_created;
+
+ALTER TABLE posts DROP CONSTRAINT posts_user_id_fkey;
+
+ALTER TABLE posts
+    ALTER COLUMN user_id DROP NOT NULL,
+    ALTER COLUMN deleted DROP NOT NULL,
+    ALTER COLUMN deleted DROP DEFAULT,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN created_at DROP DEFAULT,
+    ALTER COLUMN updated_at DROP NOT NULL,
+    ALTER COLUMN updated_at DROP DEFAULT;
@@ -0,0 +1,23 @@
+-- Posts without an existing author cannot be served by the API
+DELETE FROM post_tags WHERE post_id IN (
+    SELECT id FROM posts WHERE user_id IS NULL OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = posts.user_id)
+);
+DELETE FROM posts WHERE user_id IS NULL OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = posts.user_id);
+
+UPDATE posts SET deleted = FALSE WHERE deleted IS NULL;
+UPDATE posts SET created_at = now() WHERE created_at IS NULL;
+UPDATE posts SET updated_at = created_at WHERE updated_at IS NULL;
+
+ALTER TABLE posts
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN deleted SET NOT NULL,
+    ALTER COLUMN deleted SET DEFAULT FALSE,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN updated_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT now();
+
+ALTER TABLE posts ADD CONSTRAINT posts_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+
+-- Profile pages list the live posts of an author newest first
+CREATE INDEX idx_posts_user_created ON posts(user_id, created_at DESC, id DESC) WHERE deleted = FALSE;
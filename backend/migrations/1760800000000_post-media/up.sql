@@ -0,0 +1,16 @@
+-- Ordered media items of a post. posts.image_url is kept for older posts.
+CREATE TABLE post_media (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    position SMALLINT NOT NULL,
+    media_type VARCHAR(16) NOT NULL,
+    storage_key TEXT NOT NULL,
+    content_type VARCHAR(100) NOT NULL,
+    width INTEGER NOT NULL,
+    height INTEGER NOT NULL,
+    alt_text VARCHAR(1000) NOT NULL DEFAULT '',
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT post_media_position_unique UNIQUE (post_id, position),
+    CONSTRAINT post_media_type_check CHECK (media_type IN ('image', 'video')),
+    CONSTRAINT post_media_position_check CHECK (position >= 0)
+);
@@ -0,0 +1,10 @@
+DROP INDEX idx_post_media_videos;
+
+ALTER TABLE post_media
+    DROP COLUMN duration_ms,
+    DROP COLUMN poster_key;
+
+DROP INDEX idx_video_uploads_expires_at;
+DROP INDEX idx_video_uploads_user_id;
+
+DROP TABLE video_uploads;
@@ -0,0 +1,32 @@
+-- Resumable (tus) video uploads. The bytes live in a local spool directory
+-- until the upload completes and the validated video is moved to storage.
+CREATE TABLE video_uploads (
+    id VARCHAR(32) PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    upload_length BIGINT NOT NULL,
+    upload_offset BIGINT NOT NULL DEFAULT 0,
+    filename VARCHAR(255) NOT NULL DEFAULT '',
+    status VARCHAR(16) NOT NULL DEFAULT 'uploading',
+    error TEXT NOT NULL DEFAULT '',
+    storage_key TEXT,
+    poster_key TEXT,
+    content_type VARCHAR(100),
+    width INTEGER,
+    height INTEGER,
+    duration_ms INTEGER,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now(),
+    expires_at TIMESTAMP NOT NULL,
+    CONSTRAINT video_uploads_status_check CHECK (status IN ('uploading', 'ready', 'failed', 'attached')),
+    CONSTRAINT video_uploads_offset_check CHECK (upload_offset >= 0 AND upload_offset <= upload_length)
+);
+
+CREATE INDEX idx_video_uploads_user_id ON video_uploads(user_id);
+CREATE INDEX idx_video_uploads_expires_at ON video_uploads(expires_at) WHERE status <> 'attached';
+
+ALTER TABLE post_media
+    ADD COLUMN poster_key TEXT,
+    ADD COLUMN duration_ms INTEGER;
+
+-- The Brainrot tab lists video posts only
+CREATE INDEX idx_post_media_videos ON post_media(post_id) WHERE media_type = 'video';
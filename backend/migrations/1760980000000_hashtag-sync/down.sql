@@ -0,0 +1,19 @@
+DROP INDEX idx_post_tags_tag_id;
+CREATE INDEX idx_post_tags_tag_id ON post_tags(tag_id);
+
+ALTER TABLE post_tags
+    DROP CONSTRAINT post_tags_post_id_fkey,
+    DROP CONSTRAINT post_tags_tag_id_fkey,
+    ADD CONSTRAINT post_tags_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id),
+    ADD CONSTRAINT post_tags_tag_id_fkey FOREIGN KEY (tag_id) REFERENCES hashtags(id),
+    ALTER COLUMN created_at DROP DEFAULT,
+    ALTER COLUMN created_at DROP NOT NULL;
+
+ALTER TABLE hashtags DROP COLUMN post_count;
+
+ALTER TABLE hashtags
+    ALTER COLUMN name DROP NOT NULL,
+    ALTER COLUMN created_at DROP DEFAULT,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN deleted DROP DEFAULT,
+    ALTER COLUMN deleted DROP NOT NULL;
@@ -0,0 +1,34 @@
+DELETE FROM post_tags WHERE post_id IS NULL OR tag_id IS NULL;
+DELETE FROM hashtags WHERE name IS NULL;
+
+UPDATE hashtags SET created_at = now() WHERE created_at IS NULL;
+UPDATE hashtags SET deleted = FALSE WHERE deleted IS NULL;
+UPDATE post_tags SET created_at = now() WHERE created_at IS NULL;
+
+ALTER TABLE hashtags
+    ALTER COLUMN name SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN deleted SET NOT NULL,
+    ALTER COLUMN deleted SET DEFAULT FALSE;
+
+-- Number of live posts using the tag, kept in sync with post_tags
+ALTER TABLE hashtags ADD COLUMN post_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE hashtags h SET post_count = (
+    SELECT COUNT(*) FROM post_tags pt
+    JOIN posts p ON p.id = pt.post_id
+    WHERE pt.tag_id = h.id AND p.deleted = FALSE
+);
+
+ALTER TABLE post_tags
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT now(),
+    DROP CONSTRAINT post_tags_post_id_fkey,
+    DROP CONSTRAINT post_tags_tag_id_fkey,
+    ADD CONSTRAINT post_tags_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE,
+    ADD CONSTRAINT post_tags_tag_id_fkey FOREIGN KEY (tag_id) REFERENCES hashtags(id) ON DELETE CASCADE;
+
+-- Hashtag pages list the posts of a tag
+DROP INDEX idx_post_tags_tag_id;
+CREATE INDEX idx_post_tags_tag_id ON post_tags(tag_id, post_id);
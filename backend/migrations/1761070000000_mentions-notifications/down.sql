@@ -0,0 +1,7 @@
+DROP TABLE notifications;
+DROP TABLE mentions;
+
+ALTER TABLE users
+    DROP CONSTRAINT users_username_check,
+    DROP CONSTRAINT users_username_key,
+    DROP COLUMN username;
@@ -0,0 +1,37 @@
+-- Existing accounts get a placeholder they can change later
+ALTER TABLE users ADD COLUMN username VARCHAR(30);
+UPDATE users SET username = 'user' || id WHERE username IS NULL;
+ALTER TABLE users
+    ALTER COLUMN username SET NOT NULL,
+    ADD CONSTRAINT users_username_key UNIQUE (username),
+    ADD CONSTRAINT users_username_check CHECK (username ~ '^[a-z0-9_.]{3,30}$');
+
+-- A mention belongs to a post, or to a comment of that post when comment_id
+-- is set. Offsets are in UTF-16 code units of the stored text.
+CREATE TABLE mentions (
+    id SERIAL PRIMARY KEY,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    mentioned_user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    start_offset INTEGER NOT NULL CHECK (start_offset >= 0),
+    length INTEGER NOT NULL CHECK (length > 0),
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_mentions_post_comment ON mentions(post_id, comment_id);
+CREATE INDEX idx_mentions_mentioned_user_id ON mentions(mentioned_user_id);
+
+CREATE TABLE notifications (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    actor_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    type VARCHAR(32) NOT NULL,
+    post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE,
+    comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+    read_at TIMESTAMP,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_notifications_user_created ON notifications(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_notifications_unread ON notifications(user_id) WHERE read_at IS NULL;
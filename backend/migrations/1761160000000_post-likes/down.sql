@@ -0,0 +1,11 @@
+ALTER TABLE posts DROP COLUMN like_count;
+
+ALTER TABLE likes DROP CONSTRAINT likes_post_id_fkey;
+ALTER TABLE likes DROP CONSTRAINT likes_user_id_fkey;
+ALTER TABLE likes DROP CONSTRAINT likes_unique_pair;
+
+ALTER TABLE likes
+    ALTER COLUMN user_id DROP NOT NULL,
+    ALTER COLUMN post_id DROP NOT NULL,
+    ALTER COLUMN created_at DROP DEFAULT,
+    ALTER COLUMN created_at DROP NOT NULL;
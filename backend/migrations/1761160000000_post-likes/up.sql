@@ -0,0 +1,25 @@
+-- Drop rows that would violate the new constraints before adding them
+DELETE FROM likes a USING likes b
+WHERE a.user_id = b.user_id AND a.post_id = b.post_id AND a.id > b.id;
+
+DELETE FROM likes
+WHERE user_id IS NULL OR post_id IS NULL
+   OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = likes.user_id)
+   OR NOT EXISTS (SELECT 1 FROM posts WHERE posts.id = likes.post_id);
+
+UPDATE likes SET created_at = now() WHERE created_at IS NULL;
+
+ALTER TABLE likes
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN post_id SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT now();
+
+ALTER TABLE likes ADD CONSTRAINT likes_unique_pair UNIQUE (user_id, post_id);
+ALTER TABLE likes ADD CONSTRAINT likes_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE;
+ALTER TABLE likes ADD CONSTRAINT likes_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;
+
+-- Denormalized counter shown on posts, maintained by the like repository
+ALTER TABLE posts ADD COLUMN like_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE posts SET like_count = (SELECT COUNT(*) FROM likes WHERE likes.post_id = posts.id);
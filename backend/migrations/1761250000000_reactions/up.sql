@@ -0,0 +1,19 @@
+-- A reaction is on a post or on a comment, never both. The allowed emoji are
+-- checked by the application so the palette can change without a migration.
+CREATE TABLE reactions (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE,
+    comment_id INTEGER REFERENCES comments(id) ON DELETE CASCADE,
+    emoji VARCHAR(16) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT reactions_one_target CHECK ((post_id IS NULL) <> (comment_id IS NULL))
+);
+
+-- One reaction per user and item
+CREATE UNIQUE INDEX idx_reactions_user_post ON reactions(user_id, post_id) WHERE post_id IS NOT NULL;
+CREATE UNIQUE INDEX idx_reactions_user_comment ON reactions(user_id, comment_id) WHERE comment_id IS NOT NULL;
+
+-- Counting per emoji and listing who reacted, newest first
+CREATE INDEX idx_reactions_post_emoji ON reactions(post_id, emoji, created_at DESC) WHERE post_id IS NOT NULL;
+CREATE INDEX idx_reactions_comment_emoji ON reactions(comment_id, emoji, created_at DESC) WHERE comment_id IS NOT NULL;
@@ -0,0 +1,20 @@
+DROP INDEX idx_comments_parent_created;
+DROP INDEX idx_comments_post_popular;
+DROP INDEX idx_comments_post_created;
+
+ALTER TABLE posts DROP COLUMN comment_count;
+ALTER TABLE comments DROP COLUMN reaction_count;
+ALTER TABLE comments DROP COLUMN reply_count;
+ALTER TABLE comments DROP COLUMN updated_at;
+ALTER TABLE comments DROP COLUMN parent_id;
+
+ALTER TABLE comments
+    DROP CONSTRAINT comments_post_id_fkey,
+    DROP CONSTRAINT comments_user_id_fkey,
+    ALTER COLUMN user_id DROP NOT NULL,
+    ALTER COLUMN post_id DROP NOT NULL,
+    ALTER COLUMN text DROP NOT NULL,
+    ALTER COLUMN created_at DROP DEFAULT,
+    ALTER COLUMN created_at DROP NOT NULL,
+    ALTER COLUMN deleted DROP DEFAULT,
+    ALTER COLUMN deleted DROP NOT NULL;
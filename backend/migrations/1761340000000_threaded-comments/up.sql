@@ -0,0 +1,44 @@
+-- Drop rows that would violate the new constraints before adding them
+DELETE FROM comments
+WHERE user_id IS NULL OR post_id IS NULL
+   OR NOT EXISTS (SELECT 1 FROM users WHERE users.id = comments.user_id)
+   OR NOT EXISTS (SELECT 1 FROM posts WHERE posts.id = comments.post_id);
+
+UPDATE comments SET text = '' WHERE text IS NULL;
+UPDATE comments SET created_at = now() WHERE created_at IS NULL;
+UPDATE comments SET deleted = FALSE WHERE deleted IS NULL;
+
+ALTER TABLE comments
+    ALTER COLUMN user_id SET NOT NULL,
+    ALTER COLUMN post_id SET NOT NULL,
+    ALTER COLUMN text SET NOT NULL,
+    ALTER COLUMN created_at SET NOT NULL,
+    ALTER COLUMN created_at SET DEFAULT now(),
+    ALTER COLUMN deleted SET NOT NULL,
+    ALTER COLUMN deleted SET DEFAULT FALSE,
+    ADD CONSTRAINT comments_user_id_fkey FOREIGN KEY (user_id) REFERENCES users(id) ON DELETE CASCADE,
+    ADD CONSTRAINT comments_post_id_fkey FOREIGN KEY (post_id) REFERENCES posts(id) ON DELETE CASCADE;
+
+-- Replies point at a top level comment of the same post, one level deep
+ALTER TABLE comments ADD COLUMN parent_id INTEGER REFERENCES comments(id) ON DELETE CASCADE;
+ALTER TABLE comments ADD COLUMN updated_at TIMESTAMP;
+UPDATE comments SET updated_at = created_at;
+ALTER TABLE comments
+    ALTER COLUMN updated_at SET NOT NULL,
+    ALTER COLUMN updated_at SET DEFAULT now();
+
+-- Denormalized counters, maintained by the comment and reaction repositories.
+-- Only live comments are counted.
+ALTER TABLE comments ADD COLUMN reply_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE comments ADD COLUMN reaction_count INTEGER NOT NULL DEFAULT 0;
+ALTER TABLE posts ADD COLUMN comment_count INTEGER NOT NULL DEFAULT 0;
+
+UPDATE comments SET reaction_count = (SELECT COUNT(*) FROM reactions WHERE reactions.comment_id = comments.id);
+UPDATE posts SET comment_count = (
+    SELECT COUNT(*) FROM comments WHERE comments.post_id = posts.id AND comments.deleted = FALSE
+);
+
+-- Threads are listed by time or by popularity, and replies by time
+CREATE INDEX idx_comments_post_created ON comments(post_id, created_at DESC, id DESC) WHERE parent_id IS NULL;
+CREATE INDEX idx_comments_post_popular ON comments(post_id, (reaction_count + reply_count) DESC, id DESC) WHERE parent_id IS NULL;
+CREATE INDEX idx_comments_parent_created ON comments(parent_id, created_at, id) WHERE parent_id IS NOT NULL;
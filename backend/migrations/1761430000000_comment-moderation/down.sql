@@ -0,0 +1,6 @@
+ALTER TABLE users DROP CONSTRAINT users_role_check;
+ALTER TABLE users DROP COLUMN role;
+
+ALTER TABLE comments DROP COLUMN deletion_reason;
+ALTER TABLE comments DROP COLUMN deleted_by;
+ALTER TABLE comments ADD COLUMN deleted_by TIMESTAMP;
@@ -0,0 +1,13 @@
+-- deleted_by was created as a TIMESTAMP by mistake. Keep any time it holds
+-- as the deletion time, then turn it into a reference to who deleted the
+-- comment. Nothing recorded the deleter so far, and authors are the ones who
+-- delete comments, so existing deletions are attributed to them.
+UPDATE comments SET deleted_at = deleted_by WHERE deleted_at IS NULL AND deleted_by IS NOT NULL;
+ALTER TABLE comments DROP COLUMN deleted_by;
+ALTER TABLE comments ADD COLUMN deleted_by INTEGER REFERENCES users(id) ON DELETE SET NULL;
+ALTER TABLE comments ADD COLUMN deletion_reason VARCHAR(500);
+UPDATE comments SET deleted_by = user_id WHERE deleted = TRUE;
+
+-- Moderators can remove any comment
+ALTER TABLE users ADD COLUMN role VARCHAR(20) NOT NULL DEFAULT 'user';
+ALTER TABLE users ADD CONSTRAINT users_role_check CHECK (role IN ('user', 'moderator', 'admin'));
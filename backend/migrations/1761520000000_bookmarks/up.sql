@@ -0,0 +1,25 @@
+-- Collections are named folders of bookmarks, private to their owner
+CREATE TABLE collections (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    name VARCHAR(50) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    updated_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE UNIQUE INDEX idx_collections_user_name ON collections(user_id, lower(name));
+
+-- A saved post sits in at most one collection. Deleting a collection keeps
+-- its bookmarks in the plain saved list.
+CREATE TABLE bookmarks (
+    id SERIAL PRIMARY KEY,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    collection_id INTEGER REFERENCES collections(id) ON DELETE SET NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT bookmarks_unique_pair UNIQUE (user_id, post_id)
+);
+
+CREATE INDEX idx_bookmarks_user_created ON bookmarks(user_id, created_at DESC, id DESC);
+CREATE INDEX idx_bookmarks_collection_created ON bookmarks(collection_id, created_at DESC, id DESC)
+    WHERE collection_id IS NOT NULL;
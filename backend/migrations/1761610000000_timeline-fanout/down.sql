@@ -0,0 +1,2 @@
+DROP TABLE fanout_jobs;
+DROP TABLE timeline_entries;
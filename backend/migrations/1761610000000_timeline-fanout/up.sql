@@ -0,0 +1,32 @@
+-- Materialized home timelines, filled by the fan-out worker when
+-- FEED_FANOUT is enabled. created_at is the time of the post, so entries
+-- sort like the posts they point at.
+CREATE TABLE timeline_entries (
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    post_id INTEGER NOT NULL REFERENCES posts(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    created_at TIMESTAMP NOT NULL,
+    PRIMARY KEY (user_id, post_id)
+);
+
+CREATE INDEX idx_timeline_entries_user_created ON timeline_entries(user_id, created_at DESC, post_id DESC);
+CREATE INDEX idx_timeline_entries_user_author ON timeline_entries(user_id, author_id);
+
+-- Work queue of the fan-out worker. Jobs are claimed with FOR UPDATE SKIP
+-- LOCKED so several instances can drain it together, and failed jobs are
+-- retried later until they run out of attempts.
+CREATE TABLE fanout_jobs (
+    id BIGSERIAL PRIMARY KEY,
+    kind VARCHAR(20) NOT NULL,
+    post_id INTEGER REFERENCES posts(id) ON DELETE CASCADE,
+    follower_id INTEGER REFERENCES users(id) ON DELETE CASCADE,
+    author_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    attempts INTEGER NOT NULL DEFAULT 0,
+    run_after TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT fanout_jobs_kind_check CHECK (
+        (kind = 'post' AND post_id IS NOT NULL) OR (kind = 'follow' AND follower_id IS NOT NULL)
+    )
+);
+
+CREATE INDEX idx_fanout_jobs_run_after ON fanout_jobs(run_after, id);
@@ -0,0 +1,4 @@
+DROP INDEX idx_bookmarks_post_id;
+DROP INDEX idx_reactions_user_created;
+DROP INDEX idx_comments_user_created;
+DROP INDEX idx_likes_user_created;
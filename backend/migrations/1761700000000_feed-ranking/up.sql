@@ -0,0 +1,6 @@
+-- The ranked feed reads the recent interactions of the viewer and the number
+-- of saves of each candidate post
+CREATE INDEX idx_likes_user_created ON likes(user_id, created_at DESC);
+CREATE INDEX idx_comments_user_created ON comments(user_id, created_at DESC) WHERE deleted = FALSE;
+CREATE INDEX idx_reactions_user_created ON reactions(user_id, created_at DESC) WHERE post_id IS NOT NULL;
+CREATE INDEX idx_bookmarks_post_id ON bookmarks(post_id);
@@ -0,0 +1,8 @@
+DROP INDEX idx_posts_created;
+DROP INDEX idx_bookmarks_created;
+DROP INDEX idx_reactions_created;
+DROP INDEX idx_comments_created;
+DROP INDEX idx_likes_created;
+DROP TABLE trending_hashtags;
+DROP TABLE trending_posts;
+ALTER TABLE posts DROP COLUMN nsfw;
@@ -0,0 +1,25 @@
+ALTER TABLE posts ADD COLUMN nsfw BOOLEAN NOT NULL DEFAULT FALSE;
+
+CREATE TABLE trending_posts (
+    post_id INTEGER PRIMARY KEY REFERENCES posts(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    computed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_trending_posts_score ON trending_posts (score DESC, post_id DESC);
+
+CREATE TABLE trending_hashtags (
+    tag_id INTEGER PRIMARY KEY REFERENCES hashtags(id) ON DELETE CASCADE,
+    score DOUBLE PRECISION NOT NULL,
+    recent_post_count INTEGER NOT NULL,
+    computed_at TIMESTAMP NOT NULL
+);
+
+CREATE INDEX idx_trending_hashtags_score ON trending_hashtags (score DESC, tag_id);
+
+-- The trending job scans recent engagement across all users
+CREATE INDEX idx_likes_created ON likes(created_at);
+CREATE INDEX idx_comments_created ON comments(created_at) WHERE deleted = FALSE;
+CREATE INDEX idx_reactions_created ON reactions(created_at) WHERE post_id IS NOT NULL;
+CREATE INDEX idx_bookmarks_created ON bookmarks(created_at);
+CREATE INDEX idx_posts_created ON posts(created_at) WHERE deleted = FALSE;
@@ -0,0 +1,16 @@
+-- Text is indexed with both the Spanish and the English configuration so a
+-- search matches the stems of either language. Generated columns keep the
+-- vectors in sync with every insert and edit.
+ALTER TABLE posts ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('spanish', COALESCE(description, '')) || to_tsvector('english', COALESCE(description, ''))
+) STORED;
+
+CREATE INDEX idx_posts_search_vector ON posts USING GIN (search_vector) WHERE deleted = FALSE;
+
+ALTER TABLE comments ADD COLUMN search_vector TSVECTOR GENERATED ALWAYS AS (
+    to_tsvector('spanish', COALESCE(text, '')) || to_tsvector('english', COALESCE(text, ''))
+) STORED;
+
+CREATE INDEX idx_comments_search_vector ON comments USING GIN (search_vector) WHERE deleted = FALSE;
+
+CREATE INDEX idx_mentions_comment_id ON mentions(comment_id) WHERE comment_id IS NOT NULL;
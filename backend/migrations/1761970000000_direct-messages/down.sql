@@ -0,0 +1,6 @@
+ALTER TABLE conversations DROP CONSTRAINT conversations_last_message_id_fkey;
+DROP TABLE messages;
+DROP TABLE conversation_members;
+DROP TABLE conversations;
+ALTER TABLE users DROP CONSTRAINT users_dm_policy_check;
+ALTER TABLE users DROP COLUMN dm_policy;
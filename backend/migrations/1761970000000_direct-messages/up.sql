@@ -0,0 +1,41 @@
+ALTER TABLE users ADD COLUMN dm_policy VARCHAR(20) NOT NULL DEFAULT 'everyone';
+ALTER TABLE users ADD CONSTRAINT users_dm_policy_check CHECK (dm_policy IN ('everyone', 'followers', 'nobody'));
+
+-- A direct conversation stores its two members lowest id first so each pair
+-- of users has a single conversation
+CREATE TABLE conversations (
+    id SERIAL PRIMARY KEY,
+    user_low_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    user_high_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_message_id INTEGER,
+    last_activity_at TIMESTAMP NOT NULL DEFAULT now(),
+    created_at TIMESTAMP NOT NULL DEFAULT now(),
+    CONSTRAINT conversations_pair_order CHECK (user_low_id < user_high_id)
+);
+
+CREATE UNIQUE INDEX idx_conversations_pair ON conversations(user_low_id, user_high_id);
+CREATE INDEX idx_conversations_last_activity ON conversations(last_activity_at DESC, id DESC);
+
+CREATE TABLE conversation_members (
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    user_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    last_read_message_id INTEGER NOT NULL DEFAULT 0,
+    joined_at TIMESTAMP NOT NULL DEFAULT now(),
+    PRIMARY KEY (conversation_id, user_id)
+);
+
+CREATE INDEX idx_conversation_members_user ON conversation_members(user_id);
+
+CREATE TABLE messages (
+    id SERIAL PRIMARY KEY,
+    conversation_id INTEGER NOT NULL REFERENCES conversations(id) ON DELETE CASCADE,
+    sender_id INTEGER NOT NULL REFERENCES users(id) ON DELETE CASCADE,
+    text VARCHAR(2000) NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_messages_conversation ON messages(conversation_id, id DESC);
+CREATE INDEX idx_messages_conversation_sender ON messages(conversation_id, sender_id);
+
+ALTER TABLE conversations
+    ADD CONSTRAINT conversations_last_message_id_fkey FOREIGN KEY (last_message_id) REFERENCES messages(id) ON DELETE SET NULL;
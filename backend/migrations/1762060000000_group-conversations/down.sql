@@ -0,0 +1,13 @@
+DELETE FROM conversations WHERE kind = 'group';
+ALTER TABLE messages DROP CONSTRAINT messages_kind_check;
+ALTER TABLE messages DROP COLUMN target_user_id, DROP COLUMN event, DROP COLUMN kind;
+ALTER TABLE conversation_members DROP CONSTRAINT conversation_members_role_check;
+ALTER TABLE conversation_members DROP COLUMN role;
+ALTER TABLE conversations DROP CONSTRAINT conversations_kind_check;
+ALTER TABLE conversations
+    DROP COLUMN owner_id,
+    DROP COLUMN title,
+    DROP COLUMN kind,
+    ALTER COLUMN user_low_id SET NOT NULL,
+    ALTER COLUMN user_high_id SET NOT NULL,
+    ADD CONSTRAINT conversations_pair_order CHECK (user_low_id < user_high_id);
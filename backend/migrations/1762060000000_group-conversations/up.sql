@@ -0,0 +1,30 @@
+ALTER TABLE conversations
+    ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'direct',
+    ADD COLUMN title VARCHAR(100),
+    ADD COLUMN owner_id INTEGER REFERENCES users(id) ON DELETE SET NULL,
+    ALTER COLUMN user_low_id DROP NOT NULL,
+    ALTER COLUMN user_high_id DROP NOT NULL,
+    DROP CONSTRAINT conversations_pair_order;
+
+-- Direct conversations are identified by their pair, groups by id and carry
+-- a title instead
+ALTER TABLE conversations ADD CONSTRAINT conversations_kind_check CHECK (
+    (kind = 'direct' AND user_low_id < user_high_id AND title IS NULL)
+    OR (kind = 'group' AND user_low_id IS NULL AND user_high_id IS NULL AND title IS NOT NULL)
+);
+
+ALTER TABLE conversation_members ADD COLUMN role VARCHAR(10) NOT NULL DEFAULT 'member';
+ALTER TABLE conversation_members ADD CONSTRAINT conversation_members_role_check CHECK (role IN ('owner', 'admin', 'member'));
+
+-- System messages record membership changes. sender_id is the member who
+-- made the change and target_user_id the member it was made to.
+ALTER TABLE messages
+    ADD COLUMN kind VARCHAR(10) NOT NULL DEFAULT 'text',
+    ADD COLUMN event VARCHAR(20),
+    ADD COLUMN target_user_id INTEGER REFERENCES users(id) ON DELETE SET NULL;
+
+ALTER TABLE messages ADD CONSTRAINT messages_kind_check CHECK (
+    (kind = 'text' AND event IS NULL)
+    OR (kind = 'system' AND event IN ('created', 'member_added', 'member_removed', 'member_left', 'renamed',
+                                      'admin_added', 'admin_removed', 'owner_changed'))
+);
@@ -0,0 +1,8 @@
+DROP INDEX idx_fanout_jobs_timeline;
+DELETE FROM fanout_jobs WHERE kind = 'timeline';
+ALTER TABLE fanout_jobs DROP CONSTRAINT fanout_jobs_kind_check;
+ALTER TABLE fanout_jobs ADD CONSTRAINT fanout_jobs_kind_check CHECK (
+    (kind = 'post' AND post_id IS NOT NULL) OR (kind = 'follow' AND follower_id IS NOT NULL)
+);
+ALTER TABLE fanout_jobs ALTER COLUMN author_id SET NOT NULL;
+DROP TABLE materialized_timelines;
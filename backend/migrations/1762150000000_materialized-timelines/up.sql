@@ -0,0 +1,21 @@
+-- Users whose timeline was backfilled from every account they follow. Until
+-- a user is listed here their feed is gathered at read time, since
+-- timeline_entries only holds what was fanned out after the mode was turned
+-- on. Delete the rows to rebuild the timelines, e.g. after FEED_FANOUT was
+-- off for a while.
+CREATE TABLE materialized_timelines (
+    user_id INTEGER PRIMARY KEY REFERENCES users(id) ON DELETE CASCADE,
+    materialized_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+-- Timeline jobs backfill the whole timeline of follower_id and have no
+-- author. At most one is queued per user.
+ALTER TABLE fanout_jobs ALTER COLUMN author_id DROP NOT NULL;
+ALTER TABLE fanout_jobs DROP CONSTRAINT fanout_jobs_kind_check;
+ALTER TABLE fanout_jobs ADD CONSTRAINT fanout_jobs_kind_check CHECK (
+    (kind = 'post' AND post_id IS NOT NULL AND author_id IS NOT NULL)
+    OR (kind = 'follow' AND follower_id IS NOT NULL AND author_id IS NOT NULL)
+    OR (kind = 'timeline' AND follower_id IS NOT NULL)
+);
+
+CREATE UNIQUE INDEX idx_fanout_jobs_timeline ON fanout_jobs(follower_id) WHERE kind = 'timeline';
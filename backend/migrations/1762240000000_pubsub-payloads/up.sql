@@ -0,0 +1,11 @@
+-- Messages of the Postgres pub/sub backend too large for a NOTIFY payload.
+-- The notification carries the id and every instance reads the row, so rows
+-- are only pruned once they are old enough for every listener to have read
+-- them.
+CREATE TABLE pubsub_payloads (
+    id BIGSERIAL PRIMARY KEY,
+    payload TEXT NOT NULL,
+    created_at TIMESTAMP NOT NULL DEFAULT now()
+);
+
+CREATE INDEX idx_pubsub_payloads_created ON pubsub_payloads(created_at);
@@ -0,0 +1,88 @@
+// Package hashtag finds #tags in user text
+package hashtag
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+// MaxLength is the longest tag kept, in characters. Longer runs are ignored
+// rather than truncated so a tag never changes meaning.
+const MaxLength = 100
+
+// Extract returns the distinct tags of text in order of appearance, without
+// the leading '#' and normalized with Normalize. A tag starts with '#' (or
+// the fullwidth '＃') that does not follow a letter, digit or '_', continues
+// with letters, combining marks, digits and '_', and needs at least one
+// letter, so "#1" or "issue#3" are not tags.
+func Extract(text string) []string {
+	text = norm.NFC.String(text)
+
+	var tags []string
+	seen := make(map[string]bool)
+	prev := rune(-1)
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isHash(r) || isTagRune(prev) {
+			prev = r
+			i += size
+			continue
+		}
+
+		end := i + size
+		for end < len(text) {
+			next, nextSize := utf8.DecodeRuneInString(text[end:])
+			if !isTagRune(next) {
+				break
+			}
+			end += nextSize
+		}
+
+		if tag := text[i+size : end]; Valid(tag) {
+			tag = Normalize(tag)
+			if !seen[tag] {
+				seen[tag] = true
+				tags = append(tags, tag)
+			}
+		}
+		prev, _ = utf8.DecodeLastRuneInString(text[:end])
+		i = end
+	}
+	return tags
+}
+
+// Normalize turns a tag as typed by a user into its stored form: without a
+// leading '#', NFC composed and lowercase
+func Normalize(tag string) string {
+	tag = strings.TrimLeftFunc(tag, isHash)
+	return norm.NFC.String(strings.ToLower(norm.NFC.String(tag)))
+}
+
+// Valid reports whether tag, without its '#', is a well formed tag
+func Valid(tag string) bool {
+	length := utf8.RuneCountInString(tag)
+	if length == 0 || length > MaxLength {
+		return false
+	}
+	hasLetter := false
+	for _, r := range tag {
+		if !isTagRune(r) {
+			return false
+		}
+		if unicode.IsLetter(r) {
+			hasLetter = true
+		}
+	}
+	return hasLetter
+}
+
+func isHash(r rune) bool {
+	return r == '#' || r == '＃'
+}
+
+func isTagRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r)
+}
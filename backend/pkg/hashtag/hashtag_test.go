@@ -0,0 +1,62 @@
+package hashtag
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []string
+	}{
+		{name: "single tag", text: "learning #Go today", want: []string{"go"}},
+		{name: "distinct in order", text: "#b #a #B", want: []string{"b", "a"}},
+		{name: "trailing punctuation", text: "done #today. #now!", want: []string{"today", "now"}},
+		{name: "underscore and digits", text: "#snake_case #web3", want: []string{"snake_case", "web3"}},
+		{name: "digits only", text: "#1 #2024", want: nil},
+		{name: "after a word", text: "issue#3 foo#bar", want: nil},
+		{name: "after an underscore", text: "_#tag", want: nil},
+		{name: "after emoji", text: "🎉#party", want: []string{"party"}},
+		{name: "followed by emoji", text: "#party🎉", want: []string{"party"}},
+		{name: "non ASCII letters", text: "#日本語 #ÉTÉ", want: []string{"日本語", "été"}},
+		{name: "composed and decomposed are one tag", text: "#café #café", want: []string{"café"}},
+		{name: "combining mark inside", text: "#x́y", want: []string{"x́y"}},
+		{name: "after a combining mark", text: "x́#tag", want: nil},
+		{name: "fullwidth hash", text: "＃Tag", want: []string{"tag"}},
+		{name: "double hash", text: "##tag", want: []string{"tag"}},
+		{name: "hash alone", text: "# tag #", want: nil},
+		{name: "max length", text: "#" + strings.Repeat("a", MaxLength), want: []string{strings.Repeat("a", MaxLength)}},
+		{name: "over max length", text: "#" + strings.Repeat("a", MaxLength+1), want: nil},
+		{name: "max length in characters", text: "#" + strings.Repeat("é", MaxLength), want: []string{strings.Repeat("é", MaxLength)}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extract(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Extract(%q) = %q, want %q", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNormalize(t *testing.T) {
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{tag: "Go", want: "go"},
+		{tag: "#Go", want: "go"},
+		{tag: "＃Go", want: "go"},
+		{tag: "Café", want: "café"},
+		{tag: "ÉTÉ", want: "été"},
+	}
+
+	for _, tt := range tests {
+		if got := Normalize(tt.tag); got != tt.want {
+			t.Errorf("Normalize(%q) = %q, want %q", tt.tag, got, tt.want)
+		}
+	}
+}
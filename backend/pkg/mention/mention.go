@@ -0,0 +1,118 @@
+// Package mention finds @username mentions in user text
+package mention
+
+import (
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
+
+// Usernames are 3 to 30 lowercase ASCII letters, digits, '_' and '.'
+const (
+	MinLength = 3
+	MaxLength = 30
+)
+
+// Mention is one occurrence of @username in a text. Offset and Length count
+// UTF-16 code units, like JavaScript strings, and cover the '@' too.
+type Mention struct {
+	Username string
+	Offset   int
+	Length   int
+}
+
+// Extract returns every mention of text in order of appearance, with the
+// username normalized. A mention starts with '@' (or the fullwidth '＠') that
+// does not follow a letter, digit or username character, so e-mail
+// addresses are skipped. Trailing dots are punctuation, not part of the
+// name, and a run followed by a non ASCII letter such as "@josé" is not a
+// mention since it would link the wrong user.
+func Extract(text string) []Mention {
+	var mentions []Mention
+	prev := rune(-1)
+	offset := 0
+	for i := 0; i < len(text); {
+		r, size := utf8.DecodeRuneInString(text[i:])
+		if !isAt(r) || isWordRune(prev) {
+			prev = r
+			offset += utf16Len(r)
+			i += size
+			continue
+		}
+
+		end := i + size
+		for end < len(text) && isUsernameByte(text[end]) {
+			end++
+		}
+		name := strings.TrimRight(text[i+size:end], ".")
+		next, _ := utf8.DecodeRuneInString(text[end:])
+		if Valid(Normalize(name)) && (strings.HasSuffix(text[i:end], ".") || !isWordRune(next)) {
+			mentions = append(mentions, Mention{
+				Username: Normalize(name),
+				Offset:   offset,
+				Length:   utf16Len(r) + len(name),
+			})
+		}
+
+		// Usernames are ASCII, so bytes and UTF-16 units match after the '@'
+		offset += utf16Len(r) + end - i - size
+		prev, _ = utf8.DecodeLastRuneInString(text[:end])
+		i = end
+	}
+	return mentions
+}
+
+// Usernames returns the distinct usernames of mentions in order of
+// appearance
+func Usernames(mentions []Mention) []string {
+	var usernames []string
+	seen := make(map[string]bool)
+	for _, m := range mentions {
+		if !seen[m.Username] {
+			seen[m.Username] = true
+			usernames = append(usernames, m.Username)
+		}
+	}
+	return usernames
+}
+
+// Normalize turns a username as typed by a user into its stored form:
+// without a leading '@' and lowercase
+func Normalize(username string) string {
+	return strings.ToLower(strings.TrimLeftFunc(username, isAt))
+}
+
+// Valid reports whether username is a well formed, normalized username
+func Valid(username string) bool {
+	if len(username) < MinLength || len(username) > MaxLength {
+		return false
+	}
+	for i := 0; i < len(username); i++ {
+		c := username[i]
+		if !isUsernameByte(c) || (c >= 'A' && c <= 'Z') {
+			return false
+		}
+	}
+	return true
+}
+
+func isAt(r rune) bool {
+	return r == '@' || r == '＠'
+}
+
+func isUsernameByte(c byte) bool {
+	return c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '_' || c == '.'
+}
+
+func isWordRune(r rune) bool {
+	return r == '_' || r == '.' || unicode.IsLetter(r) || unicode.IsDigit(r) || unicode.Is(unicode.M, r)
+}
+
+func utf16Len(r rune) int {
+	if n := utf16.RuneLen(r); n > 0 {
+		return n
+	}
+	// Invalid UTF-8 decodes to U+FFFD, a single unit
+	return 1
+}
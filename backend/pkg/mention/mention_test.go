@@ -0,0 +1,76 @@
+package mention
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestExtract(t *testing.T) {
+	tests := []struct {
+		name string
+		text string
+		want []Mention
+	}{
+		{name: "single mention", text: "hi @alice", want: []Mention{{"alice", 3, 6}}},
+		{name: "normalized", text: "@Alice", want: []Mention{{"alice", 0, 6}}},
+		{name: "dots and underscores", text: "@a.b_c", want: []Mention{{"a.b_c", 0, 6}}},
+		{name: "trailing dot", text: "thanks @bob.", want: []Mention{{"bob", 7, 4}}},
+		{name: "trailing dots", text: "@bob... ok", want: []Mention{{"bob", 0, 4}}},
+		{name: "trailing dot before a letter", text: "@bob.é", want: []Mention{{"bob", 0, 4}}},
+		{name: "email", text: "write to a@b.com or me@example.com", want: nil},
+		{name: "after a dot", text: "x.@alice", want: nil},
+		{name: "after emoji", text: "😀@alice", want: []Mention{{"alice", 2, 6}}},
+		{name: "offset after emoji and accents", text: "café 👋 @bob", want: []Mention{{"bob", 8, 4}}},
+		{name: "after a combining mark", text: "é@alice", want: nil},
+		{name: "followed by a non ASCII letter", text: "@josé", want: nil},
+		{name: "followed by a combining mark", text: "@josé", want: nil},
+		{name: "fullwidth at", text: "＠Alice hi", want: []Mention{{"alice", 0, 6}}},
+		{name: "fullwidth at after emoji", text: "👋＠bob", want: []Mention{{"bob", 2, 4}}},
+		{name: "too short", text: "@ab @ab.", want: nil},
+		{name: "min length", text: "@abc", want: []Mention{{"abc", 0, 4}}},
+		{name: "max length", text: "@" + strings.Repeat("a", MaxLength),
+			want: []Mention{{strings.Repeat("a", MaxLength), 0, MaxLength + 1}}},
+		{name: "over max length", text: "@" + strings.Repeat("a", MaxLength+1), want: nil},
+		{name: "several", text: "@ann, @bob and @ann", want: []Mention{{"ann", 0, 4}, {"bob", 6, 4}, {"ann", 15, 4}}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Extract(tt.text); !slices.Equal(got, tt.want) {
+				t.Errorf("Extract(%q) = %+v, want %+v", tt.text, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestUsernames(t *testing.T) {
+	got := Usernames(Extract("@ann @Bob @ANN @carl"))
+	want := []string{"ann", "bob", "carl"}
+	if !slices.Equal(got, want) {
+		t.Errorf("Usernames() = %q, want %q", got, want)
+	}
+}
+
+func TestValid(t *testing.T) {
+	tests := []struct {
+		username string
+		want     bool
+	}{
+		{username: "abc", want: true},
+		{username: "a.b_c9", want: true},
+		{username: "ab", want: false},
+		{username: strings.Repeat("a", MaxLength), want: true},
+		{username: strings.Repeat("a", MaxLength+1), want: false},
+		{username: "Alice", want: false},
+		{username: "josé", want: false},
+		{username: "a-b", want: false},
+		{username: "@abc", want: false},
+	}
+
+	for _, tt := range tests {
+		if got := Valid(tt.username); got != tt.want {
+			t.Errorf("Valid(%q) = %v, want %v", tt.username, got, tt.want)
+		}
+	}
+}
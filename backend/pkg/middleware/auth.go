@@ -36,15 +36,35 @@ func (am *AuthMiddleware) Auth(next http.HandlerFunc) http.HandlerFunc {
 			return
 		}
 
-		claims, err := am.jwtUtil.ValidateToken(tokenString)
-		if err != nil {
-			common.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired token", nil)
+		am.serve(w, r, tokenString, next)
+	}
+}
+
+// AuthStream is Auth for endpoints opened by browser APIs that cannot set
+// headers, like WebSocket and EventSource. The token can also be passed in
+// the access_token query parameter.
+func (am *AuthMiddleware) AuthStream(next http.HandlerFunc) http.HandlerFunc {
+	auth := am.Auth(next)
+	return func(w http.ResponseWriter, r *http.Request) {
+		tokenString := r.URL.Query().Get("access_token")
+		if tokenString == "" {
+			auth(w, r)
 			return
 		}
+		am.serve(w, r, tokenString, next)
+	}
+}
 
-		ctx := context.WithValue(r.Context(), UserContextKey, claims)
-		next.ServeHTTP(w, r.WithContext(ctx))
+// serve validates the token and calls next with its claims in the context
+func (am *AuthMiddleware) serve(w http.ResponseWriter, r *http.Request, tokenString string, next http.HandlerFunc) {
+	claims, err := am.jwtUtil.ValidateToken(tokenString)
+	if err != nil {
+		common.ErrorResponse(w, http.StatusUnauthorized, "Invalid or expired token", nil)
+		return
 	}
+
+	ctx := context.WithValue(r.Context(), UserContextKey, claims)
+	next.ServeHTTP(w, r.WithContext(ctx))
 }
 
 func GetUserFromContext(ctx context.Context) *utils.Claims {
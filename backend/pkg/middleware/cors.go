@@ -7,9 +7,11 @@ import (
 // NewCORS returns a configured CORS middleware
 func NewCORS() *cors.Cors {
 	return cors.New(cors.Options{
-		AllowedOrigins:   []string{"http://localhost:5173"}, // your frontend dev URL
-		AllowedMethods:   []string{"GET", "POST", "PUT", "DELETE", "OPTIONS"},
-		AllowedHeaders:   []string{"*"},
+		AllowedOrigins: []string{"http://localhost:5173"}, // your frontend dev URL
+		AllowedMethods: []string{"GET", "HEAD", "POST", "PUT", "PATCH", "DELETE", "OPTIONS"},
+		AllowedHeaders: []string{"*"},
+		// Headers of the tus resumable upload protocol
+		ExposedHeaders:   []string{"Location", "Upload-Offset", "Upload-Length", "Upload-Expires", "Tus-Resumable", "Tus-Version", "Tus-Extension", "Tus-Max-Size"},
 		AllowCredentials: true,
 	})
 }
@@ -0,0 +1,71 @@
+package pubsub
+
+import (
+	"context"
+	"sync"
+)
+
+// Memory delivers messages to subscribers in the same process. It is enough
+// for a single instance and is also how the Postgres backend dispatches the
+// notifications it receives.
+type Memory struct {
+	mu       sync.RWMutex
+	nextID   int
+	handlers map[string]map[int]Handler
+	closed   bool
+}
+
+func NewMemory() *Memory {
+	return &Memory{handlers: make(map[string]map[int]Handler)}
+}
+
+// Publish calls the handlers of topic on the calling goroutine
+func (m *Memory) Publish(_ context.Context, topic string, payload []byte) error {
+	m.mu.RLock()
+	handlers := make([]Handler, 0, len(m.handlers[topic]))
+	for _, handler := range m.handlers[topic] {
+		handlers = append(handlers, handler)
+	}
+	m.mu.RUnlock()
+
+	for _, handler := range handlers {
+		handler(payload)
+	}
+	return nil
+}
+
+// Subscribe registers handler for topic. Subscribing after Close is a no-op.
+func (m *Memory) Subscribe(topic string, handler Handler) func() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.closed {
+		return func() {}
+	}
+	m.nextID++
+	id := m.nextID
+	if m.handlers[topic] == nil {
+		m.handlers[topic] = make(map[int]Handler)
+	}
+	m.handlers[topic][id] = handler
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			m.mu.Lock()
+			defer m.mu.Unlock()
+			delete(m.handlers[topic], id)
+			if len(m.handlers[topic]) == 0 {
+				delete(m.handlers, topic)
+			}
+		})
+	}
+}
+
+// Close drops every subscription, later messages are not delivered
+func (m *Memory) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.closed = true
+	clear(m.handlers)
+	return nil
+}
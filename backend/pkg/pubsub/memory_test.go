@@ -0,0 +1,88 @@
+package pubsub
+
+import (
+	"context"
+	"slices"
+	"testing"
+)
+
+// recorder collects the payloads a handler receives
+type recorder struct {
+	got []string
+}
+
+func (r *recorder) handle(payload []byte) {
+	r.got = append(r.got, string(payload))
+}
+
+func TestMemoryPublish(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	var first, second, other recorder
+	m.Subscribe("users.1", first.handle)
+	m.Subscribe("users.1", second.handle)
+	m.Subscribe("users.2", other.handle)
+
+	for _, payload := range []string{"a", "b"} {
+		if err := m.Publish(ctx, "users.1", []byte(payload)); err != nil {
+			t.Fatalf("Publish() error = %v", err)
+		}
+	}
+	if err := m.Publish(ctx, "users.3", []byte("nobody")); err != nil {
+		t.Fatalf("Publish() without subscribers error = %v", err)
+	}
+
+	want := []string{"a", "b"}
+	if !slices.Equal(first.got, want) || !slices.Equal(second.got, want) {
+		t.Errorf("subscribers got %q and %q, want %q", first.got, second.got, want)
+	}
+	if len(other.got) != 0 {
+		t.Errorf("subscriber of another topic got %q", other.got)
+	}
+}
+
+func TestMemoryUnsubscribe(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	var kept, dropped recorder
+	m.Subscribe("users.1", kept.handle)
+	unsubscribe := m.Subscribe("users.1", dropped.handle)
+
+	m.Publish(ctx, "users.1", []byte("a")) //nolint:errcheck // in memory publish does not fail
+	unsubscribe()
+	unsubscribe()
+	m.Publish(ctx, "users.1", []byte("b")) //nolint:errcheck // in memory publish does not fail
+
+	if want := []string{"a", "b"}; !slices.Equal(kept.got, want) {
+		t.Errorf("remaining subscriber got %q, want %q", kept.got, want)
+	}
+	if want := []string{"a"}; !slices.Equal(dropped.got, want) {
+		t.Errorf("unsubscribed handler got %q, want %q", dropped.got, want)
+	}
+
+	only := m.Subscribe("users.2", dropped.handle)
+	only()
+	if _, ok := m.handlers["users.2"]; ok {
+		t.Errorf("topic without subscribers was kept")
+	}
+}
+
+func TestMemoryClose(t *testing.T) {
+	m := NewMemory()
+	ctx := context.Background()
+	var before, after recorder
+	unsubscribe := m.Subscribe("users.1", before.handle)
+
+	if err := m.Close(); err != nil {
+		t.Fatalf("Close() error = %v", err)
+	}
+	m.Subscribe("users.1", after.handle)
+	if err := m.Publish(ctx, "users.1", []byte("a")); err != nil {
+		t.Fatalf("Publish() after Close() error = %v", err)
+	}
+	unsubscribe()
+
+	if len(before.got) != 0 || len(after.got) != 0 {
+		t.Errorf("handlers got %q and %q after Close()", before.got, after.got)
+	}
+}
@@ -0,0 +1,220 @@
+package pubsub
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	defaultChannel = "red404_events"
+
+	// maxNotifyPayload is the largest payload NOTIFY accepts. Larger messages
+	// are stored in pubsub_payloads, up to maxStoredPayload, and the
+	// notification refers to the row.
+	maxNotifyPayload = 7999
+	maxStoredPayload = 1 << 20
+	// storedPayloadTTL is how long a stored message is kept for the listeners
+	// to read it
+	storedPayloadTTL = time.Minute
+
+	minReconnectDelay = time.Second
+	maxReconnectDelay = 30 * time.Second
+)
+
+// Postgres delivers messages through LISTEN/NOTIFY, so a message published
+// on one instance reaches the subscribers of every instance connected to the
+// same database. All topics share one notification channel; the topic is
+// the first line of the payload. Messages too large for a notification go
+// through the pubsub_payloads table, and the notification starts with an
+// empty line followed by the topic and the id of the row.
+type Postgres struct {
+	pool    *pgxpool.Pool
+	channel string
+	local   *Memory
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+// NewPostgres starts listening on a connection taken out of pool. It runs
+// until Close is called and reconnects when the connection is lost;
+// messages published in the meantime are not delivered.
+func NewPostgres(pool *pgxpool.Pool, channel string) *Postgres {
+	if channel == "" {
+		channel = defaultChannel
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Postgres{
+		pool:    pool,
+		channel: channel,
+		local:   NewMemory(),
+		cancel:  cancel,
+		done:    make(chan struct{}),
+	}
+	go p.listen(ctx)
+	return p
+}
+
+// Publish sends a notification. Every instance gets it, this one included,
+// and hands it to its subscribers.
+func (p *Postgres) Publish(ctx context.Context, topic string, payload []byte) error {
+	if topic == "" || strings.ContainsRune(topic, '\n') {
+		return ErrInvalidTopic
+	}
+	message, ok := inlineMessage(topic, payload)
+	if !ok {
+		return p.publishStored(ctx, topic, payload)
+	}
+	if _, err := p.pool.Exec(ctx, `SELECT pg_notify($1, $2)`, p.channel, message); err != nil {
+		return fmt.Errorf("failed to publish on %s: %w", topic, err)
+	}
+	return nil
+}
+
+// publishStored saves the payload and notifies its id in one statement,
+// pruning the payloads every listener had time to read
+func (p *Postgres) publishStored(ctx context.Context, topic string, payload []byte) error {
+	if len(payload) > maxStoredPayload {
+		return ErrTooLarge
+	}
+	query := `WITH pruned AS (
+                  DELETE FROM pubsub_payloads WHERE created_at < now() - make_interval(secs => $4)
+              ), stored AS (
+                  INSERT INTO pubsub_payloads (payload) VALUES ($3) RETURNING id
+              )
+              SELECT pg_notify($1, $2 || id) FROM stored`
+	_, err := p.pool.Exec(ctx, query, p.channel, storedPrefix(topic), string(payload), storedPayloadTTL.Seconds())
+	if err != nil {
+		return fmt.Errorf("failed to publish on %s: %w", topic, err)
+	}
+	return nil
+}
+
+// inlineMessage frames a message as "topic\npayload". It reports false when
+// the result does not fit in a notification.
+func inlineMessage(topic string, payload []byte) (string, bool) {
+	message := topic + "\n" + string(payload)
+	return message, len(message) <= maxNotifyPayload
+}
+
+// storedPrefix starts the notification of a stored message, the id of its
+// row follows
+func storedPrefix(topic string) string {
+	return "\n" + topic + "\n"
+}
+
+// notification is a message received from Postgres. The payload of stored
+// messages is still to be read from the row storedID.
+type notification struct {
+	topic    string
+	payload  string
+	storedID int64
+}
+
+// parseNotification reads a notification framed by inlineMessage or
+// storedPrefix
+func parseNotification(raw string) (notification, error) {
+	topic, payload, ok := strings.Cut(raw, "\n")
+	if !ok {
+		return notification{}, fmt.Errorf("malformed notification %q", raw)
+	}
+	if topic != "" {
+		return notification{topic: topic, payload: payload}, nil
+	}
+
+	topic, rawID, ok := strings.Cut(payload, "\n")
+	id, err := strconv.ParseInt(rawID, 10, 64)
+	if !ok || topic == "" || err != nil || id <= 0 {
+		return notification{}, fmt.Errorf("malformed reference %q", payload)
+	}
+	return notification{topic: topic, storedID: id}, nil
+}
+
+func (p *Postgres) Subscribe(topic string, handler Handler) func() {
+	return p.local.Subscribe(topic, handler)
+}
+
+// Close stops listening, releases the connection and drops the
+// subscriptions
+func (p *Postgres) Close() error {
+	p.cancel()
+	<-p.done
+	return p.local.Close()
+}
+
+func (p *Postgres) listen(ctx context.Context) {
+	defer close(p.done)
+
+	delay := minReconnectDelay
+	for {
+		listening, err := p.listenOnce(ctx)
+		if ctx.Err() != nil {
+			return
+		}
+		if listening {
+			delay = minReconnectDelay
+		}
+		log.Printf("Pub/sub listener disconnected, retrying in %s: %v", delay, err)
+
+		select {
+		case <-time.After(delay):
+		case <-ctx.Done():
+			return
+		}
+		delay = min(delay*2, maxReconnectDelay)
+	}
+}
+
+// listenOnce listens on its own connection until it fails. The connection is
+// taken out of the pool, as a listening session cannot be shared.
+func (p *Postgres) listenOnce(ctx context.Context) (listening bool, err error) {
+	pooled, err := p.pool.Acquire(ctx)
+	if err != nil {
+		return false, err
+	}
+	conn := pooled.Hijack()
+	defer conn.Close(context.Background())
+
+	if _, err := conn.Exec(ctx, "LISTEN "+pgx.Identifier{p.channel}.Sanitize()); err != nil {
+		return false, err
+	}
+
+	for {
+		received, err := conn.WaitForNotification(ctx)
+		if err != nil {
+			return true, err
+		}
+		message, err := parseNotification(received.Payload)
+		if err != nil {
+			log.Printf("Ignoring pub/sub notification: %v", err)
+			continue
+		}
+		if message.storedID != 0 {
+			if message.payload, err = loadStored(ctx, conn, message.storedID); err != nil {
+				if ctx.Err() != nil {
+					return true, err
+				}
+				log.Printf("Failed to load stored pub/sub message of %s: %v", message.topic, err)
+				continue
+			}
+		}
+		p.local.Publish(ctx, message.topic, []byte(message.payload)) //nolint:errcheck // in memory publish does not fail
+	}
+}
+
+// loadStored reads the payload of a stored message
+func loadStored(ctx context.Context, conn *pgx.Conn, id int64) (string, error) {
+	var payload string
+	query := `SELECT payload FROM pubsub_payloads WHERE id = $1`
+	if err := conn.QueryRow(ctx, query, id).Scan(&payload); err != nil {
+		return "", fmt.Errorf("failed to read payload %d: %w", id, err)
+	}
+	return payload, nil
+}
@@ -0,0 +1,115 @@
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+func TestInlineMessage(t *testing.T) {
+	// "topic\n" takes 6 bytes of the notification
+	tests := []struct {
+		name       string
+		payload    string
+		wantInline bool
+	}{
+		{name: "empty", payload: "", wantInline: true},
+		{name: "json", payload: `{"type":"message"}`, wantInline: true},
+		{name: "largest inline", payload: strings.Repeat("x", maxNotifyPayload-6), wantInline: true},
+		{name: "one byte over", payload: strings.Repeat("x", maxNotifyPayload-5)},
+		{name: "multibyte over", payload: strings.Repeat("ñ", 4000)},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			message, inline := inlineMessage("topic", []byte(tt.payload))
+			if inline != tt.wantInline {
+				t.Fatalf("inlineMessage() inline = %v for %d bytes, want %v", inline, len(message), tt.wantInline)
+			}
+			if !inline {
+				return
+			}
+			if len(message) >= 8000 {
+				t.Errorf("inline message of %d bytes does not fit in a notification", len(message))
+			}
+			got, err := parseNotification(message)
+			if err != nil {
+				t.Fatalf("parseNotification() error = %v", err)
+			}
+			if want := (notification{topic: "topic", payload: tt.payload}); got != want {
+				t.Errorf("parseNotification() = %+v, want %+v", got, want)
+			}
+		})
+	}
+}
+
+func TestStoredReference(t *testing.T) {
+	got, err := parseNotification(storedPrefix("users.1") + "42")
+	if err != nil {
+		t.Fatalf("parseNotification() error = %v", err)
+	}
+	if want := (notification{topic: "users.1", storedID: 42}); got != want {
+		t.Errorf("parseNotification() = %+v, want %+v", got, want)
+	}
+}
+
+func TestParseNotification(t *testing.T) {
+	tests := []struct {
+		name    string
+		raw     string
+		want    notification
+		wantErr bool
+	}{
+		{name: "inline", raw: "users.1\n{}", want: notification{topic: "users.1", payload: "{}"}},
+		{name: "inline with newlines", raw: "users.1\na\nb", want: notification{topic: "users.1", payload: "a\nb"}},
+		{name: "inline empty payload", raw: "users.1\n", want: notification{topic: "users.1"}},
+		{name: "stored", raw: "\nusers.1\n7", want: notification{topic: "users.1", storedID: 7}},
+		{name: "no newline", raw: "users.1", wantErr: true},
+		{name: "empty", raw: "", wantErr: true},
+		{name: "stored without id", raw: "\nusers.1", wantErr: true},
+		{name: "stored with empty id", raw: "\nusers.1\n", wantErr: true},
+		{name: "stored with invalid id", raw: "\nusers.1\nabc", wantErr: true},
+		{name: "stored with negative id", raw: "\nusers.1\n-1", wantErr: true},
+		{name: "stored without topic", raw: "\n\n7", wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseNotification(tt.raw)
+			if tt.wantErr {
+				if err == nil {
+					t.Errorf("parseNotification(%q) = %+v, want an error", tt.raw, got)
+				}
+				return
+			}
+			if err != nil || got != tt.want {
+				t.Errorf("parseNotification(%q) = %+v, %v, want %+v", tt.raw, got, err, tt.want)
+			}
+		})
+	}
+}
+
+// TestPostgresPublishRejects checks the messages refused before reaching the
+// database
+func TestPostgresPublishRejects(t *testing.T) {
+	p := &Postgres{channel: defaultChannel}
+	tests := []struct {
+		name    string
+		topic   string
+		payload []byte
+		want    error
+	}{
+		{name: "empty topic", topic: "", payload: []byte("{}"), want: ErrInvalidTopic},
+		{name: "topic with newline", topic: "users\n1", payload: []byte("{}"), want: ErrInvalidTopic},
+		{name: "too large to store", topic: "users.1", payload: make([]byte, maxStoredPayload+1), want: ErrTooLarge},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := p.Publish(context.Background(), tt.topic, tt.payload); !errors.Is(err, tt.want) {
+				t.Errorf("Publish() error = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
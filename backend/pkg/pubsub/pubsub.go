@@ -0,0 +1,60 @@
+// Package pubsub delivers messages to subscribers on every instance of the
+// server
+package pubsub
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/jackc/pgx/v5/pgxpool"
+)
+
+const (
+	DriverMemory   = "memory"
+	DriverPostgres = "postgres"
+)
+
+var (
+	ErrTooLarge     = errors.New("message too large")
+	ErrInvalidTopic = errors.New("invalid topic")
+	ErrNoPool       = errors.New("postgres pub/sub needs a database pool")
+)
+
+// Handler receives the payload of a message published on a topic
+type Handler func(payload []byte)
+
+// PubSub is implemented by every backend. Delivery is at most once: messages
+// published while a subscriber is unreachable are lost.
+type PubSub interface {
+	// Publish sends the payload to the subscribers of topic. Payloads must be
+	// text, such as JSON.
+	Publish(ctx context.Context, topic string, payload []byte) error
+	// Subscribe calls handler with every message published on topic until the
+	// returned function is called. Handlers must not block.
+	Subscribe(topic string, handler Handler) (unsubscribe func())
+	Close() error
+}
+
+// Config holds the pub/sub backend settings
+type Config struct {
+	Driver string
+	Pool   *pgxpool.Pool
+	// Channel is the Postgres notification channel shared by all topics
+	Channel string
+}
+
+// New returns the backend selected by cfg.Driver
+func New(cfg *Config) (PubSub, error) {
+	switch cfg.Driver {
+	case DriverMemory, "":
+		return NewMemory(), nil
+	case DriverPostgres:
+		if cfg.Pool == nil {
+			return nil, ErrNoPool
+		}
+		return NewPostgres(cfg.Pool, cfg.Channel), nil
+	default:
+		return nil, fmt.Errorf("unknown pub/sub driver %q", cfg.Driver)
+	}
+}
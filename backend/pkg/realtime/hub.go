@@ -0,0 +1,247 @@
+// Package realtime pushes events to connected users over WebSockets and
+// Server-Sent Events
+package realtime
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log"
+	"sync"
+	"time"
+)
+
+const (
+	defaultSendBuffer   = 64
+	defaultPingInterval = 25 * time.Second
+	defaultWriteTimeout = 10 * time.Second
+	defaultReplaySize   = 100
+	defaultReplayTTL    = 5 * time.Minute
+
+	typePing   = "ping"
+	typePong   = "pong"
+	typeResync = "resync"
+)
+
+// Event is a message pushed to clients, encoded as {"type": ..., "data": ...}
+type Event struct {
+	Type string `json:"type"`
+	Data any    `json:"data,omitempty"`
+}
+
+// Incoming is a message sent by a client. Data is decoded by whoever handles
+// Type.
+type Incoming struct {
+	Type string          `json:"type"`
+	Data json.RawMessage `json:"data,omitempty"`
+}
+
+// Publisher sends events to every connection of the given users
+type Publisher interface {
+	Publish(userIDs []int, event Event)
+}
+
+// Receiver handles the messages clients send, other than heartbeats. It runs
+// on the reading goroutine of the connection, and ctx is canceled when the
+// hub shuts down.
+type Receiver func(ctx context.Context, userID int, msg Incoming)
+
+// Options tunes the hub. Zero values fall back to the defaults.
+type Options struct {
+	// SendBuffer is how many events can wait for a connection before it is
+	// considered too slow and dropped
+	SendBuffer int
+	// PingInterval is how often a ping is sent. WebSocket clients answer with
+	// a pong, or any other message, and are dropped after two intervals of
+	// silence.
+	PingInterval time.Duration
+	WriteTimeout time.Duration
+	// ReplaySize and ReplayTTL bound the recent events kept for each user so
+	// an event stream can resume where it left off
+	ReplaySize int
+	ReplayTTL  time.Duration
+}
+
+// Hub keeps the open connections of every user and fans events out to them
+type Hub struct {
+	opts Options
+
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	clients map[int]map[*client]struct{}
+	replay  map[int][]message
+	closed  bool
+	wg      sync.WaitGroup
+}
+
+// message is an encoded event with the id it is replayed by
+type message struct {
+	id   string
+	data []byte
+	at   time.Time
+}
+
+type client struct {
+	userID int
+	send   chan message
+
+	done      chan struct{}
+	closeOnce sync.Once
+}
+
+// close asks the connection to stop. It is safe to call more than once.
+func (c *client) close() {
+	c.closeOnce.Do(func() { close(c.done) })
+}
+
+func NewHub(opts Options) *Hub {
+	if opts.SendBuffer <= 0 {
+		opts.SendBuffer = defaultSendBuffer
+	}
+	if opts.PingInterval <= 0 {
+		opts.PingInterval = defaultPingInterval
+	}
+	if opts.WriteTimeout <= 0 {
+		opts.WriteTimeout = defaultWriteTimeout
+	}
+	if opts.ReplaySize <= 0 {
+		opts.ReplaySize = defaultReplaySize
+	}
+	if opts.ReplayTTL <= 0 {
+		opts.ReplayTTL = defaultReplayTTL
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Hub{
+		opts:    opts,
+		ctx:     ctx,
+		cancel:  cancel,
+		clients: make(map[int]map[*client]struct{}),
+		replay:  make(map[int][]message),
+	}
+}
+
+// Publish sends the event to the users connected to this instance only. Use
+// a Relay to reach every instance.
+func (h *Hub) Publish(userIDs []int, event Event) {
+	id, data, err := encodeEvent(event)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+	h.deliver(userIDs, id, data)
+}
+
+// deliver records an encoded event for replay and queues it on every
+// connection of the users. A connection whose queue is full is closed rather
+// than left with a gap in its events; the client reconnects and resumes or
+// reloads what it missed.
+func (h *Hub) deliver(userIDs []int, id string, data []byte) {
+	msg := message{id: id, data: data, at: time.Now()}
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for _, userID := range userIDs {
+		h.record(userID, msg)
+		for c := range h.clients[userID] {
+			select {
+			case <-c.done:
+			case c.send <- msg:
+			default:
+				log.Printf("Dropping slow realtime connection of user %d", c.userID)
+				c.close()
+			}
+		}
+	}
+}
+
+// Shutdown stops accepting connections, closes the open ones and waits for
+// them to finish or for ctx to be done
+func (h *Hub) Shutdown(ctx context.Context) error {
+	h.mu.Lock()
+	h.closed = true
+	for _, clients := range h.clients {
+		for c := range clients {
+			c.close()
+		}
+	}
+	h.mu.Unlock()
+	h.cancel()
+
+	done := make(chan struct{})
+	go func() {
+		h.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// register adds a client for userID unless the hub is shutting down. When
+// lastEventID is set, the events the user got after it are returned as well,
+// with ok false if it is no longer in the replay buffer.
+func (h *Hub) register(userID int, lastEventID string) (c *client, missed []message, ok bool) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.closed {
+		return nil, nil, false
+	}
+
+	ok = true
+	if lastEventID != "" {
+		missed, ok = h.since(userID, lastEventID)
+	}
+
+	c = &client{
+		userID: userID,
+		send:   make(chan message, h.opts.SendBuffer),
+		done:   make(chan struct{}),
+	}
+	if h.clients[userID] == nil {
+		h.clients[userID] = make(map[*client]struct{})
+	}
+	h.clients[userID][c] = struct{}{}
+	h.wg.Add(1)
+	return c, missed, ok
+}
+
+// unregister removes a client added by register
+func (h *Hub) unregister(c *client) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	delete(h.clients[c.userID], c)
+	if len(h.clients[c.userID]) == 0 {
+		delete(h.clients, c.userID)
+	}
+	h.wg.Done()
+}
+
+// encodeEvent gives the event a new id and encodes it
+func encodeEvent(event Event) (string, []byte, error) {
+	data, err := json.Marshal(event)
+	if err != nil {
+		return "", nil, err
+	}
+	id, err := newEventID()
+	if err != nil {
+		return "", nil, err
+	}
+	return id, data, nil
+}
+
+// newEventID returns a random id. Ids only need to be unique, the replay
+// buffer keeps events in the order they arrived.
+func newEventID() (string, error) {
+	buf := make([]byte, 8)
+	if _, err := rand.Read(buf); err != nil {
+		return "", fmt.Errorf("failed to generate event id: %w", err)
+	}
+	return hex.EncodeToString(buf), nil
+}
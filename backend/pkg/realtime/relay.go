@@ -0,0 +1,75 @@
+package realtime
+
+import (
+	"context"
+	"encoding/json"
+	"log"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/pkg/pubsub"
+)
+
+const (
+	relayTopic = "realtime"
+
+	relayPublishTimeout = 5 * time.Second
+)
+
+// relayMessage is an event on its way to the hub of every instance
+type relayMessage struct {
+	UserIDs []int           `json:"user_ids"`
+	ID      string          `json:"id"`
+	Event   json.RawMessage `json:"event"`
+}
+
+// Relay is a Publisher that sends events through pub/sub, so they reach the
+// users connected to any instance and not just this one. The event id is set
+// before sending, so every instance replays the event under the same id.
+type Relay struct {
+	bus         pubsub.PubSub
+	hub         *Hub
+	unsubscribe func()
+}
+
+// NewRelay hands the events published through bus to hub
+func NewRelay(bus pubsub.PubSub, hub *Hub) *Relay {
+	unsubscribe := bus.Subscribe(relayTopic, func(payload []byte) {
+		var msg relayMessage
+		if err := json.Unmarshal(payload, &msg); err != nil {
+			log.Printf("Failed to decode relayed event: %v", err)
+			return
+		}
+		hub.deliver(msg.UserIDs, msg.ID, msg.Event)
+	})
+	return &Relay{bus: bus, hub: hub, unsubscribe: unsubscribe}
+}
+
+// Publish sends the event to every instance. When that fails, the users
+// connected to this instance still get it.
+func (r *Relay) Publish(userIDs []int, event Event) {
+	if len(userIDs) == 0 {
+		return
+	}
+	id, data, err := encodeEvent(event)
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+	payload, err := json.Marshal(relayMessage{UserIDs: userIDs, ID: id, Event: data})
+	if err != nil {
+		log.Printf("Failed to encode %s event: %v", event.Type, err)
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), relayPublishTimeout)
+	defer cancel()
+	if err := r.bus.Publish(ctx, relayTopic, payload); err != nil {
+		log.Printf("Failed to relay %s event, delivering it locally: %v", event.Type, err)
+		r.hub.deliver(userIDs, id, data)
+	}
+}
+
+// Close stops handing events to the hub
+func (r *Relay) Close() {
+	r.unsubscribe()
+}
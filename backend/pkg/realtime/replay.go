@@ -0,0 +1,51 @@
+package realtime
+
+import (
+	"context"
+	"time"
+)
+
+// record appends the event to the replay buffer of the user, dropping the
+// oldest events past ReplaySize. The caller holds h.mu.
+func (h *Hub) record(userID int, msg message) {
+	events := append(h.replay[userID], msg)
+	if len(events) > h.opts.ReplaySize {
+		events = events[len(events)-h.opts.ReplaySize:]
+	}
+	h.replay[userID] = events
+}
+
+// since returns the events the user got after lastEventID, and false when
+// that event is no longer in the buffer so some may be missing. The caller
+// holds h.mu.
+func (h *Hub) since(userID int, lastEventID string) ([]message, bool) {
+	events := h.replay[userID]
+	for i := len(events) - 1; i >= 0; i-- {
+		if events[i].id == lastEventID {
+			return append([]message(nil), events[i+1:]...), true
+		}
+	}
+	return nil, false
+}
+
+// PruneReplay drops the events older than ReplayTTL, and the buffers of users
+// left without any
+func (h *Hub) PruneReplay(_ context.Context) error {
+	cutoff := time.Now().Add(-h.opts.ReplayTTL)
+
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	for userID, events := range h.replay {
+		expired := 0
+		for expired < len(events) && events[expired].at.Before(cutoff) {
+			expired++
+		}
+		switch {
+		case expired == len(events):
+			delete(h.replay, userID)
+		case expired > 0:
+			h.replay[userID] = append([]message(nil), events[expired:]...)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,113 @@
+package realtime
+
+import (
+	"context"
+	"slices"
+	"testing"
+	"time"
+)
+
+// ids returns the ids of msgs in order
+func ids(msgs []message) []string {
+	out := make([]string, 0, len(msgs))
+	for _, msg := range msgs {
+		out = append(out, msg.id)
+	}
+	return out
+}
+
+func TestRecord(t *testing.T) {
+	tests := []struct {
+		name     string
+		size     int
+		recorded []string
+		want     []string
+	}{
+		{name: "under the limit", size: 3, recorded: []string{"a", "b"}, want: []string{"a", "b"}},
+		{name: "at the limit", size: 3, recorded: []string{"a", "b", "c"}, want: []string{"a", "b", "c"}},
+		{name: "drops the oldest", size: 3, recorded: []string{"a", "b", "c", "d", "e"}, want: []string{"c", "d", "e"}},
+		{name: "size one", size: 1, recorded: []string{"a", "b"}, want: []string{"b"}},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(Options{ReplaySize: tt.size})
+			for _, id := range tt.recorded {
+				h.record(1, message{id: id, at: time.Now()})
+			}
+			if got := ids(h.replay[1]); !slices.Equal(got, tt.want) {
+				t.Errorf("replay = %q, want %q", got, tt.want)
+			}
+			if _, ok := h.replay[2]; ok {
+				t.Errorf("replay of another user was created")
+			}
+		})
+	}
+}
+
+func TestSince(t *testing.T) {
+	tests := []struct {
+		name        string
+		recorded    []string
+		lastEventID string
+		want        []string
+		wantOK      bool
+	}{
+		{name: "resumes after the event", recorded: []string{"a", "b", "c"}, lastEventID: "a", want: []string{"b", "c"}, wantOK: true},
+		{name: "up to date", recorded: []string{"a", "b", "c"}, lastEventID: "c", want: []string{}, wantOK: true},
+		{name: "unknown event", recorded: []string{"a", "b"}, lastEventID: "x", want: nil, wantOK: false},
+		{name: "evicted event", recorded: []string{"a", "b", "c", "d"}, lastEventID: "a", want: nil, wantOK: false},
+		{name: "empty buffer", recorded: nil, lastEventID: "a", want: nil, wantOK: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			h := NewHub(Options{ReplaySize: 3})
+			for _, id := range tt.recorded {
+				h.record(1, message{id: id, at: time.Now()})
+			}
+
+			got, ok := h.since(1, tt.lastEventID)
+			if ok != tt.wantOK {
+				t.Fatalf("since() ok = %v, want %v", ok, tt.wantOK)
+			}
+			if ok && !slices.Equal(ids(got), tt.want) {
+				t.Errorf("since() = %q, want %q", ids(got), tt.want)
+			}
+			if !ok && got != nil {
+				t.Errorf("since() = %q, want nil", ids(got))
+			}
+		})
+	}
+}
+
+func TestSinceReturnsACopy(t *testing.T) {
+	h := NewHub(Options{ReplaySize: 3})
+	for _, id := range []string{"a", "b", "c"} {
+		h.record(1, message{id: id, at: time.Now()})
+	}
+
+	missed, _ := h.since(1, "a")
+	h.record(1, message{id: "d", at: time.Now()})
+	if got := ids(missed); !slices.Equal(got, []string{"b", "c"}) {
+		t.Errorf("since() result changed to %q after record", got)
+	}
+}
+
+func TestPruneReplay(t *testing.T) {
+	h := NewHub(Options{ReplayTTL: time.Minute})
+	old := time.Now().Add(-2 * time.Minute)
+	h.record(1, message{id: "a", at: old})
+	h.record(1, message{id: "b", at: time.Now()})
+	h.record(2, message{id: "c", at: old})
+
+	if err := h.PruneReplay(context.Background()); err != nil {
+		t.Fatalf("PruneReplay() error = %v", err)
+	}
+	if got := ids(h.replay[1]); !slices.Equal(got, []string{"b"}) {
+		t.Errorf("replay of user 1 = %q, want [b]", got)
+	}
+	if _, ok := h.replay[2]; ok {
+		t.Errorf("replay of user 2 was kept without events")
+	}
+}
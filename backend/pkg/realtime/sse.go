@@ -0,0 +1,101 @@
+package realtime
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/pkg/common"
+)
+
+// sseRetry is how long browsers wait before reconnecting a dropped stream
+const sseRetry = 3 * time.Second
+
+// ServeEvents streams the events of userID, who must already be
+// authenticated, as Server-Sent Events. Every event carries an id; a client
+// that reconnects with it in Last-Event-ID, or in the last_event_id query
+// parameter, first gets the events it missed. When they are no longer
+// buffered it gets a "resync" event and should reload its state instead.
+func (h *Hub) ServeEvents(w http.ResponseWriter, r *http.Request, userID int) {
+	lastEventID := r.Header.Get("Last-Event-ID")
+	if lastEventID == "" {
+		lastEventID = r.URL.Query().Get("last_event_id")
+	}
+
+	c, missed, resumed := h.register(userID, lastEventID)
+	if c == nil {
+		common.ErrorResponse(w, http.StatusServiceUnavailable, "Server is shutting down", nil)
+		return
+	}
+	defer h.unregister(c)
+
+	rc := http.NewResponseController(w)
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	// Keeps reverse proxies like nginx from buffering the stream
+	w.Header().Set("X-Accel-Buffering", "no")
+	w.WriteHeader(http.StatusOK)
+
+	write := func(write func() error) bool {
+		if err := rc.SetWriteDeadline(time.Now().Add(h.opts.WriteTimeout)); err != nil && !errors.Is(err, http.ErrNotSupported) {
+			return false
+		}
+		return write() == nil && rc.Flush() == nil
+	}
+
+	if !write(func() error {
+		if _, err := fmt.Fprintf(w, "retry: %d\n\n", sseRetry.Milliseconds()); err != nil {
+			return err
+		}
+		if !resumed {
+			data, _ := json.Marshal(Event{Type: typeResync})
+			return writeSSE(w, message{data: data})
+		}
+		for _, msg := range missed {
+			if err := writeSSE(w, msg); err != nil {
+				return err
+			}
+		}
+		return nil
+	}) {
+		return
+	}
+
+	ticker := time.NewTicker(h.opts.PingInterval)
+	defer ticker.Stop()
+	for {
+		var ok bool
+		select {
+		case msg := <-c.send:
+			ok = write(func() error { return writeSSE(w, msg) })
+		case <-ticker.C:
+			// A comment line, so proxies do not time out an idle stream
+			ok = write(func() error {
+				_, err := io.WriteString(w, ": ping\n\n")
+				return err
+			})
+		case <-c.done:
+			return
+		case <-r.Context().Done():
+			return
+		}
+		if !ok {
+			return
+		}
+	}
+}
+
+// writeSSE writes one event. The data is JSON on a single line, and events
+// without an id, like resync, leave the last id of the client as it was.
+func writeSSE(w io.Writer, msg message) error {
+	if msg.id != "" {
+		if _, err := fmt.Fprintf(w, "id: %s\n", msg.id); err != nil {
+			return err
+		}
+	}
+	_, err := fmt.Fprintf(w, "data: %s\n\n", msg.data)
+	return err
+}
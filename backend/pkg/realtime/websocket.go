@@ -0,0 +1,100 @@
+package realtime
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/escuadron-404/red404/backend/pkg/common"
+	"golang.org/x/net/websocket"
+)
+
+// maxIncomingBytes caps the size of a message sent by a client
+const maxIncomingBytes = 4096
+
+// ServeWebSocket upgrades the request to a WebSocket for userID, who must
+// already be authenticated, and keeps it open until either side closes it
+func (h *Hub) ServeWebSocket(w http.ResponseWriter, r *http.Request, userID int, receive Receiver) {
+	c, _, _ := h.register(userID, "")
+	if c == nil {
+		common.ErrorResponse(w, http.StatusServiceUnavailable, "Server is shutting down", nil)
+		return
+	}
+	defer h.unregister(c)
+
+	server := websocket.Server{
+		// Clients authenticate with a bearer token rather than a cookie, so a
+		// page on another origin gains nothing by connecting and the origin
+		// is not checked
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(conn *websocket.Conn) {
+			h.runWebSocket(c, conn, receive)
+		},
+	}
+	server.ServeHTTP(w, r)
+}
+
+func (h *Hub) runWebSocket(c *client, conn *websocket.Conn, receive Receiver) {
+	conn.MaxPayloadBytes = maxIncomingBytes
+
+	written := make(chan struct{})
+	go func() {
+		defer close(written)
+		h.writeWebSocket(c, conn)
+	}()
+
+	h.readWebSocket(c, conn, receive)
+	c.close()
+	<-written
+}
+
+// readWebSocket hands client messages to receive until the connection fails,
+// goes quiet for too long or is closed by the writer
+func (h *Hub) readWebSocket(c *client, conn *websocket.Conn, receive Receiver) {
+	for {
+		if err := conn.SetReadDeadline(time.Now().Add(2 * h.opts.PingInterval)); err != nil {
+			return
+		}
+		var data []byte
+		if err := websocket.Message.Receive(conn, &data); err != nil {
+			return
+		}
+
+		var msg Incoming
+		if err := json.Unmarshal(data, &msg); err != nil || msg.Type == typePong || receive == nil {
+			continue
+		}
+		receive(h.ctx, c.userID, msg)
+	}
+}
+
+// writeWebSocket sends queued events and pings until the client is closed.
+// It owns the connection and closes it on the way out, which also stops the
+// reader.
+func (h *Hub) writeWebSocket(c *client, conn *websocket.Conn) {
+	ticker := time.NewTicker(h.opts.PingInterval)
+	defer ticker.Stop()
+	defer conn.Close()
+
+	ping, _ := json.Marshal(Event{Type: typePing})
+	for {
+		var data []byte
+		select {
+		case msg := <-c.send:
+			data = msg.data
+		case <-ticker.C:
+			data = ping
+		case <-c.done:
+			return
+		}
+
+		if err := conn.SetWriteDeadline(time.Now().Add(h.opts.WriteTimeout)); err != nil {
+			c.close()
+			return
+		}
+		if err := websocket.Message.Send(conn, string(data)); err != nil {
+			c.close()
+			return
+		}
+	}
+}
@@ -0,0 +1,209 @@
+package storage
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// MediaRoutePrefix is where the server exposes objects of the local backend
+const MediaRoutePrefix = "/api/media/"
+
+// Local stores objects on the local filesystem. Its signed URLs point back at
+// this server, which checks the signature before serving the file.
+type Local struct {
+	root       string
+	baseURL    string
+	signingKey []byte
+}
+
+func NewLocal(root, baseURL, signingKey string) (*Local, error) {
+	if signingKey == "" {
+		return nil, errors.New("local storage requires a signing key")
+	}
+	if root == "" {
+		root = "uploads"
+	}
+	absRoot, err := filepath.Abs(root)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve storage root: %w", err)
+	}
+	if err := os.MkdirAll(absRoot, 0o750); err != nil {
+		return nil, fmt.Errorf("failed to create storage root: %w", err)
+	}
+
+	return &Local{
+		root:       absRoot,
+		baseURL:    strings.TrimSuffix(baseURL, "/"),
+		signingKey: []byte(signingKey),
+	}, nil
+}
+
+func (l *Local) path(key string) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(l.root, filepath.FromSlash(key)), nil
+}
+
+func (l *Local) Put(ctx context.Context, key string, r io.Reader, _ int64, _ string) error {
+	dst, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(dst), 0o750); err != nil {
+		return fmt.Errorf("failed to create object directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial object
+	tmp, err := os.CreateTemp(filepath.Dir(dst), ".upload-*")
+	if err != nil {
+		return fmt.Errorf("failed to create temporary file: %w", err)
+	}
+	defer os.Remove(tmp.Name())
+
+	if _, err := io.Copy(tmp, readerWithContext(ctx, r)); err != nil {
+		tmp.Close()
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("failed to write object: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), dst)
+}
+
+func (l *Local) Open(_ context.Context, key string) (Object, error) {
+	src, err := l.path(key)
+	if err != nil {
+		return nil, err
+	}
+
+	f, err := os.Open(src)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	stat, err := f.Stat()
+	if err != nil {
+		f.Close()
+		return nil, err
+	}
+	if stat.IsDir() {
+		f.Close()
+		return nil, ErrNotFound
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(key))
+	if contentType == "" {
+		contentType = sniffContentType(f)
+	}
+
+	return &localObject{
+		File: f,
+		info: ObjectInfo{
+			Key:         key,
+			Size:        stat.Size(),
+			ContentType: contentType,
+			ModTime:     stat.ModTime(),
+		},
+	}, nil
+}
+
+func (l *Local) Delete(_ context.Context, key string) error {
+	dst, err := l.path(key)
+	if err != nil {
+		return err
+	}
+	if err := os.Remove(dst); err != nil && !os.IsNotExist(err) {
+		return err
+	}
+	return nil
+}
+
+func (l *Local) SignedURL(_ context.Context, key string, ttl time.Duration) (string, error) {
+	key, err := CleanKey(key)
+	if err != nil {
+		return "", err
+	}
+
+	expires := time.Now().Add(ttl).Unix()
+	query := url.Values{}
+	query.Set("expires", strconv.FormatInt(expires, 10))
+	query.Set("signature", l.sign(key, expires))
+
+	return l.baseURL + MediaRoutePrefix + (&url.URL{Path: key}).EscapedPath() + "?" + query.Encode(), nil
+}
+
+func (l *Local) Verify(key string, expires int64, signature string) error {
+	if time.Now().Unix() > expires {
+		return ErrInvalidSignature
+	}
+	expected := l.sign(key, expires)
+	if !hmac.Equal([]byte(expected), []byte(signature)) {
+		return ErrInvalidSignature
+	}
+	return nil
+}
+
+func (l *Local) sign(key string, expires int64) string {
+	mac := hmac.New(sha256.New, l.signingKey)
+	mac.Write([]byte(key))
+	mac.Write([]byte{'\n'})
+	mac.Write([]byte(strconv.FormatInt(expires, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+type localObject struct {
+	*os.File
+	info ObjectInfo
+}
+
+func (o *localObject) Info() ObjectInfo {
+	return o.info
+}
+
+// sniffContentType guesses the content type from the first bytes of the file
+// and rewinds it afterwards
+func sniffContentType(f *os.File) string {
+	buf := make([]byte, 512)
+	n, _ := io.ReadFull(f, buf)
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		return "application/octet-stream"
+	}
+	return http.DetectContentType(buf[:n])
+}
+
+type ctxReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (c *ctxReader) Read(p []byte) (int, error) {
+	if err := c.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return c.r.Read(p)
+}
+
+// readerWithContext stops long copies once the request has been canceled
+func readerWithContext(ctx context.Context, r io.Reader) io.Reader {
+	return &ctxReader{ctx: ctx, r: r}
+}
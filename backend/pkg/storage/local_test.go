@@ -0,0 +1,128 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"net/url"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// parseSignedURL splits a signed URL of the local backend into the values
+// the media handler passes to Verify
+func parseSignedURL(t *testing.T, rawURL string) (key string, expires int64, signature string) {
+	t.Helper()
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		t.Fatalf("invalid signed URL %q: %v", rawURL, err)
+	}
+	key, ok := strings.CutPrefix(u.Path, MediaRoutePrefix)
+	if !ok {
+		t.Fatalf("signed URL %q is not under %s", rawURL, MediaRoutePrefix)
+	}
+	expires, err = strconv.ParseInt(u.Query().Get("expires"), 10, 64)
+	if err != nil {
+		t.Fatalf("invalid expires in %q: %v", rawURL, err)
+	}
+	return key, expires, u.Query().Get("signature")
+}
+
+func TestLocalSignedURL(t *testing.T) {
+	l, err := NewLocal(t.TempDir(), "http://localhost:8080/", "test-signing-key")
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+	other, err := NewLocal(t.TempDir(), "http://localhost:8080", "another-key")
+	if err != nil {
+		t.Fatalf("NewLocal() error = %v", err)
+	}
+
+	signed, err := l.SignedURL(context.Background(), "posts/1/a photo.jpg", time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL() error = %v", err)
+	}
+	if !strings.HasPrefix(signed, "http://localhost:8080"+MediaRoutePrefix+"posts/1/a%20photo.jpg?") {
+		t.Errorf("SignedURL() = %s, want it under the media route", signed)
+	}
+	key, expires, signature := parseSignedURL(t, signed)
+
+	expired, err := l.SignedURL(context.Background(), key, -time.Minute)
+	if err != nil {
+		t.Fatalf("SignedURL() error = %v", err)
+	}
+	expiredKey, expiredAt, expiredSignature := parseSignedURL(t, expired)
+
+	tampered := "0" + signature[1:]
+	if tampered == signature {
+		tampered = "1" + signature[1:]
+	}
+
+	tests := []struct {
+		name      string
+		verifier  *Local
+		key       string
+		expires   int64
+		signature string
+		wantErr   bool
+	}{
+		{name: "valid", verifier: l, key: key, expires: expires, signature: signature},
+		{name: "expired", verifier: l, key: expiredKey, expires: expiredAt, signature: expiredSignature, wantErr: true},
+		{name: "other key", verifier: l, key: "posts/1/other.jpg", expires: expires, signature: signature, wantErr: true},
+		{name: "extended expiry", verifier: l, key: key, expires: expires + 3600, signature: signature, wantErr: true},
+		{name: "tampered signature", verifier: l, key: key, expires: expires, signature: tampered, wantErr: true},
+		{name: "missing signature", verifier: l, key: key, expires: expires, signature: "", wantErr: true},
+		{name: "other signing key", verifier: other, key: key, expires: expires, signature: signature, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.verifier.Verify(tt.key, tt.expires, tt.signature)
+			if tt.wantErr && !errors.Is(err, ErrInvalidSignature) {
+				t.Errorf("Verify() error = %v, want ErrInvalidSignature", err)
+			}
+			if !tt.wantErr && err != nil {
+				t.Errorf("Verify() error = %v", err)
+			}
+		})
+	}
+}
+
+func TestNewLocalRequiresSigningKey(t *testing.T) {
+	if _, err := NewLocal(t.TempDir(), "", ""); err == nil {
+		t.Error("NewLocal() without a signing key succeeded")
+	}
+}
+
+func TestCleanKey(t *testing.T) {
+	tests := []struct {
+		key     string
+		want    string
+		wantErr bool
+	}{
+		{key: "posts/1/a.jpg", want: "posts/1/a.jpg"},
+		{key: "/posts/1/a.jpg", want: "posts/1/a.jpg"},
+		{key: "", wantErr: true},
+		{key: "/", wantErr: true},
+		{key: "..", wantErr: true},
+		{key: "../etc/passwd", wantErr: true},
+		{key: "posts/../../etc/passwd", wantErr: true},
+		{key: "posts//a.jpg", wantErr: true},
+		{key: "posts/./a.jpg", wantErr: true},
+		{key: `posts\a.jpg`, wantErr: true},
+	}
+
+	for _, tt := range tests {
+		got, err := CleanKey(tt.key)
+		if tt.wantErr {
+			if !errors.Is(err, ErrInvalidKey) {
+				t.Errorf("CleanKey(%q) error = %v, want ErrInvalidKey", tt.key, err)
+			}
+			continue
+		}
+		if err != nil || got != tt.want {
+			t.Errorf("CleanKey(%q) = %q, %v, want %q", tt.key, got, err, tt.want)
+		}
+	}
+}
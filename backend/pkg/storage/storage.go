@@ -0,0 +1,93 @@
+// Package storage provides pluggable blob storage for user uploaded media
+package storage
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"path"
+	"strings"
+	"time"
+)
+
+const (
+	DriverLocal = "local"
+	DriverS3    = "s3"
+)
+
+var (
+	ErrNotFound         = errors.New("object not found")
+	ErrInvalidKey       = errors.New("invalid object key")
+	ErrInvalidSignature = errors.New("invalid or expired signature")
+)
+
+// ObjectInfo describes a stored object
+type ObjectInfo struct {
+	Key         string
+	Size        int64
+	ContentType string
+	ModTime     time.Time
+}
+
+// Object is an opened blob. It is seekable so it can be handed straight to
+// http.ServeContent, which takes care of range requests.
+type Object interface {
+	io.ReadSeekCloser
+	Info() ObjectInfo
+}
+
+// Storage is implemented by every blob backend
+type Storage interface {
+	Put(ctx context.Context, key string, r io.Reader, size int64, contentType string) error
+	Open(ctx context.Context, key string) (Object, error)
+	Delete(ctx context.Context, key string) error
+	SignedURL(ctx context.Context, key string, ttl time.Duration) (string, error)
+}
+
+// Verifier is implemented by backends whose signed URLs are served by this
+// server instead of by the storage provider itself
+type Verifier interface {
+	Verify(key string, expires int64, signature string) error
+}
+
+// Config holds the storage backend settings
+type Config struct {
+	Driver        string
+	LocalPath     string
+	PublicBaseURL string
+	SigningKey    string
+
+	S3Endpoint     string
+	S3Region       string
+	S3Bucket       string
+	S3AccessKey    string
+	S3SecretKey    string
+	S3UsePathStyle bool
+}
+
+// New returns the backend selected by cfg.Driver
+func New(cfg *Config) (Storage, error) {
+	switch cfg.Driver {
+	case DriverLocal, "":
+		return NewLocal(cfg.LocalPath, cfg.PublicBaseURL, cfg.SigningKey)
+	case DriverS3:
+		return NewS3(cfg)
+	default:
+		return nil, fmt.Errorf("unknown storage driver %q", cfg.Driver)
+	}
+}
+
+// CleanKey normalizes an object key and rejects keys that could escape the
+// bucket or root directory
+func CleanKey(key string) (string, error) {
+	key = strings.TrimPrefix(key, "/")
+	if key == "" || strings.Contains(key, "\\") {
+		return "", ErrInvalidKey
+	}
+	cleaned := path.Clean(key)
+	if cleaned != key || cleaned == "." || strings.HasPrefix(cleaned, "../") || cleaned == ".." {
+		return "", ErrInvalidKey
+	}
+	return cleaned, nil
+}
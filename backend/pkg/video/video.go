@@ -0,0 +1,163 @@
+// Package video inspects and processes uploaded videos with ffprobe and
+// ffmpeg, which must be installed on the server
+package video
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os/exec"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var ErrUnsupported = errors.New("unsupported video")
+
+// Info is what the server needs to know about a video file
+type Info struct {
+	ContentType string
+	VideoCodec  string
+	AudioCodec  string
+	Width       int
+	Height      int
+	Duration    time.Duration
+}
+
+// Tools locates the ffprobe and ffmpeg binaries
+type Tools struct {
+	FFprobePath string
+	FFmpegPath  string
+}
+
+// videoCodecs maps the accepted video codecs to the container they are
+// served in. Browsers play H.264/HEVC in MP4 and VP8/VP9/AV1 in WebM.
+var videoCodecs = map[string]string{
+	"h264": "video/mp4",
+	"hevc": "video/mp4",
+	"vp8":  "video/webm",
+	"vp9":  "video/webm",
+	"av1":  "video/webm",
+}
+
+var audioCodecs = map[string]bool{
+	"aac":    true,
+	"mp3":    true,
+	"opus":   true,
+	"vorbis": true,
+}
+
+type probeOutput struct {
+	Streams []struct {
+		CodecType string `json:"codec_type"`
+		CodecName string `json:"codec_name"`
+		Width     int    `json:"width"`
+		Height    int    `json:"height"`
+	} `json:"streams"`
+	Format struct {
+		FormatName string `json:"format_name"`
+		Duration   string `json:"duration"`
+	} `json:"format"`
+}
+
+// Probe reads the container, codecs, dimensions and duration of the file and
+// rejects anything browsers cannot play. The returned errors wrap
+// ErrUnsupported when the file itself is the problem.
+func (t Tools) Probe(ctx context.Context, path string) (*Info, error) {
+	cmd := exec.CommandContext(ctx, t.FFprobePath,
+		"-v", "error", "-print_format", "json", "-show_format", "-show_streams", path)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		// A timeout or cancellation kills ffprobe too, but says nothing about
+		// the file, so it is reported as an ordinary error that can be retried
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return nil, fmt.Errorf("ffprobe did not finish: %w", ctxErr)
+		}
+		var exitErr *exec.ExitError
+		if errors.As(err, &exitErr) {
+			return nil, fmt.Errorf("%w: %s", ErrUnsupported, strings.TrimSpace(stderr.String()))
+		}
+		return nil, fmt.Errorf("failed to run ffprobe: %w", err)
+	}
+
+	var probe probeOutput
+	if err := json.Unmarshal(out, &probe); err != nil {
+		return nil, fmt.Errorf("failed to parse ffprobe output: %w", err)
+	}
+	return parseProbe(&probe)
+}
+
+func parseProbe(probe *probeOutput) (*Info, error) {
+	info := &Info{}
+	for _, stream := range probe.Streams {
+		switch stream.CodecType {
+		case "video":
+			if info.VideoCodec == "" {
+				info.VideoCodec = stream.CodecName
+				info.Width = stream.Width
+				info.Height = stream.Height
+			}
+		case "audio":
+			if info.AudioCodec == "" {
+				info.AudioCodec = stream.CodecName
+			}
+		}
+	}
+
+	if info.VideoCodec == "" {
+		return nil, fmt.Errorf("%w: no video stream", ErrUnsupported)
+	}
+	contentType, ok := videoCodecs[info.VideoCodec]
+	if !ok {
+		return nil, fmt.Errorf("%w: video codec %s", ErrUnsupported, info.VideoCodec)
+	}
+	if info.AudioCodec != "" && !audioCodecs[info.AudioCodec] {
+		return nil, fmt.Errorf("%w: audio codec %s", ErrUnsupported, info.AudioCodec)
+	}
+
+	// ffprobe reports every container of a family, e.g. "mov,mp4,m4a,3gp"
+	formats := strings.Split(probe.Format.FormatName, ",")
+	wanted := "mp4"
+	if contentType == "video/webm" {
+		wanted = "webm"
+	}
+	if !slices.Contains(formats, wanted) {
+		return nil, fmt.Errorf("%w: container %s", ErrUnsupported, probe.Format.FormatName)
+	}
+	info.ContentType = contentType
+
+	seconds, err := strconv.ParseFloat(probe.Format.Duration, 64)
+	if err != nil || seconds <= 0 {
+		return nil, fmt.Errorf("%w: unknown duration", ErrUnsupported)
+	}
+	info.Duration = time.Duration(seconds * float64(time.Second))
+
+	if info.Width <= 0 || info.Height <= 0 {
+		return nil, fmt.Errorf("%w: unknown dimensions", ErrUnsupported)
+	}
+	return info, nil
+}
+
+// ExtractPoster writes a JPEG frame taken at offset into dst, scaled down to
+// at most maxWidth pixels wide
+func (t Tools) ExtractPoster(ctx context.Context, src, dst string, offset time.Duration, maxWidth int) error {
+	cmd := exec.CommandContext(ctx, t.FFmpegPath,
+		"-v", "error", "-y",
+		"-ss", strconv.FormatFloat(offset.Seconds(), 'f', 3, 64),
+		"-i", src,
+		"-frames:v", "1",
+		"-vf", fmt.Sprintf("scale='min(%d,iw)':-2", maxWidth),
+		"-f", "image2", "-c:v", "mjpeg",
+		dst)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("ffmpeg failed to extract poster: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return nil
+}
@@ -0,0 +1,139 @@
+package video
+
+import (
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestParseProbe(t *testing.T) {
+	tests := []struct {
+		name    string
+		probe   string
+		want    *Info
+		wantErr bool
+	}{
+		{
+			name: "h264 in mp4",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"h264","width":1920,"height":1080},
+				{"codec_type":"audio","codec_name":"aac"}],
+				"format":{"format_name":"mov,mp4,m4a,3gp,3g2,mj2","duration":"12.500000"}}`,
+			want: &Info{
+				ContentType: "video/mp4",
+				VideoCodec:  "h264",
+				AudioCodec:  "aac",
+				Width:       1920,
+				Height:      1080,
+				Duration:    12500 * time.Millisecond,
+			},
+		},
+		{
+			name: "vp9 in webm without audio",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"vp9","width":640,"height":360}],
+				"format":{"format_name":"matroska,webm","duration":"3"}}`,
+			want: &Info{
+				ContentType: "video/webm",
+				VideoCodec:  "vp9",
+				Width:       640,
+				Height:      360,
+				Duration:    3 * time.Second,
+			},
+		},
+		{
+			name: "first video and audio streams win",
+			probe: `{"streams":[{"codec_type":"data","codec_name":"bin_data"},
+				{"codec_type":"video","codec_name":"av1","width":720,"height":1280},
+				{"codec_type":"audio","codec_name":"opus"},
+				{"codec_type":"video","codec_name":"mjpeg","width":100,"height":100},
+				{"codec_type":"audio","codec_name":"flac"}],
+				"format":{"format_name":"matroska,webm","duration":"1.25"}}`,
+			want: &Info{
+				ContentType: "video/webm",
+				VideoCodec:  "av1",
+				AudioCodec:  "opus",
+				Width:       720,
+				Height:      1280,
+				Duration:    1250 * time.Millisecond,
+			},
+		},
+		{
+			name: "no video stream",
+			probe: `{"streams":[{"codec_type":"audio","codec_name":"mp3"}],
+				"format":{"format_name":"mp3","duration":"60"}}`,
+			wantErr: true,
+		},
+		{
+			name: "unknown video codec",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"mpeg4","width":320,"height":240}],
+				"format":{"format_name":"mov,mp4,m4a,3gp,3g2,mj2","duration":"5"}}`,
+			wantErr: true,
+		},
+		{
+			name: "unknown audio codec",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"h264","width":320,"height":240},
+				{"codec_type":"audio","codec_name":"pcm_s16le"}],
+				"format":{"format_name":"mov,mp4,m4a,3gp,3g2,mj2","duration":"5"}}`,
+			wantErr: true,
+		},
+		{
+			name: "h264 in matroska",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"h264","width":320,"height":240}],
+				"format":{"format_name":"matroska,webm","duration":"5"}}`,
+			wantErr: true,
+		},
+		{
+			name: "vp9 in mp4",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"vp9","width":320,"height":240}],
+				"format":{"format_name":"mov,mp4,m4a,3gp,3g2,mj2","duration":"5"}}`,
+			wantErr: true,
+		},
+		{
+			name: "container name is matched whole",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"h264","width":320,"height":240}],
+				"format":{"format_name":"mp4x","duration":"5"}}`,
+			wantErr: true,
+		},
+		{
+			name: "missing duration",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"h264","width":320,"height":240}],
+				"format":{"format_name":"mov,mp4,m4a,3gp,3g2,mj2","duration":"N/A"}}`,
+			wantErr: true,
+		},
+		{
+			name: "zero duration",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"h264","width":320,"height":240}],
+				"format":{"format_name":"mov,mp4,m4a,3gp,3g2,mj2","duration":"0.000000"}}`,
+			wantErr: true,
+		},
+		{
+			name: "missing dimensions",
+			probe: `{"streams":[{"codec_type":"video","codec_name":"h264"}],
+				"format":{"format_name":"mov,mp4,m4a,3gp,3g2,mj2","duration":"5"}}`,
+			wantErr: true,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var probe probeOutput
+			if err := json.Unmarshal([]byte(tt.probe), &probe); err != nil {
+				t.Fatalf("invalid probe output: %v", err)
+			}
+
+			got, err := parseProbe(&probe)
+			if tt.wantErr {
+				if !errors.Is(err, ErrUnsupported) {
+					t.Fatalf("parseProbe() error = %v, want ErrUnsupported", err)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseProbe() error = %v", err)
+			}
+			if *got != *tt.want {
+				t.Errorf("parseProbe() = %+v, want %+v", *got, *tt.want)
+			}
+		})
+	}
+}